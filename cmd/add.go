@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yejune/git-multirepo/internal/common"
+	"github.com/yejune/git-multirepo/internal/git"
+	"github.com/yejune/git-multirepo/internal/hooks"
+	"github.com/yejune/git-multirepo/internal/manifest"
+)
+
+var addCmd = &cobra.Command{
+	Use:   "add <path>",
+	Short: "Register an existing repository",
+	Long: `Register a repository that is already cloned below the project.
+
+The repo URL is read from its origin remote, the branch from its current
+branch (unless it is origin's default branch), and files marked skip-worktree
+become keep files. A repository without an origin remote is refused, unless
+discover.local_repos is set in the manifest. The .git directory is added to .gitignore and the
+post-commit hook is installed, as with clone.
+
+To register every unregistered repository at once, use 'git multirepo sync --discover'.
+
+Examples:
+  git multirepo add packages/lib`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAdd,
+}
+
+func init() {
+	rootCmd.AddCommand(addCmd)
+}
+
+func runAdd(cmd *cobra.Command, args []string) error {
+	p := path.Clean(filepath.ToSlash(args[0]))
+
+	ctx, err := common.LoadWorkspaceContext()
+	if err != nil {
+		return err
+	}
+
+	if err := checkWorkspacePath(ctx.Manifest, p, ""); err != nil {
+		return err
+	}
+
+	fullPath := filepath.Join(ctx.RepoRoot, p)
+	if !git.IsRepo(fullPath) {
+		return fmt.Errorf("%s is not a git repository (use 'git multirepo clone <url> %s' to clone it)", p, p)
+	}
+
+	ws, err := inspectWorkspace(ctx.RepoRoot, fullPath, ctx.Manifest.AllowsLocalRepos())
+	if err != nil {
+		return fmt.Errorf("%s has no origin remote (set 'discover: {local_repos: true}' in %s to register it anyway): %w", p, manifest.FileName, err)
+	}
+
+	entry := beginJournal(ctx.RepoRoot, "add "+p, ctx.Manifest)
+	defer entry.Finish()
+
+	// Reloaded under the lock, in case the manifest changed meanwhile
+	if _, err := manifest.Update(ctx.RepoRoot, func(m *manifest.Manifest) error {
+		if m.Exists(p) {
+			return fmt.Errorf("repository already exists at %s", p)
+		}
+		m.Workspaces = append(m.Workspaces, ws)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if err := git.AddToGitignore(ctx.RepoRoot, p); err != nil {
+		return fmt.Errorf("failed to update .gitignore: %w", err)
+	}
+
+	if err := hooks.InstallWorkspaceHook(fullPath); err != nil {
+		fmt.Printf("⚠ Failed to install hook: %v\n", err)
+	}
+
+	fmt.Printf("✓ Added repository: %s\n", p)
+	if ws.Repo != "" {
+		fmt.Printf("  Repository: %s\n", ws.Repo)
+	} else {
+		fmt.Println("  Repository: none (no origin remote, never cloned)")
+	}
+	if ws.Branch != "" {
+		fmt.Printf("  Branch: %s\n", ws.Branch)
+	}
+	if len(ws.Keep) > 0 {
+		fmt.Printf("  Keep files: %s\n", strings.Join(ws.Keep, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yejune/git-multirepo/internal/hooks"
+	"github.com/yejune/git-multirepo/internal/manifest"
+)
+
+// cloneByHand clones remote into dir/path with plain git, as a user would
+func cloneByHand(t *testing.T, remote, dir, path string) string {
+	t.Helper()
+	fullPath := filepath.Join(dir, path)
+	if out, err := exec.Command("git", "clone", "-q", remote, fullPath).CombinedOutput(); err != nil {
+		t.Fatalf("git clone failed: %v\n%s", err, out)
+	}
+	return fullPath
+}
+
+func TestRunAdd(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	remote := setupRemoteRepo(t)
+	writeTestManifest(t, dir, manifest.WorkspaceEntry{Path: "apps/web", Repo: remote})
+
+	lib := cloneByHand(t, remote, dir, "packages/lib")
+	exec.Command("git", "-C", lib, "checkout", "-q", "-b", "feature").Run()
+	exec.Command("git", "-C", lib, "update-index", "--skip-worktree", "README.md").Run()
+
+	t.Run("refuses invalid paths", func(t *testing.T) {
+		os.MkdirAll(filepath.Join(dir, "plain"), 0755)
+		for _, p := range []string{"plain", "missing", "apps/web", "apps/web/sub", "../x"} {
+			if err := runAdd(addCmd, []string{p}); err == nil {
+				t.Errorf("add %s should fail", p)
+			}
+		}
+	})
+
+	output := captureOutput(func() {
+		if err := runAdd(addCmd, []string{"packages/lib/"}); err != nil {
+			t.Fatalf("add failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "✓ Added repository: packages/lib") || !strings.Contains(output, "Branch: feature") {
+		t.Errorf("unexpected output: %s", output)
+	}
+
+	m, _ := manifest.Load(dir)
+	ws := m.Find("packages/lib")
+	if ws == nil || ws.Repo != remote || ws.Branch != "feature" || len(ws.Keep) != 1 || ws.Keep[0] != "README.md" {
+		t.Fatalf("unexpected entry: %+v", ws)
+	}
+	gitignore, _ := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	if !strings.Contains(string(gitignore), "packages/lib/.git/") {
+		t.Errorf(".gitignore should ignore the workspace's .git, got:\n%s", gitignore)
+	}
+	if !hooks.IsWorkspaceHookInstalled(lib) {
+		t.Error("workspace hook should be installed")
+	}
+
+	if err := runAdd(addCmd, []string{"packages/lib"}); err == nil {
+		t.Error("adding a registered repository should fail")
+	}
+}
+
+func TestRunSync_Discover(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	remote := setupRemoteRepo(t)
+	writeTestManifest(t, dir, manifest.WorkspaceEntry{Path: "apps/web", Repo: remote})
+	captureOutput(func() { runSync(syncCmd, nil) })
+
+	// A repository nested in a registered workspace belongs to that workspace
+	cloneByHand(t, remote, dir, "apps/web/vendor/x")
+	cloneByHand(t, remote, dir, "tools/gen")
+
+	// Without --discover, unregistered repositories are left alone
+	captureOutput(func() { runSync(syncCmd, nil) })
+	if m, _ := manifest.Load(dir); len(m.Workspaces) != 1 {
+		t.Fatalf("sync should not register repositories without --discover, got %+v", m.Workspaces)
+	}
+
+	syncDiscover = true
+	defer func() { syncDiscover = false }()
+	output := captureOutput(func() {
+		if err := runSync(syncCmd, nil); err != nil {
+			t.Fatalf("sync --discover failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "Registered 1 repository(s)") {
+		t.Errorf("unexpected output: %s", output)
+	}
+
+	m, _ := manifest.Load(dir)
+	if len(m.Workspaces) != 2 || m.Workspaces[0].Path != "apps/web" || m.Workspaces[1].Path != "tools/gen" {
+		t.Errorf("only tools/gen should be registered, got %+v", m.Workspaces)
+	}
+	if m.Workspaces[1].Branch != "" {
+		t.Errorf("origin's default branch should not be recorded, got %q", m.Workspaces[1].Branch)
+	}
+
+	output = captureOutput(func() { runSync(syncCmd, nil) })
+	if !strings.Contains(output, "No unregistered repositories found") {
+		t.Errorf("second discovery should find nothing, got: %s", output)
+	}
+}
+
+func TestRunSync_DiscoverSettings(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	remote := setupRemoteRepo(t)
+	os.WriteFile(filepath.Join(dir, manifest.FileName), []byte(`discover:
+  exclude: [third_party]
+  local_repos: true
+workspaces:
+  - path: apps/web
+    repo: `+remote+`
+`), 0644)
+	os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("node_modules/\n"), 0644)
+	captureOutput(func() { runSync(syncCmd, nil) })
+
+	cloneByHand(t, remote, dir, "node_modules/pkg")
+	cloneByHand(t, remote, dir, "third_party/x")
+	gen := cloneByHand(t, remote, dir, "tools/gen")
+	exec.Command("git", "-C", gen, "worktree", "add", "-q", filepath.Join(dir, "wt", "gen"), "-b", "wt").Run()
+	local := filepath.Join(dir, "scratch")
+	exec.Command("git", "init", "-q", local).Run()
+
+	syncDiscover = true
+	defer func() { syncDiscover = false }()
+	output := captureOutput(func() {
+		if err := runSync(syncCmd, nil); err != nil {
+			t.Fatalf("sync --discover failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "Registered 2 repository(s)") || !strings.Contains(output, "scratch (no remote)") {
+		t.Errorf("unexpected output: %s", output)
+	}
+	if !strings.Contains(output, "wt/gen: git worktree of tools/gen, not registered") {
+		t.Errorf("worktree should be reported, got: %s", output)
+	}
+
+	m, _ := manifest.Load(dir)
+	var paths []string
+	for _, ws := range m.Workspaces {
+		paths = append(paths, ws.Path)
+	}
+	if strings.Join(paths, ",") != "apps/web,scratch,tools/gen" {
+		t.Errorf("ignored, excluded and worktree directories should be skipped, got %v", paths)
+	}
+	if m.Discover == nil || !m.Discover.LocalRepos || m.Find("scratch").Repo != "" {
+		t.Errorf("discover settings should be kept and scratch registered without repo, got %+v", m)
+	}
+	if problems := m.Validate(); len(problems) > 0 {
+		t.Errorf("local repository should be valid, got %v", problems)
+	}
+
+	// A local repository has nothing to clone from
+	os.RemoveAll(local)
+	syncDiscover = false
+	output = captureOutput(func() {
+		if err := runSync(syncCmd, nil); err != nil {
+			t.Fatalf("sync failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "No repo URL (local repository), not cloned") {
+		t.Errorf("unexpected output: %s", output)
+	}
+}
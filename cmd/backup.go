@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/yejune/git-multirepo/internal/backup"
+	"github.com/yejune/git-multirepo/internal/config"
+	"github.com/yejune/git-multirepo/internal/git"
+	"github.com/yejune/git-multirepo/internal/manifest"
+)
+
+var backupPruneDryRun bool
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Manage backups in .multirepos/backup",
+}
+
+var backupPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove backups exceeding the retention settings",
+	Long: `Remove backups exceeding the retention settings.
+
+Retention is read from the backup section of .git.multirepos:
+
+  backup:
+    keep_days: 30      # Live backups (modified/, patched/) older than this are removed
+    keep_months: 12    # Monthly archives older than this are removed
+    max_size: 500MB    # Oldest backups are removed until the total fits
+
+or from ~/.git.multirepo (backup.keepDays, backup.keepMonths, backup.maxSize).
+Values in the manifest take precedence. Unset values mean unlimited.
+
+Pruning also runs automatically with the daily archive check in sync.
+
+Examples:
+  git multirepo backup prune --dry-run   # Show what would be removed
+  git multirepo backup prune`,
+	Args: cobra.NoArgs,
+	RunE: runBackupPrune,
+}
+
+func init() {
+	backupPruneCmd.Flags().BoolVar(&backupPruneDryRun, "dry-run", false, "Show what would be removed without removing anything")
+	backupCmd.AddCommand(backupPruneCmd)
+	rootCmd.AddCommand(backupCmd)
+}
+
+// resolveRetention merges backup retention from the manifest and ~/.git.multirepo.
+// Each value set in the manifest overrides the home config.
+func resolveRetention(m *manifest.Manifest) (backup.Retention, error) {
+	settings, err := config.GetBackupSettings()
+	if err != nil {
+		return backup.Retention{}, err
+	}
+
+	if m.Backup != nil {
+		if m.Backup.KeepDays > 0 {
+			settings.KeepDays = m.Backup.KeepDays
+		}
+		if m.Backup.KeepMonths > 0 {
+			settings.KeepMonths = m.Backup.KeepMonths
+		}
+		if m.Backup.MaxSize != "" {
+			settings.MaxSize = m.Backup.MaxSize
+		}
+	}
+
+	maxSize, err := backup.ParseSize(settings.MaxSize)
+	if err != nil {
+		return backup.Retention{}, fmt.Errorf("invalid backup max_size: %w", err)
+	}
+
+	return backup.Retention{
+		KeepDays:   settings.KeepDays,
+		KeepMonths: settings.KeepMonths,
+		MaxSize:    maxSize,
+	}, nil
+}
+
+func runBackupPrune(cmd *cobra.Command, args []string) error {
+	repoRoot, err := git.GetRepoRoot()
+	if err != nil {
+		return fmt.Errorf("not in a git repository: %w", err)
+	}
+
+	m, err := manifest.Load(repoRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load manifest: %w", err)
+	}
+
+	retention, err := resolveRetention(m)
+	if err != nil {
+		return err
+	}
+	if retention.IsZero() {
+		fmt.Println("No backup retention configured (see 'git multirepo backup prune --help').")
+		return nil
+	}
+
+	backupDir := filepath.Join(repoRoot, ".multirepos", "backup")
+	result, err := backup.Prune(backupDir, retention, backupPruneDryRun)
+	if err != nil {
+		return err
+	}
+
+	if len(result.Items) == 0 {
+		fmt.Printf("Nothing to prune (%s of backups).\n", backup.FormatSize(result.Total))
+		return nil
+	}
+
+	verb := "Removed"
+	if backupPruneDryRun {
+		verb = "Would remove"
+	}
+	for _, item := range result.Items {
+		rel, err := filepath.Rel(backupDir, item.Path)
+		if err != nil {
+			rel = item.Path
+		}
+		fmt.Printf("  %s  %-11s  %s\n", filepath.ToSlash(rel), item.Reason, backup.FormatSize(item.Size))
+	}
+	fmt.Printf("\n%s %d backup(s), %s of %s\n", verb, len(result.Items), backup.FormatSize(result.Freed), backup.FormatSize(result.Total))
+	return nil
+}
+
+// pruneBackups applies the retention settings after the daily archive in sync
+func pruneBackups(m *manifest.Manifest, backupDir string) error {
+	retention, err := resolveRetention(m)
+	if err != nil {
+		return err
+	}
+	if retention.IsZero() {
+		return nil
+	}
+
+	result, err := backup.Prune(backupDir, retention, false)
+	if err != nil {
+		return err
+	}
+	if len(result.Items) > 0 {
+		fmt.Printf("\nPruned %d old backup(s), freed %s\n", len(result.Items), backup.FormatSize(result.Freed))
+	}
+	return nil
+}
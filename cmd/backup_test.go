@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yejune/git-multirepo/internal/manifest"
+)
+
+// writeOldBackup creates a modified-file backup taken daysAgo days ago
+func writeOldBackup(t *testing.T, dir string, daysAgo int) string {
+	t.Helper()
+	ts := time.Now().AddDate(0, 0, -daysAgo)
+	path := filepath.Join(dir, ".multirepos", "backup", "modified", ts.Format("2006/01/02"), "config."+ts.Format("20060102_150405")+".json")
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestBackupPrune(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer func() { backupPruneDryRun = false }()
+	t.Setenv("HOME", t.TempDir())
+
+	recent := writeOldBackup(t, dir, 1)
+	old := writeOldBackup(t, dir, 40)
+
+	t.Run("no retention configured", func(t *testing.T) {
+		output := captureOutput(func() {
+			if err := runBackupPrune(backupPruneCmd, nil); err != nil {
+				t.Fatalf("backup prune failed: %v", err)
+			}
+		})
+		if !strings.Contains(output, "No backup retention configured") {
+			t.Errorf("expected no retention message, got: %s", output)
+		}
+	})
+
+	manifest.Save(dir, &manifest.Manifest{Backup: &manifest.BackupConfig{KeepDays: 30}})
+
+	t.Run("dry run", func(t *testing.T) {
+		backupPruneDryRun = true
+		defer func() { backupPruneDryRun = false }()
+
+		output := captureOutput(func() {
+			if err := runBackupPrune(backupPruneCmd, nil); err != nil {
+				t.Fatalf("backup prune failed: %v", err)
+			}
+		})
+		if !strings.Contains(output, "Would remove 1 backup(s)") {
+			t.Errorf("expected dry run summary, got: %s", output)
+		}
+		if _, err := os.Stat(old); err != nil {
+			t.Error("dry run should not remove backups")
+		}
+	})
+
+	t.Run("prune", func(t *testing.T) {
+		output := captureOutput(func() {
+			if err := runBackupPrune(backupPruneCmd, nil); err != nil {
+				t.Fatalf("backup prune failed: %v", err)
+			}
+		})
+		if !strings.Contains(output, "Removed 1 backup(s)") {
+			t.Errorf("expected summary, got: %s", output)
+		}
+		if _, err := os.Stat(old); !os.IsNotExist(err) {
+			t.Error("old backup should be removed")
+		}
+		if _, err := os.Stat(recent); err != nil {
+			t.Error("recent backup should be kept")
+		}
+	})
+}
+
+func TestResolveRetention_ManifestOverridesHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configPath := filepath.Join(home, ".git.multirepo")
+	exec.Command("git", "config", "-f", configPath, "backup.keepDays", "10").Run()
+	exec.Command("git", "config", "-f", configPath, "backup.maxSize", "1MB").Run()
+
+	retention, err := resolveRetention(&manifest.Manifest{Backup: &manifest.BackupConfig{KeepDays: 30, KeepMonths: 6}})
+	if err != nil {
+		t.Fatalf("resolveRetention failed: %v", err)
+	}
+	if retention.KeepDays != 30 || retention.KeepMonths != 6 || retention.MaxSize != 1<<20 {
+		t.Errorf("unexpected retention: %+v", retention)
+	}
+
+	if _, err := resolveRetention(&manifest.Manifest{Backup: &manifest.BackupConfig{MaxSize: "huge"}}); err == nil {
+		t.Error("expected error for invalid max_size")
+	}
+}
@@ -2,18 +2,19 @@ package cmd
 
 import (
 	"fmt"
-	"os/exec"
+	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/yejune/git-multirepo/internal/common"
 	"github.com/yejune/git-multirepo/internal/git"
 	"github.com/yejune/git-multirepo/internal/manifest"
 )
 
 var branchCmd = &cobra.Command{
-	Use:   "branch [repository-path]",
-	Short: "Show branch information for repositories",
+	Use:   "branch [path...]",
+	Short: "Show, create, switch and delete branches across repositories",
 	Long: `Display current branch for all repositories or a specific repository.
 
 Shows:
@@ -21,18 +22,98 @@ Shows:
   - Repository URL
   - Current branch
 
+Use the create, checkout and delete subcommands to work on the same branch
+across several repositories.
+
 Examples:
   git-multirepo branch                 # Show all repositories
-  git-multirepo branch packages/lib    # Show specific repository`,
-	Args: cobra.MaximumNArgs(1),
+  git-multirepo branch packages/lib    # Show specific repository
+  git-multirepo branch -g frontend     # Show repositories in group "frontend"
+  git-multirepo branch -o json         # Machine-readable output (json or yaml)
+  git-multirepo branch create feature/x -g backend
+  git-multirepo branch checkout main`,
 	RunE: runBranch,
 }
 
+var branchCreateCmd = &cobra.Command{
+	Use:   "create <branch> [path...]",
+	Short: "Create and check out a branch in repositories",
+	Long: `Create a branch and check it out in all or the selected repositories.
+
+Repositories with uncommitted changes (other than keep files) are refused
+before anything is changed. Keep files keep their local content.
+
+Examples:
+  git-multirepo branch create feature/x                   # All repositories
+  git-multirepo branch create feature/x apps/api apps/web
+  git-multirepo branch create feature/x -g backend --from origin/main
+  git-multirepo branch create feature/x --update-manifest # Also set branch: in manifest`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runBranchCreate,
+}
+
+var branchCheckoutCmd = &cobra.Command{
+	Use:   "checkout <branch> [path...]",
+	Short: "Check out a branch in repositories",
+	Long: `Check out an existing branch in all or the selected repositories.
+
+A branch that only exists on origin is created to track it. Repositories with
+uncommitted changes (other than keep files) are refused before anything is
+changed. Keep files keep their local content.
+
+Examples:
+  git-multirepo branch checkout main
+  git-multirepo branch checkout feature/x -g backend
+  git-multirepo branch checkout main --update-manifest`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runBranchCheckout,
+}
+
+var branchDeleteCmd = &cobra.Command{
+	Use:   "delete <branch> [path...]",
+	Short: "Delete a local branch in repositories",
+	Long: `Delete a local branch in all or the selected repositories that have it.
+
+Unmerged branches are only deleted with --force. The checked out branch is
+never deleted.
+
+Examples:
+  git-multirepo branch delete feature/x
+  git-multirepo branch delete feature/x -g backend --force`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runBranchDelete,
+}
+
+var (
+	branchOutput         string
+	branchGroups         []string
+	branchFrom           string
+	branchUpdateManifest bool
+	branchForce          bool
+)
+
 func init() {
+	branchCmd.Flags().StringVarP(&branchOutput, "output", "o", outputText, "Output format: text, json or yaml")
+	addGroupFlag(branchCmd, &branchGroups)
+
+	branchCreateCmd.Flags().StringVar(&branchFrom, "from", "", "Start point of the new branch (default: current HEAD)")
+	for _, c := range []*cobra.Command{branchCreateCmd, branchCheckoutCmd} {
+		c.Flags().BoolVar(&branchUpdateManifest, "update-manifest", false, "Set the branch of each repository in "+manifest.FileName)
+	}
+	branchDeleteCmd.Flags().BoolVarP(&branchForce, "force", "f", false, "Delete even if the branch is not merged")
+	for _, c := range []*cobra.Command{branchCreateCmd, branchCheckoutCmd, branchDeleteCmd} {
+		addGroupFlag(c, &branchGroups)
+	}
+
+	branchCmd.AddCommand(branchCreateCmd, branchCheckoutCmd, branchDeleteCmd)
 	rootCmd.AddCommand(branchCmd)
 }
 
 func runBranch(cmd *cobra.Command, args []string) error {
+	if err := validateOutputFormat(branchOutput); err != nil {
+		return err
+	}
+
 	repoRoot, err := git.GetRepoRoot()
 	if err != nil {
 		return fmt.Errorf("not in a git repository: %w", err)
@@ -43,20 +124,39 @@ func runBranch(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load manifest: %w", err)
 	}
 
+	selector := common.Selector{Patterns: args, Groups: branchGroups}
+
+	if branchOutput != outputText {
+		workspaces, err := selector.Select(m.Workspaces)
+		if err != nil {
+			return err
+		}
+
+		reports := []workspaceReport{}
+		for i := range workspaces {
+			reports = append(reports, collectBranchInfo(repoRoot, &workspaces[i]))
+		}
+		return writeStructured(os.Stdout, branchOutput, workspacesReport{Workspaces: reports})
+	}
+
 	if len(m.Workspaces) == 0 {
 		fmt.Println("No repositories registered.")
 		return nil
 	}
 
-	// Show specific workspace
-	if len(args) == 1 {
-		path := args[0]
-		ws := m.Find(path)
-		if ws == nil {
-			return fmt.Errorf("repository not found: %s", path)
-		}
+	workspaces, err := selector.Select(m.Workspaces)
+	if err != nil {
+		return err
+	}
 
-		return showBranchInfo(repoRoot, ws)
+	// Show specific workspaces
+	if !selector.IsEmpty() {
+		for i := range workspaces {
+			if err := showBranchInfo(repoRoot, &workspaces[i]); err != nil {
+				fmt.Printf("  %s: %v\n", workspaces[i].Path, err)
+			}
+		}
+		return nil
 	}
 
 	// Show all workspaces
@@ -70,39 +170,242 @@ func runBranch(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func showBranchInfo(repoRoot string, ws *manifest.WorkspaceEntry) error {
+// collectBranchInfo returns the current and tracking branch of a workspace
+func collectBranchInfo(repoRoot string, ws *manifest.WorkspaceEntry) workspaceReport {
 	fullPath := filepath.Join(repoRoot, ws.Path)
+	report := newWorkspaceReport(ws)
 
 	if !git.IsRepo(fullPath) {
-		fmt.Printf("  %s: not cloned\n", ws.Path)
-		return nil
+		report.State = "not_cloned"
+		return report
 	}
+	report.Cloned = true
 
-	// Get current branch
-	cmdBranch := exec.Command("git", "-C", fullPath, "rev-parse", "--abbrev-ref", "HEAD")
-	output, err := cmdBranch.Output()
+	branch, err := git.GetCurrentBranch(fullPath)
 	if err != nil {
-		fmt.Printf("  %s: failed to get branch\n", ws.Path)
-		return nil
+		report.State = "error"
+		report.Error = "failed to get branch"
+		return report
 	}
+	report.CurrentBranch = branch
 
-	branch := strings.TrimSpace(string(output))
+	// Remote tracking branch is optional
+	report.TrackingBranch, _ = git.GetTrackingBranch(fullPath)
+
+	return report
+}
 
-	// Get remote tracking branch
-	cmdTracking := exec.Command("git", "-C", fullPath, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{u}")
-	trackingOutput, err := cmdTracking.Output()
-	tracking := ""
-	if err == nil {
-		tracking = strings.TrimSpace(string(trackingOutput))
+func showBranchInfo(repoRoot string, ws *manifest.WorkspaceEntry) error {
+	report := collectBranchInfo(repoRoot, ws)
+
+	if !report.Cloned {
+		fmt.Printf("  %s: not cloned\n", ws.Path)
+		return nil
+	}
+
+	if report.Error != "" {
+		fmt.Printf("  %s: %s\n", ws.Path, report.Error)
+		return nil
 	}
 
 	fmt.Printf("  %s\n", ws.Path)
 	fmt.Printf("    Repo:   %s\n", ws.Repo)
-	fmt.Printf("    Branch: %s", branch)
-	if tracking != "" {
-		fmt.Printf(" → %s", tracking)
+	fmt.Printf("    Branch: %s", report.CurrentBranch)
+	if report.TrackingBranch != "" {
+		fmt.Printf(" → %s", report.TrackingBranch)
 	}
 	fmt.Println()
 
 	return nil
 }
+
+func runBranchCreate(cmd *cobra.Command, args []string) error {
+	return switchBranches(args[0], args[1:], func(ws *manifest.WorkspaceEntry, fullPath string) (string, error) {
+		if git.BranchExists(fullPath, args[0]) {
+			return "", fmt.Errorf("branch %s already exists (use 'branch checkout')", args[0])
+		}
+		return "created " + args[0], withKeepFilesPreserved(fullPath, ws.Keep, func() error {
+			return git.CreateBranch(fullPath, args[0], branchFrom)
+		})
+	})
+}
+
+func runBranchCheckout(cmd *cobra.Command, args []string) error {
+	return switchBranches(args[0], args[1:], func(ws *manifest.WorkspaceEntry, fullPath string) (string, error) {
+		if current, err := git.GetCurrentBranch(fullPath); err == nil && current == args[0] {
+			return "already on " + args[0], nil
+		}
+		return "switched to " + args[0], withKeepFilesPreserved(fullPath, ws.Keep, func() error {
+			return git.CheckoutBranch(fullPath, args[0])
+		})
+	})
+}
+
+// switchBranches runs switchFn in each selected workspace after checking that
+// none has uncommitted changes, then records the branch in the manifest if requested
+func switchBranches(branch string, paths []string, switchFn func(ws *manifest.WorkspaceEntry, fullPath string) (string, error)) error {
+	ctx, err := common.LoadWorkspaceContext()
+	if err != nil {
+		return err
+	}
+
+	workspaces, err := selectWorkspaces(ctx, paths, branchGroups)
+	if err != nil {
+		return err
+	}
+	if len(workspaces) == 0 {
+		fmt.Println("No repositories registered.")
+		return nil
+	}
+
+	// Refuse before changing anything, so repositories don't end up on mixed branches
+	var dirty []string
+	for _, ws := range workspaces {
+		fullPath := filepath.Join(ctx.RepoRoot, ws.Path)
+		if !git.IsRepo(fullPath) {
+			continue
+		}
+		changed, err := countUncommittedChanges(fullPath, ws.Keep)
+		if err != nil {
+			return fmt.Errorf("%s: failed to get status: %w", ws.Path, err)
+		}
+		if changed > 0 {
+			dirty = append(dirty, fmt.Sprintf("%s (%d uncommitted file(s))", ws.Path, changed))
+		}
+	}
+	if len(dirty) > 0 {
+		return fmt.Errorf("commit or stash changes first:\n  - %s", strings.Join(dirty, "\n  - "))
+	}
+
+	failed := 0
+	var switchedPaths []string
+	for _, ws := range workspaces {
+		fullPath := filepath.Join(ctx.RepoRoot, ws.Path)
+		if !git.IsRepo(fullPath) {
+			fmt.Printf("  - %s: not cloned, skipped\n", ws.Path)
+			continue
+		}
+
+		msg, err := switchFn(&ws, fullPath)
+		if err != nil {
+			fmt.Printf("  ✗ %s: %v\n", ws.Path, err)
+			failed++
+			continue
+		}
+		fmt.Printf("  ✓ %s: %s\n", ws.Path, msg)
+
+		switchedPaths = append(switchedPaths, ws.Path)
+	}
+
+	if branchUpdateManifest && len(switchedPaths) > 0 {
+		entry := beginJournal(ctx.RepoRoot, "branch "+branch+" --update-manifest", ctx.Manifest)
+		defer entry.Finish()
+		if _, err := manifest.Update(ctx.RepoRoot, func(m *manifest.Manifest) error {
+			for _, path := range switchedPaths {
+				if entry := m.Find(path); entry != nil {
+					entry.Branch = branch
+				}
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		fmt.Printf("\nUpdated branch in %s for %d workspace(s)\n", manifest.FileName, len(switchedPaths))
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d workspace(s) failed", failed)
+	}
+	return nil
+}
+
+// countUncommittedChanges counts modified, staged and untracked files, ignoring keep files
+func countUncommittedChanges(fullPath string, keep []string) (int, error) {
+	status, err := git.GetWorkspaceStatus(fullPath, keep)
+	if err != nil {
+		return 0, err
+	}
+
+	isKeep := map[string]bool{}
+	for _, file := range keep {
+		isKeep[file] = true
+	}
+
+	count := len(status.UntrackedFiles) + len(status.StagedFiles)
+	for _, file := range status.ModifiedFiles {
+		if !isKeep[file] {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// withKeepFilesPreserved runs fn with keep files reset to HEAD so that git can
+// switch branches, then writes their local content back
+func withKeepFilesPreserved(fullPath string, keep []string, fn func() error) error {
+	return git.WithSkipWorktreeTransaction(fullPath, keep, func() error {
+		kept := map[string][]byte{}
+		for _, file := range keep {
+			content, err := os.ReadFile(filepath.Join(fullPath, file))
+			if err != nil {
+				continue // Missing keep file: nothing to carry over
+			}
+			kept[file] = content
+			if git.IsTracked(fullPath, file) {
+				if err := git.DiscardFileChanges(fullPath, file); err != nil {
+					return fmt.Errorf("failed to set aside %s: %w", file, err)
+				}
+			}
+		}
+
+		err := fn()
+		if restoreErr := restoreKeptFiles(fullPath, kept); err == nil {
+			err = restoreErr
+		}
+		return err
+	})
+}
+
+func runBranchDelete(cmd *cobra.Command, args []string) error {
+	branch := args[0]
+
+	ctx, err := common.LoadWorkspaceContext()
+	if err != nil {
+		return err
+	}
+
+	workspaces, err := selectWorkspaces(ctx, args[1:], branchGroups)
+	if err != nil {
+		return err
+	}
+
+	failed, deleted := 0, 0
+	for _, ws := range workspaces {
+		fullPath := filepath.Join(ctx.RepoRoot, ws.Path)
+		if !git.IsRepo(fullPath) || !git.BranchExists(fullPath, branch) {
+			continue
+		}
+
+		if current, _ := git.GetCurrentBranch(fullPath); current == branch {
+			fmt.Printf("  ✗ %s: %s is checked out\n", ws.Path, branch)
+			failed++
+			continue
+		}
+
+		if err := git.DeleteBranch(fullPath, branch, branchForce); err != nil {
+			fmt.Printf("  ✗ %s: %v\n", ws.Path, err)
+			failed++
+			continue
+		}
+		fmt.Printf("  ✓ %s: deleted %s\n", ws.Path, branch)
+		deleted++
+	}
+
+	if deleted == 0 && failed == 0 {
+		fmt.Printf("No repositories have branch %s\n", branch)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d workspace(s) failed", failed)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -257,3 +258,125 @@ func TestRunBranch_CorruptedGitRepo(t *testing.T) {
 		}
 	})
 }
+
+// Branch Switching Tests
+
+// resetBranchFlags restores branch subcommand flags to their defaults
+func resetBranchFlags() {
+	branchGroups = nil
+	branchFrom = ""
+	branchUpdateManifest = false
+	branchForce = false
+}
+
+// currentBranch returns the checked out branch of a workspace
+func currentBranch(t *testing.T, path string) string {
+	t.Helper()
+	out, err := exec.Command("git", "-C", path, "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		t.Fatalf("rev-parse failed: %v", err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestRunBranch_CreateCheckoutDelete(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetBranchFlags()
+
+	remoteRepo := setupRemoteRepoWithCommits(t)
+	setupWorkspaceWithKeepFile(t, dir, remoteRepo, "packages/ws1")
+	cloneBranch = ""
+	runClone(cloneCmd, []string{setupRemoteRepo(t), "packages/ws2"})
+
+	ws1 := filepath.Join(dir, "packages/ws1")
+	ws2 := filepath.Join(dir, "packages/ws2")
+	mainBranch := currentBranch(t, ws1)
+
+	// Local keep file change travels across branch switches
+	configPath := filepath.Join(ws1, "config.yml")
+	os.WriteFile(configPath, []byte("local: true\n"), 0644)
+	exec.Command("git", "-C", ws1, "update-index", "--skip-worktree", "config.yml").Run()
+
+	branchUpdateManifest = true
+	output := captureOutput(func() {
+		if err := runBranchCreate(branchCreateCmd, []string{"feature/x"}); err != nil {
+			t.Fatalf("branch create failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "packages/ws1: created feature/x") || !strings.Contains(output, "packages/ws2: created feature/x") {
+		t.Errorf("expected both workspaces created, got: %s", output)
+	}
+	for _, ws := range []string{ws1, ws2} {
+		if got := currentBranch(t, ws); got != "feature/x" {
+			t.Errorf("%s should be on feature/x, got %s", ws, got)
+		}
+	}
+	if content, _ := os.ReadFile(configPath); string(content) != "local: true\n" {
+		t.Errorf("keep file content should be preserved, got %q", content)
+	}
+	m, _ := manifest.Load(dir)
+	if m.Workspaces[0].Branch != "feature/x" || m.Workspaces[1].Branch != "feature/x" {
+		t.Errorf("manifest branches should be updated, got %+v", m.Workspaces)
+	}
+	branchUpdateManifest = false
+
+	t.Run("create refuses existing branch", func(t *testing.T) {
+		captureOutput(func() {
+			if err := runBranchCreate(branchCreateCmd, []string{"feature/x", "packages/ws2"}); err == nil {
+				t.Error("expected error for existing branch")
+			}
+		})
+	})
+
+	t.Run("checkout selected workspace", func(t *testing.T) {
+		captureOutput(func() {
+			if err := runBranchCheckout(branchCheckoutCmd, []string{mainBranch, "packages/ws1"}); err != nil {
+				t.Fatalf("branch checkout failed: %v", err)
+			}
+		})
+		if got := currentBranch(t, ws1); got != mainBranch {
+			t.Errorf("ws1 should be on %s, got %s", mainBranch, got)
+		}
+		if got := currentBranch(t, ws2); got != "feature/x" {
+			t.Errorf("ws2 should stay on feature/x, got %s", got)
+		}
+		if content, _ := os.ReadFile(configPath); string(content) != "local: true\n" {
+			t.Errorf("keep file content should be preserved, got %q", content)
+		}
+	})
+
+	t.Run("delete skips checked out branch", func(t *testing.T) {
+		var err error
+		output := captureOutput(func() { err = runBranchDelete(branchDeleteCmd, []string{"feature/x"}) })
+		if err == nil {
+			t.Error("expected error for checked out branch in ws2")
+		}
+		if !strings.Contains(output, "packages/ws1: deleted feature/x") || !strings.Contains(output, "packages/ws2: feature/x is checked out") {
+			t.Errorf("unexpected output: %s", output)
+		}
+	})
+}
+
+func TestRunBranch_CheckoutRefusesDirtyWorkspace(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetBranchFlags()
+
+	cloneBranch = ""
+	runClone(cloneCmd, []string{setupRemoteRepo(t), "packages/ws1"})
+	runClone(cloneCmd, []string{setupRemoteRepo(t), "packages/ws2"})
+
+	os.WriteFile(filepath.Join(dir, "packages/ws2", "README.md"), []byte("changed"), 0644)
+
+	var err error
+	captureOutput(func() { err = runBranchCreate(branchCreateCmd, []string{"feature/x"}) })
+	if err == nil || !strings.Contains(err.Error(), "packages/ws2") {
+		t.Fatalf("expected dirty workspace error, got: %v", err)
+	}
+
+	// Nothing changed, not even in the clean workspace
+	if got := currentBranch(t, filepath.Join(dir, "packages/ws1")); got == "feature/x" {
+		t.Error("clean workspace should not be switched when another is dirty")
+	}
+}
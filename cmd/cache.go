@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yejune/git-multirepo/internal/backup"
+	"github.com/yejune/git-multirepo/internal/common"
+	"github.com/yejune/git-multirepo/internal/config"
+	"github.com/yejune/git-multirepo/internal/git"
+	"github.com/yejune/git-multirepo/internal/manifest"
+)
+
+var (
+	cacheJobs      int
+	cacheGroups    []string
+	cacheOlderThan int
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the shared mirror cache",
+	Long: `Manage the shared mirror cache.
+
+Clones made by clone and sync borrow objects from a bare mirror of the
+repository in ~/.cache/git-multirepo/mirrors/<host>/<path>.git, which is
+created or fetched before each clone (git clone --reference-if-able
+--dissociate). Repeated clones of the same repository, in any project, only
+download what the mirror lacks. Workspaces never depend on the cache, so it
+can be removed at any time.
+
+Local repository paths, shallow clones (depth) and partial clones (filter) do
+not use the cache.
+
+Settings in ~/.git.multirepo:
+  cache.enabled   false to disable the cache (default true)
+  cache.dir       Cache location (default ~/.cache/git-multirepo/mirrors)`,
+}
+
+var cacheUpdateCmd = &cobra.Command{
+	Use:   "update [path...]",
+	Short: "Create or fetch the mirrors of the workspaces",
+	Long: `Create or fetch the mirrors of the workspaces in .git.multirepos.
+
+Examples:
+  git multirepo cache update             # All workspaces
+  git multirepo cache update -g backend  # Workspaces in a group`,
+	RunE: runCacheUpdate,
+}
+
+var cacheListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the mirrors in the cache",
+	Args:  cobra.NoArgs,
+	RunE:  runCacheList,
+}
+
+var cacheGCCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Compact the mirrors and remove unused ones",
+	Long: `Run git gc in every mirror of the cache.
+
+With --older-than, mirrors that have not been updated for that many days are
+removed instead.
+
+Examples:
+  git multirepo cache gc
+  git multirepo cache gc --older-than 90`,
+	Args: cobra.NoArgs,
+	RunE: runCacheGC,
+}
+
+func init() {
+	cacheUpdateCmd.Flags().IntVarP(&cacheJobs, "jobs", "j", common.DefaultJobs(), "Number of mirrors to update concurrently")
+	addGroupFlag(cacheUpdateCmd, &cacheGroups)
+	cacheGCCmd.Flags().IntVar(&cacheOlderThan, "older-than", 0, "Remove mirrors not updated for this many days")
+	cacheCmd.AddCommand(cacheUpdateCmd, cacheListCmd, cacheGCCmd)
+	rootCmd.AddCommand(cacheCmd)
+	cobra.OnInitialize(configureMirrorCache)
+}
+
+// configureMirrorCache applies the cache settings of ~/.git.multirepo
+func configureMirrorCache() {
+	settings, err := config.GetCacheSettings()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠ %v\n", err)
+		return
+	}
+	if settings.Disabled {
+		git.MirrorRoot = ""
+	} else if settings.Dir != "" {
+		git.MirrorRoot = settings.Dir
+	}
+}
+
+// cacheDisabled prints a notice and returns true if the cache is disabled
+func cacheDisabled() bool {
+	if git.MirrorRoot == "" {
+		fmt.Println("Mirror cache is disabled (cache.enabled in ~/.git.multirepo).")
+		return true
+	}
+	return false
+}
+
+func runCacheUpdate(cmd *cobra.Command, args []string) error {
+	if cacheDisabled() {
+		return nil
+	}
+
+	ctx, err := common.LoadWorkspaceContext()
+	if err != nil {
+		return err
+	}
+
+	selected, err := selectWorkspaces(ctx, args, cacheGroups)
+	if err != nil {
+		return err
+	}
+
+	// One update per mirror, even if several workspaces share a repository
+	var workspaces []manifest.WorkspaceEntry
+	seen := make(map[string]bool)
+	for _, ws := range selected {
+		mirror := git.MirrorPath(ws.Repo)
+		if mirror == "" {
+			fmt.Printf("○ %s: local repository, not cached\n", ws.Path)
+			continue
+		}
+		if !seen[mirror] {
+			seen[mirror] = true
+			workspaces = append(workspaces, ws)
+		}
+	}
+
+	errs := ctx.RunWorkspacesParallel(workspaces, cacheJobs, os.Stdout, func(ws *manifest.WorkspaceEntry, fullPath string, out io.Writer) error {
+		var gitOutput bytes.Buffer
+		if err := git.UpdateMirror(ws.Repo, &gitOutput); err != nil {
+			fmt.Fprintf(out, "✗ %s: %v\n", ws.Repo, err)
+			printIndented(out, gitOutput.String())
+			return err
+		}
+		fmt.Fprintf(out, "✓ %s\n", ws.Repo)
+		return nil
+	})
+
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d mirror(s) failed to update", failed)
+	}
+	return nil
+}
+
+func runCacheList(cmd *cobra.Command, args []string) error {
+	if cacheDisabled() {
+		return nil
+	}
+
+	mirrors, err := git.ListMirrors()
+	if err != nil {
+		return fmt.Errorf("failed to read mirror cache: %w", err)
+	}
+	if len(mirrors) == 0 {
+		fmt.Printf("No mirrors in %s\n", git.MirrorRoot)
+		return nil
+	}
+
+	fmt.Printf("Mirror cache: %s\n\n", git.MirrorRoot)
+	var total int64
+	for _, m := range mirrors {
+		fmt.Printf("  %-50s %9s  %s\n", m.Name, backup.FormatSize(m.Size), m.Updated.Format("2006-01-02 15:04"))
+		total += m.Size
+	}
+	fmt.Printf("\n%d mirror(s), %s\n", len(mirrors), backup.FormatSize(total))
+	return nil
+}
+
+func runCacheGC(cmd *cobra.Command, args []string) error {
+	if cacheDisabled() {
+		return nil
+	}
+
+	mirrors, err := git.ListMirrors()
+	if err != nil {
+		return fmt.Errorf("failed to read mirror cache: %w", err)
+	}
+
+	failed := 0
+	for _, m := range mirrors {
+		if cacheOlderThan > 0 && time.Since(m.Updated) > time.Duration(cacheOlderThan)*24*time.Hour {
+			if err := git.RemoveMirror(m.Path); err != nil {
+				fmt.Printf("✗ %s: %v\n", m.Name, err)
+				failed++
+				continue
+			}
+			fmt.Printf("✓ Removed %s (%s, not updated since %s)\n", m.Name, backup.FormatSize(m.Size), m.Updated.Format("2006-01-02"))
+			continue
+		}
+
+		var gitOutput bytes.Buffer
+		if err := git.GCMirror(m.Path, &gitOutput); err != nil {
+			fmt.Printf("✗ %s: %v\n", m.Name, err)
+			printIndented(os.Stdout, gitOutput.String())
+			failed++
+			continue
+		}
+		fmt.Printf("✓ Compacted %s\n", m.Name)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d mirror(s) failed", failed)
+	}
+	return nil
+}
+
+// printIndented prints git's output below a result line
+func printIndented(out io.Writer, text string) {
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		if line != "" {
+			fmt.Fprintf(out, "  %s\n", line)
+		}
+	}
+}
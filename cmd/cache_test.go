@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yejune/git-multirepo/internal/git"
+	"github.com/yejune/git-multirepo/internal/manifest"
+)
+
+// TestMain keeps the mirrors of clones made by tests out of the user's cache
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "git-multirepo-mirrors-")
+	if err == nil {
+		git.MirrorRoot = dir
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestCacheCommands(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	saved := git.MirrorRoot
+	git.MirrorRoot = t.TempDir()
+	defer func() { git.MirrorRoot = saved }()
+
+	remote := "file://" + filepath.ToSlash(setupRemoteRepo(t))
+	writeTestManifest(t, dir,
+		manifest.WorkspaceEntry{Path: "a", Repo: remote},
+		manifest.WorkspaceEntry{Path: "b", Repo: remote},
+		manifest.WorkspaceEntry{Path: "local", Repo: setupRemoteRepo(t)},
+	)
+
+	output := captureOutput(func() {
+		if err := runCacheUpdate(cacheUpdateCmd, nil); err != nil {
+			t.Fatalf("cache update failed: %v", err)
+		}
+	})
+	if strings.Count(output, "✓ "+remote) != 1 {
+		t.Errorf("repository shared by two workspaces should be updated once, got: %s", output)
+	}
+	if !strings.Contains(output, "○ local: local repository, not cached") {
+		t.Errorf("local repository should be skipped, got: %s", output)
+	}
+
+	mirror := git.MirrorPath(remote)
+	if _, err := os.Stat(filepath.Join(mirror, "HEAD")); err != nil {
+		t.Fatalf("mirror should be created at %s", mirror)
+	}
+
+	// sync clones through the mirror
+	captureOutput(func() {
+		if err := runSync(syncCmd, nil); err != nil {
+			t.Fatalf("sync failed: %v", err)
+		}
+	})
+	if !git.IsRepo(filepath.Join(dir, "a")) {
+		t.Error("workspace should be cloned")
+	}
+
+	output = captureOutput(func() {
+		if err := runCacheList(cacheListCmd, nil); err != nil {
+			t.Fatalf("cache list failed: %v", err)
+		}
+	})
+	name, _ := filepath.Rel(git.MirrorRoot, mirror)
+	if !strings.Contains(output, filepath.ToSlash(name)) || !strings.Contains(output, "1 mirror(s)") {
+		t.Errorf("cache list should show the mirror, got: %s", output)
+	}
+
+	output = captureOutput(func() {
+		if err := runCacheGC(cacheGCCmd, nil); err != nil {
+			t.Fatalf("cache gc failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "✓ Compacted") {
+		t.Errorf("cache gc should compact the mirror, got: %s", output)
+	}
+
+	// Mirrors not updated for longer than --older-than are removed
+	old := time.Now().AddDate(0, 0, -100)
+	for _, file := range []string{"HEAD", "FETCH_HEAD"} {
+		os.Chtimes(filepath.Join(mirror, file), old, old)
+	}
+	cacheOlderThan = 90
+	defer func() { cacheOlderThan = 0 }()
+	output = captureOutput(func() {
+		if err := runCacheGC(cacheGCCmd, nil); err != nil {
+			t.Fatalf("cache gc failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "✓ Removed") {
+		t.Errorf("cache gc should remove the old mirror, got: %s", output)
+	}
+	if _, err := os.Stat(mirror); !os.IsNotExist(err) {
+		t.Error("old mirror should be removed")
+	}
+}
@@ -17,6 +17,9 @@ var (
 	// Clone command flags
 	cloneBranch string
 	clonePath   string
+	cloneDepth  int
+	cloneFilter string
+	cloneSparse []string
 )
 
 var cloneCmd = &cobra.Command{
@@ -30,7 +33,12 @@ while the parent project tracks the source files (but not .git).
 Examples:
   git multirepo clone https://github.com/user/repo.git           # Clone to ./repo
   git multirepo clone https://github.com/user/repo.git lib/repo  # Clone to lib/repo
-  git multirepo clone -b develop https://github.com/user/repo.git`,
+  git multirepo clone -b develop https://github.com/user/repo.git
+
+Large repositories can be cloned shallow (--depth), partially (--filter) and/or
+with only some directories checked out (--sparse). The options are recorded in
+.git.multirepos and used by sync and pull:
+  git multirepo clone --depth 1 --filter blob:none --sparse services/api https://github.com/org/monorepo.git`,
 	Args: cobra.RangeArgs(1, 2),
 	RunE: runClone,
 }
@@ -38,6 +46,9 @@ Examples:
 func init() {
 	cloneCmd.Flags().StringVarP(&cloneBranch, "branch", "b", "", "Branch to clone")
 	cloneCmd.Flags().StringVarP(&clonePath, "path", "p", "", "Destination path")
+	cloneCmd.Flags().IntVar(&cloneDepth, "depth", 0, "Shallow clone with this many commits")
+	cloneCmd.Flags().StringVar(&cloneFilter, "filter", "", "Partial clone filter, e.g. blob:none")
+	cloneCmd.Flags().StringArrayVar(&cloneSparse, "sparse", nil, "Only check out this directory (repeatable)")
 	rootCmd.AddCommand(cloneCmd)
 }
 
@@ -84,16 +95,34 @@ func runClone(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
 
+	entry := beginJournal(repoRoot, "clone "+repo+" "+path, m)
+	defer entry.Finish()
+
 	// Clone the repository
 	fmt.Printf("Cloning %s into %s...\n", repo, path)
-	if err := git.Clone(repo, fullPath, cloneBranch); err != nil {
+	opts := git.CloneOptions{Branch: cloneBranch, Depth: cloneDepth, Filter: cloneFilter, Sparse: cloneSparse}
+	// The manifest records repo as given; rewrite rules apply when cloning
+	source := repo
+	if urls := remoteURLs(m, &manifest.WorkspaceEntry{Repo: repo}); len(urls) > 0 {
+		source = urls[0]
+	}
+	if err := git.CloneWithOptions(source, fullPath, opts, os.Stdout); err != nil {
 		return fmt.Errorf("failed to clone: %w", err)
 	}
 
-	// Add to manifest
-	m.Add(path, repo)
-	if err := manifest.Save(repoRoot, m); err != nil {
-		return fmt.Errorf("failed to save manifest: %w", err)
+	// Add to manifest (reloaded under the lock, in case it changed while cloning)
+	if _, err := manifest.Update(repoRoot, func(m *manifest.Manifest) error {
+		if m.Exists(path) {
+			return fmt.Errorf("repository already exists at %s", path)
+		}
+		m.Add(path, repo)
+		ws := m.Find(path)
+		ws.Depth = cloneDepth
+		ws.Filter = cloneFilter
+		ws.Sparse = cloneSparse
+		return nil
+	}); err != nil {
+		return err
 	}
 
 	// Add .git directory to parent's .gitignore
@@ -471,6 +471,128 @@ func TestStatusWithModifiedWorkspace(t *testing.T) {
 	})
 }
 
+func TestStatusParallelKeepsManifestOrder(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	cloneBranch = ""
+	paths := []string{"packages/one", "packages/two", "packages/three"}
+	for _, p := range paths {
+		runClone(cloneCmd, []string{setupRemoteRepo(t), p})
+	}
+
+	t.Run("status output is grouped per workspace in manifest order", func(t *testing.T) {
+		oldJobs := statusJobs
+		statusJobs = 3
+		defer func() { statusJobs = oldJobs }()
+
+		output := captureOutput(func() {
+			runStatus(statusCmd, []string{})
+		})
+
+		last := -1
+		for _, p := range paths {
+			idx := strings.Index(output, p+" (")
+			if idx < 0 {
+				t.Fatalf("output should contain %s, got: %s", p, output)
+			}
+			if idx < last {
+				t.Errorf("%s printed out of manifest order, got: %s", p, output)
+			}
+			last = idx
+		}
+	})
+}
+
+func TestStatusSelectsPathsAndGroups(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	cloneBranch = ""
+	for _, p := range []string{"apps/web", "apps/api", "packages/lib"} {
+		runClone(cloneCmd, []string{setupRemoteRepo(t), p})
+	}
+
+	m, _ := manifest.Load(dir)
+	m.Find("apps/api").Groups = []string{"backend"}
+	m.Find("packages/lib").Groups = []string{"backend", "shared"}
+	manifest.Save(dir, m)
+
+	t.Run("multiple paths and globs", func(t *testing.T) {
+		output := captureOutput(func() {
+			if err := runStatus(statusCmd, []string{"apps/web", "packages/*"}); err != nil {
+				t.Fatalf("runStatus failed: %v", err)
+			}
+		})
+		if !strings.Contains(output, "apps/web (") || !strings.Contains(output, "packages/lib (") {
+			t.Errorf("selected workspaces should be shown, got: %s", output)
+		}
+		if strings.Contains(output, "apps/api (") {
+			t.Errorf("apps/api should not be shown, got: %s", output)
+		}
+	})
+
+	t.Run("group", func(t *testing.T) {
+		statusGroups = []string{"backend"}
+		defer func() { statusGroups = nil }()
+
+		output := captureOutput(func() {
+			if err := runStatus(statusCmd, []string{}); err != nil {
+				t.Fatalf("runStatus failed: %v", err)
+			}
+		})
+		if !strings.Contains(output, "apps/api (") || !strings.Contains(output, "packages/lib (") {
+			t.Errorf("backend workspaces should be shown, got: %s", output)
+		}
+		if strings.Contains(output, "apps/web (") {
+			t.Errorf("apps/web should not be shown, got: %s", output)
+		}
+	})
+
+	t.Run("unknown group", func(t *testing.T) {
+		statusGroups = []string{"infra"}
+		defer func() { statusGroups = nil }()
+
+		if err := runStatus(statusCmd, []string{}); err == nil {
+			t.Error("should error when group has no workspaces")
+		}
+	})
+}
+
+func TestSyncParallelClonesMissingWorkspaces(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	m := &manifest.Manifest{}
+	m.Add("packages/a", setupRemoteRepo(t))
+	m.Add("packages/b", setupRemoteRepo(t))
+	manifest.Save(dir, m)
+
+	t.Run("sync clones all workspaces concurrently", func(t *testing.T) {
+		oldJobs := syncJobs
+		syncJobs = 2
+		defer func() { syncJobs = oldJobs }()
+
+		output := captureOutput(func() {
+			if err := runSync(syncCmd, []string{}); err != nil {
+				t.Fatalf("runSync failed: %v", err)
+			}
+		})
+
+		for _, p := range []string{"packages/a", "packages/b"} {
+			if _, err := os.Stat(filepath.Join(dir, p, ".git")); err != nil {
+				t.Errorf("%s should be cloned", p)
+			}
+			if !hasGitignoreEntry(dir, p) {
+				t.Errorf(".gitignore should contain %s/.git/", p)
+			}
+		}
+		if strings.Index(output, "packages/a") > strings.Index(output, "packages/b") {
+			t.Errorf("output should be in manifest order, got: %s", output)
+		}
+	})
+}
+
 func TestSyncWithExistingWorkspace(t *testing.T) {
 	_, cleanup := setupTestEnv(t)
 	defer cleanup()
@@ -2263,7 +2385,6 @@ func TestExecuteWithSubprocess(t *testing.T) {
 			"TEST_EXECUTE_SUBPROCESS=1",
 			"TEST_DIR="+dir,
 		)
-		cmd.Args = append(cmd.Args)
 
 		output, err := cmd.CombinedOutput()
 		if err != nil {
@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yejune/git-multirepo/internal/common"
+	"github.com/yejune/git-multirepo/internal/git"
+	"github.com/yejune/git-multirepo/internal/interactive"
+	"github.com/yejune/git-multirepo/internal/manifest"
+)
+
+var (
+	commitMessage     string
+	commitGroups      []string
+	commitInteractive bool
+	commitUntracked   bool
+	commitParent      bool
+)
+
+// selectCommitFiles asks which files to commit (replaced in tests)
+var selectCommitFiles = interactive.SelectFiles
+
+var commitCmd = &cobra.Command{
+	Use:   "commit -m <message> [path...]",
+	Short: "Commit changes in several repositories with one message",
+	Long: `Stage and commit changes in all or the selected repositories with the same message.
+
+Modified and deleted tracked files are committed; new files only with
+--include-untracked or when picked with --interactive. Keep files protected by
+skip-worktree are never committed. Repositories without changes are skipped.
+
+With --parent, the parent repository then commits the same files under each
+workspace path, with a message listing the workspace commits.
+
+Examples:
+  git multirepo commit -m "Bump API version"                 # All repositories
+  git multirepo commit -m "Bump API version" apps/api apps/web
+  git multirepo commit -m "Add feature" -g backend -u        # Include new files
+  git multirepo commit -m "Fix" -i                           # Pick files per repository
+  git multirepo commit -m "Bump API version" --parent        # Also commit parent repo`,
+	RunE: runCommit,
+}
+
+func init() {
+	commitCmd.Flags().StringVarP(&commitMessage, "message", "m", "", "Commit message (required)")
+	commitCmd.Flags().BoolVarP(&commitInteractive, "interactive", "i", false, "Select the files to commit in each repository")
+	commitCmd.Flags().BoolVarP(&commitUntracked, "include-untracked", "u", false, "Also commit untracked files")
+	commitCmd.Flags().BoolVar(&commitParent, "parent", false, "Commit the same files in the parent repository afterwards")
+	addGroupFlag(commitCmd, &commitGroups)
+	rootCmd.AddCommand(commitCmd)
+}
+
+// workspaceCommit records a commit made in a workspace
+type workspaceCommit struct {
+	Path   string
+	Commit string
+	Files  []string // Relative to the workspace
+}
+
+func runCommit(cmd *cobra.Command, args []string) error {
+	if strings.TrimSpace(commitMessage) == "" {
+		return fmt.Errorf("commit message is required (-m)")
+	}
+
+	ctx, err := common.LoadWorkspaceContext()
+	if err != nil {
+		return err
+	}
+
+	workspaces, err := selectWorkspaces(ctx, args, commitGroups)
+	if err != nil {
+		return err
+	}
+
+	var commits []workspaceCommit
+	failed := 0
+	for _, ws := range workspaces {
+		fullPath := filepath.Join(ctx.RepoRoot, ws.Path)
+		if !git.IsRepo(fullPath) {
+			fmt.Printf("  - %s: not cloned, skipped\n", ws.Path)
+			continue
+		}
+
+		files, err := commitCandidates(fullPath, ws.Keep)
+		if err != nil {
+			fmt.Printf("  ✗ %s: %v\n", ws.Path, err)
+			failed++
+			continue
+		}
+
+		if commitInteractive && len(files) > 0 {
+			fmt.Printf("\n%s:\n", ws.Path)
+			files, err = selectCommitFiles(files)
+			if err != nil {
+				return fmt.Errorf("file selection cancelled: %w", err)
+			}
+		}
+
+		if len(files) == 0 {
+			fmt.Printf("  - %s: nothing to commit\n", ws.Path)
+			continue
+		}
+
+		if err := git.CommitFiles(fullPath, commitMessage, files); err != nil {
+			fmt.Printf("  ✗ %s: %v\n", ws.Path, err)
+			failed++
+			continue
+		}
+
+		commit, _ := git.GetCurrentCommit(fullPath)
+		fmt.Printf("  ✓ %s: %s (%d file(s))\n", ws.Path, shortCommit(commit), len(files))
+		commits = append(commits, workspaceCommit{Path: ws.Path, Commit: commit, Files: files})
+	}
+
+	if commitParent && len(commits) > 0 {
+		if err := commitParentRepo(ctx.RepoRoot, commitMessage, commits); err != nil {
+			fmt.Printf("  ✗ parent: %v\n", err)
+			failed++
+		}
+	}
+
+	if len(commits) == 0 && failed == 0 {
+		fmt.Println("\nNothing to commit.")
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d workspace(s) failed", failed)
+	}
+	return nil
+}
+
+// commitCandidates lists changed files in a workspace, excluding keep files.
+// Untracked files are included when --include-untracked or --interactive is set.
+func commitCandidates(fullPath string, keep []string) ([]string, error) {
+	files, err := git.GetModifiedFiles(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get changes: %w", err)
+	}
+
+	if commitUntracked || commitInteractive {
+		untracked, err := git.GetUntrackedFiles(fullPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get untracked files: %w", err)
+		}
+		files = append(files, untracked...)
+	}
+
+	// Keep files are skip-worktree and normally hidden; never commit them even if exposed
+	isKeep := map[string]bool{}
+	for _, file := range keep {
+		isKeep[file] = true
+	}
+	skipped, _ := git.ListSkipWorktree(fullPath)
+	for _, file := range skipped {
+		isKeep[file] = true
+	}
+
+	var candidates []string
+	for _, file := range files {
+		if file != "" && !isKeep[file] {
+			candidates = append(candidates, file)
+		}
+	}
+	sort.Strings(candidates)
+	return candidates, nil
+}
+
+// commitParentRepo commits the files committed in workspaces in the parent repository
+func commitParentRepo(repoRoot, message string, commits []workspaceCommit) error {
+	var files []string
+	var body strings.Builder
+	body.WriteString(message + "\n\nWorkspace commits:\n")
+	for _, c := range commits {
+		fmt.Fprintf(&body, "- %s: %s\n", c.Path, shortCommit(c.Commit))
+		for _, file := range c.Files {
+			files = append(files, c.Path+"/"+file)
+		}
+	}
+
+	// The manifest may have been updated by workspace post-commit hooks
+	files = append(files, manifest.FileName)
+
+	changed, err := git.HasChangesIn(repoRoot, files)
+	if err != nil {
+		return fmt.Errorf("failed to get changes: %w", err)
+	}
+	if !changed {
+		fmt.Println("  - parent: nothing to commit")
+		return nil
+	}
+
+	// Only pass paths that exist in the index or working tree, or git add fails
+	var existing []string
+	for _, file := range files {
+		if changed, _ := git.HasChangesIn(repoRoot, []string{file}); changed || git.IsTracked(repoRoot, file) {
+			existing = append(existing, file)
+		}
+	}
+
+	if err := git.CommitFiles(repoRoot, strings.TrimRight(body.String(), "\n"), existing); err != nil {
+		return err
+	}
+
+	commit, _ := git.GetCurrentCommit(repoRoot)
+	fmt.Printf("  ✓ parent: %s\n", shortCommit(commit))
+	return nil
+}
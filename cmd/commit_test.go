@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yejune/git-multirepo/internal/interactive"
+)
+
+// resetCommitFlags restores commit flags to their defaults
+func resetCommitFlags() {
+	commitMessage = ""
+	commitGroups = nil
+	commitInteractive = false
+	commitUntracked = false
+	commitParent = false
+	selectCommitFiles = interactive.SelectFiles
+}
+
+// gitOutput runs a git command and returns its trimmed output
+func gitOutput(t *testing.T, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		t.Fatalf("git %v failed: %v", args, err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// trackWorkspacesInParent commits workspace source files in the parent, as on a
+// checkout of the parent made before the workspaces' .git directories were restored
+func trackWorkspacesInParent(t *testing.T, dir string, paths ...string) {
+	t.Helper()
+	for _, p := range paths {
+		os.Rename(filepath.Join(dir, p, ".git"), filepath.Join(dir, p, ".git.tmp"))
+	}
+	exec.Command("git", "-C", dir, "add", "-A").Run()
+	exec.Command("git", "-C", dir, "commit", "-m", "Add workspaces").Run()
+	for _, p := range paths {
+		os.Rename(filepath.Join(dir, p, ".git.tmp"), filepath.Join(dir, p, ".git"))
+	}
+}
+
+func TestRunCommit_WorkspacesAndParent(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetCommitFlags()
+
+	remoteRepo := setupRemoteRepoWithCommits(t)
+	setupWorkspaceWithKeepFile(t, dir, remoteRepo, "packages/ws1")
+	cloneBranch = ""
+	runClone(cloneCmd, []string{setupRemoteRepo(t), "packages/ws2"})
+	runClone(cloneCmd, []string{setupRemoteRepo(t), "packages/ws3"})
+
+	ws1 := filepath.Join(dir, "packages/ws1")
+	ws2 := filepath.Join(dir, "packages/ws2")
+
+	trackWorkspacesInParent(t, dir, "packages/ws1", "packages/ws2", "packages/ws3")
+
+	// Keep file with local changes, protected by skip-worktree
+	os.WriteFile(filepath.Join(ws1, "config.yml"), []byte("local: true\n"), 0644)
+	exec.Command("git", "-C", ws1, "update-index", "--skip-worktree", "config.yml").Run()
+
+	os.WriteFile(filepath.Join(ws1, "README.md"), []byte("# changed 1"), 0644)
+	os.WriteFile(filepath.Join(ws2, "README.md"), []byte("# changed 2"), 0644)
+	os.WriteFile(filepath.Join(ws2, "new.txt"), []byte("new"), 0644)
+
+	commitMessage = "Update readmes"
+	commitParent = true
+	output := captureOutput(func() {
+		if err := runCommit(commitCmd, nil); err != nil {
+			t.Fatalf("commit failed: %v", err)
+		}
+	})
+
+	if !strings.Contains(output, "packages/ws1:") || !strings.Contains(output, "(1 file(s))") {
+		t.Errorf("ws1 should commit one file, got: %s", output)
+	}
+	if !strings.Contains(output, "packages/ws3: nothing to commit") {
+		t.Errorf("clean workspace should be skipped, got: %s", output)
+	}
+
+	if msg := gitOutput(t, "-C", ws1, "log", "-1", "--format=%s"); msg != "Update readmes" {
+		t.Errorf("ws1 commit message = %q", msg)
+	}
+	if files := gitOutput(t, "-C", ws1, "show", "--name-only", "--format=", "HEAD"); files != "README.md" {
+		t.Errorf("ws1 commit should only contain README.md, got %q", files)
+	}
+	if content, _ := os.ReadFile(filepath.Join(ws1, "config.yml")); string(content) != "local: true\n" {
+		t.Error("keep file should keep its local content")
+	}
+
+	// Untracked files are not committed without -u
+	if status := gitOutput(t, "-C", ws2, "status", "--porcelain"); status != "?? new.txt" {
+		t.Errorf("new.txt should stay untracked, got %q", status)
+	}
+
+	// Parent commit lists the workspace commits and only contains their files
+	body := gitOutput(t, "-C", dir, "log", "-1", "--format=%B")
+	if !strings.HasPrefix(body, "Update readmes") || !strings.Contains(body, "- packages/ws1: ") || !strings.Contains(body, "- packages/ws2: ") {
+		t.Errorf("unexpected parent commit message: %s", body)
+	}
+	parentFiles := gitOutput(t, "-C", dir, "show", "--name-only", "--format=", "HEAD")
+	if parentFiles != "packages/ws1/README.md\npackages/ws2/README.md" {
+		t.Errorf("parent commit should contain the workspace files only, got %q", parentFiles)
+	}
+}
+
+func TestRunCommit_InteractiveAndUntracked(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetCommitFlags()
+
+	cloneBranch = ""
+	runClone(cloneCmd, []string{setupRemoteRepo(t), "packages/ws1"})
+	ws1 := filepath.Join(dir, "packages/ws1")
+
+	os.WriteFile(filepath.Join(ws1, "README.md"), []byte("# changed"), 0644)
+	os.WriteFile(filepath.Join(ws1, "new.txt"), []byte("new"), 0644)
+
+	var offered []string
+	selectCommitFiles = func(files []string) ([]string, error) {
+		offered = files
+		return []string{"new.txt"}, nil
+	}
+
+	commitMessage = "Add new file"
+	commitInteractive = true
+	captureOutput(func() {
+		if err := runCommit(commitCmd, []string{"packages/ws1"}); err != nil {
+			t.Fatalf("commit failed: %v", err)
+		}
+	})
+
+	if strings.Join(offered, ",") != "README.md,new.txt" {
+		t.Errorf("interactive selection should offer modified and untracked files, got %v", offered)
+	}
+	if files := gitOutput(t, "-C", ws1, "show", "--name-only", "--format=", "HEAD"); files != "new.txt" {
+		t.Errorf("commit should only contain the selected file, got %q", files)
+	}
+	if status := gitOutput(t, "-C", ws1, "status", "--porcelain"); status != "M README.md" {
+		t.Errorf("unselected file should stay modified, got %q", status)
+	}
+}
+
+func TestRunCommit_RequiresMessage(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetCommitFlags()
+
+	if err := runCommit(commitCmd, nil); err == nil || !strings.Contains(err.Error(), "message is required") {
+		t.Errorf("expected missing message error, got: %v", err)
+	}
+}
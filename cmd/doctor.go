@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/yejune/git-multirepo/internal/common"
+	"github.com/yejune/git-multirepo/internal/discover"
+	"github.com/yejune/git-multirepo/internal/git"
+	"github.com/yejune/git-multirepo/internal/hooks"
+	"github.com/yejune/git-multirepo/internal/manifest"
+)
+
+var doctorFix bool
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the manifest and workspaces for problems",
+	Long: `Check the manifest and workspaces for problems.
+
+Checks:
+  - Manifest: duplicate, absolute or ../ paths, nested workspaces, empty repo URLs
+  - Hooks: parent post-checkout hook and workspace post-commit hooks
+  - Workspaces: cloned, origin URL matches repo, .git/ listed in .gitignore
+  - Keep files: exist, tracked, and marked skip-worktree; no skip-worktree
+    files missing from the keep list
+  - Stray repositories: .git directories not registered in the manifest
+
+With --fix, safe repairs are applied: installing hooks, adding .gitignore
+entries, setting origin to the manifest repo and re-applying skip-worktree.
+Everything else is reported for manual action.
+
+Examples:
+  git multirepo doctor         # Report problems
+  git multirepo doctor --fix   # Report and repair what is safe to repair`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Apply safe repairs")
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// doctorReport prints check results and applies fixes when --fix is set
+type doctorReport struct {
+	fix      bool
+	problems int // Problems left unresolved
+	fixed    int
+}
+
+// problem reports an issue. fix is nil if the issue can't be repaired automatically.
+func (r *doctorReport) problem(fix func() error, format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+
+	if fix == nil {
+		fmt.Printf("  ✗ %s\n", msg)
+		r.problems++
+		return
+	}
+
+	if !r.fix {
+		fmt.Printf("  ✗ %s (fixable with --fix)\n", msg)
+		r.problems++
+		return
+	}
+
+	if err := fix(); err != nil {
+		fmt.Printf("  ✗ %s (fix failed: %v)\n", msg, err)
+		r.problems++
+		return
+	}
+	fmt.Printf("  ✓ Fixed: %s\n", msg)
+	r.fixed++
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	ctx, err := common.LoadWorkspaceContext()
+	if err != nil {
+		return err
+	}
+
+	r := &doctorReport{fix: doctorFix}
+	if doctorFix {
+		entry := beginJournal(ctx.RepoRoot, "doctor --fix", ctx.Manifest)
+		defer entry.Finish()
+	}
+
+	fmt.Println("Manifest")
+	problems := ctx.Manifest.Validate()
+	for _, p := range problems {
+		r.problem(nil, "%s", p.Error())
+	}
+	if len(problems) == 0 {
+		fmt.Printf("  ✓ %d workspace(s), no problems\n", len(ctx.Manifest.Workspaces))
+	}
+
+	fmt.Println("\nParent repository")
+	before := r.problems + r.fixed
+	if !hooks.IsInstalled(ctx.RepoRoot) {
+		r.problem(func() error { return hooks.Install(ctx.RepoRoot) }, "post-checkout hook not installed")
+	}
+	checkKeepFiles(r, ctx.RepoRoot, ctx.RepoRoot, ctx.Manifest.Keep)
+	if r.problems+r.fixed == before {
+		fmt.Println("  ✓ OK")
+	}
+
+	for i := range ctx.Manifest.Workspaces {
+		ws := &ctx.Manifest.Workspaces[i]
+		if !filepath.IsLocal(ws.Path) {
+			continue // Reported by manifest validation; don't touch paths outside the repo
+		}
+
+		fmt.Printf("\n%s\n", ws.Path)
+		before := r.problems + r.fixed
+		checkWorkspace(r, ctx, ws)
+		if r.problems+r.fixed == before {
+			fmt.Println("  ✓ OK")
+		}
+	}
+
+	fmt.Println("\nStray repositories")
+	stray := findStrayRepos(ctx)
+	for _, path := range stray {
+		r.problem(nil, "%s has a .git directory but is not in %s (register it with 'git multirepo add %s')", path, manifest.FileName, path)
+	}
+	if len(stray) == 0 {
+		fmt.Println("  ✓ None")
+	}
+
+	fmt.Println()
+	if r.fixed > 0 {
+		fmt.Printf("Fixed %d problem(s)\n", r.fixed)
+	}
+	if r.problems > 0 {
+		return fmt.Errorf("%d problem(s) found", r.problems)
+	}
+	fmt.Println("✓ No problems found")
+	return nil
+}
+
+// checkWorkspace checks a cloned workspace against its manifest entry
+func checkWorkspace(r *doctorReport, ctx *common.WorkspaceContext, ws *manifest.WorkspaceEntry) {
+	repoRoot := ctx.RepoRoot
+	fullPath := filepath.Join(repoRoot, ws.Path)
+
+	if _, err := os.Stat(fullPath); os.IsNotExist(err) {
+		r.problem(nil, "not cloned (run 'git multirepo sync')")
+		return
+	}
+	if !git.IsRepo(fullPath) {
+		r.problem(nil, "directory exists but has no .git (run 'git multirepo sync')")
+		return
+	}
+
+	if origin, drifted := originDrift(ctx.Manifest, ws, fullPath); drifted {
+		expected := expectedOrigin(ctx.Manifest, ws)
+		if origin == "" {
+			r.problem(func() error { return git.SetRemoteURL(fullPath, expected) }, "no origin remote (expected %s)", expected)
+		} else {
+			r.problem(func() error { return git.SetRemoteURL(fullPath, expected) }, "origin is %s, manifest repo is %s", origin, expected)
+		}
+	}
+
+	if !hasGitignoreEntry(repoRoot, ws.Path) {
+		r.problem(func() error { return git.AddToGitignore(repoRoot, ws.Path) }, "%s/.git/ not in .gitignore", ws.Path)
+	}
+
+	if !hooks.IsWorkspaceHookInstalled(fullPath) {
+		r.problem(func() error { return hooks.InstallWorkspaceHook(fullPath) }, "post-commit hook not installed")
+	}
+
+	checkKeepFiles(r, repoRoot, fullPath, ws.Keep)
+}
+
+// checkKeepFiles compares the keep list of a repository with its skip-worktree state
+func checkKeepFiles(r *doctorReport, repoRoot, repoPath string, keep []string) {
+	skipped, err := git.ListSkipWorktree(repoPath)
+	if err != nil {
+		r.problem(nil, "failed to list skip-worktree files: %v", err)
+		return
+	}
+	isSkipped := map[string]bool{}
+	for _, file := range skipped {
+		isSkipped[file] = true
+	}
+
+	isKeep := map[string]bool{}
+	for _, file := range keep {
+		isKeep[file] = true
+		if !filepath.IsLocal(file) {
+			continue // Reported by manifest validation
+		}
+
+		if _, err := os.Stat(filepath.Join(repoPath, file)); os.IsNotExist(err) {
+			r.problem(nil, "keep file %s does not exist", file)
+			continue
+		}
+		if !git.IsTracked(repoPath, file) {
+			r.problem(nil, "keep file %s is not tracked by git (remove it from keep or commit it)", file)
+			continue
+		}
+		if !isSkipped[file] {
+			file := file
+			r.problem(func() error { return git.ApplySkipWorktree(repoPath, []string{file}) }, "keep file %s is not marked skip-worktree", file)
+		}
+	}
+
+	for _, file := range skipped {
+		if !isKeep[file] {
+			r.problem(nil, "%s is skip-worktree but not in the keep list (add it to keep or run 'git update-index --no-skip-worktree %s')", file, file)
+		}
+	}
+}
+
+// findStrayRepos returns paths of nested git repositories not in the manifest,
+// looked for as sync --discover does
+func findStrayRepos(ctx *common.WorkspaceContext) []string {
+	var stray []string
+	repos, _ := discover.Scan(ctx.RepoRoot, discoverOptions(ctx, common.DefaultJobs()))
+	for _, repo := range repos {
+		if repo.Kind == discover.KindRepo {
+			stray = append(stray, repo.Path)
+		}
+	}
+	return stray
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yejune/git-multirepo/internal/git"
+	"github.com/yejune/git-multirepo/internal/hooks"
+	"github.com/yejune/git-multirepo/internal/manifest"
+)
+
+func TestDoctor_ReportAndFix(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer func() { doctorFix = false }()
+
+	remote := setupRemoteRepo(t)
+	cloneBranch = ""
+	runClone(cloneCmd, []string{remote, "packages/app"})
+	wsPath := filepath.Join(dir, "packages/app")
+
+	m, _ := manifest.Load(dir)
+	m.Workspaces[0].Keep = []string{"README.md", "missing.txt"}
+	manifest.Save(dir, m)
+
+	// Break what doctor can repair
+	exec.Command("git", "-C", wsPath, "remote", "set-url", "origin", "https://example.com/other.git").Run()
+	os.Remove(filepath.Join(wsPath, ".git", "hooks", "post-commit"))
+	os.WriteFile(filepath.Join(dir, ".gitignore"), []byte(""), 0644)
+
+	// And what it can't
+	os.MkdirAll(filepath.Join(dir, "tools/other"), 0755)
+	exec.Command("git", "-C", filepath.Join(dir, "tools/other"), "init").Run()
+
+	var err error
+	output := captureOutput(func() { err = runDoctor(doctorCmd, nil) })
+	if err == nil {
+		t.Fatal("doctor should fail when problems are found")
+	}
+	for _, want := range []string{
+		"post-checkout hook not installed (fixable with --fix)",
+		"origin is https://example.com/other.git, manifest repo is " + remote,
+		"packages/app/.git/ not in .gitignore",
+		"post-commit hook not installed",
+		"keep file README.md is not marked skip-worktree",
+		"keep file missing.txt does not exist",
+		"tools/other has a .git directory but is not in .git.multirepos",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output should contain %q, got:\n%s", want, output)
+		}
+	}
+
+	doctorFix = true
+	output = captureOutput(func() { err = runDoctor(doctorCmd, nil) })
+	if err == nil || !strings.Contains(err.Error(), "2 problem(s)") {
+		t.Errorf("only unfixable problems should remain, got: %v\n%s", err, output)
+	}
+	if !strings.Contains(output, "Fixed 5 problem(s)") {
+		t.Errorf("expected 5 fixes, got:\n%s", output)
+	}
+
+	if !hooks.IsInstalled(dir) || !hooks.IsWorkspaceHookInstalled(wsPath) {
+		t.Error("hooks should be installed")
+	}
+	if origin, _ := git.GetRemoteURL(wsPath); origin != remote {
+		t.Errorf("origin should be reset to %s, got %s", remote, origin)
+	}
+	if !hasGitignoreEntry(dir, "packages/app") {
+		t.Error(".gitignore entry should be restored")
+	}
+	if skipped, _ := git.ListSkipWorktree(wsPath); len(skipped) != 1 || skipped[0] != "README.md" {
+		t.Errorf("README.md should be skip-worktree, got %v", skipped)
+	}
+}
+
+func TestDoctor_Healthy(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	cloneBranch = ""
+	runClone(cloneCmd, []string{setupRemoteRepo(t), "packages/app"})
+	hooks.Install(dir)
+
+	var err error
+	output := captureOutput(func() { err = runDoctor(doctorCmd, nil) })
+	if err != nil {
+		t.Fatalf("doctor failed on a healthy repo: %v\n%s", err, output)
+	}
+	if !strings.Contains(output, "No problems found") {
+		t.Errorf("expected success message, got:\n%s", output)
+	}
+}
+
+func TestDoctor_InvalidManifest(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	manifest.Save(dir, &manifest.Manifest{Workspaces: []manifest.WorkspaceEntry{
+		{Path: "../outside", Repo: "https://github.com/test/a.git"},
+		{Path: "apps/api"},
+	}})
+
+	var err error
+	output := captureOutput(func() { err = runDoctor(doctorCmd, nil) })
+	if err == nil {
+		t.Fatal("doctor should fail on an invalid manifest")
+	}
+	if !strings.Contains(output, "../outside: path must not contain ..") || !strings.Contains(output, "apps/api: repo is empty") {
+		t.Errorf("expected validation errors, got:\n%s", output)
+	}
+	if strings.Contains(output, "\n../outside\n") {
+		t.Error("workspaces outside the repository should not be inspected")
+	}
+}
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+
+	"github.com/spf13/cobra"
+	"github.com/yejune/git-multirepo/internal/common"
+	"github.com/yejune/git-multirepo/internal/git"
+	"github.com/yejune/git-multirepo/internal/i18n"
+	"github.com/yejune/git-multirepo/internal/manifest"
+)
+
+var (
+	foreachParallel        bool
+	foreachJobs            int
+	foreachContinueOnError bool
+	foreachPaths           []string
+	foreachGroups          []string
+)
+
+var foreachCmd = &cobra.Command{
+	Use:   "foreach [flags] -- <command>",
+	Short: "Run a shell command in every workspace",
+	Long: `Run a command in each workspace directory.
+
+A single argument runs through the shell (sh -c, or cmd /C on Windows), so it
+can use pipes and variables; several arguments run as the program args[0] with
+the other arguments passed as they are, like 'git submodule foreach'.
+The command runs with these environment variables set:
+  MULTIREPO_ROOT     Absolute path of the parent repository
+  MULTIREPO_PATH     Workspace path relative to the parent repository
+  MULTIREPO_REPO     Repository URL from .git.multirepos
+  MULTIREPO_BRANCH   Current branch of the workspace
+
+Workspaces that are not cloned are skipped. By default, execution stops at the
+first failing workspace; use --continue-on-error to run all of them.
+With --parallel, output of each workspace is buffered and printed in manifest order.
+
+Examples:
+  git multirepo foreach -- git log -1 --oneline
+  git multirepo foreach --parallel -- go test ./...
+  git multirepo foreach --path 'packages/*' -- 'echo $MULTIREPO_BRANCH'
+  git multirepo foreach -g backend -- make build
+  git multirepo foreach --continue-on-error -- make lint`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runForeach,
+}
+
+func init() {
+	foreachCmd.Flags().BoolVarP(&foreachParallel, "parallel", "p", false, "Run in workspaces concurrently")
+	foreachCmd.Flags().IntVarP(&foreachJobs, "jobs", "j", common.DefaultJobs(), "Number of concurrent workspaces with --parallel")
+	foreachCmd.Flags().BoolVar(&foreachContinueOnError, "continue-on-error", false, "Keep going after a workspace fails")
+	foreachCmd.Flags().StringSliceVar(&foreachPaths, "path", nil, "Only run in workspaces matching path or glob (repeatable)")
+	addGroupFlag(foreachCmd, &foreachGroups)
+	rootCmd.AddCommand(foreachCmd)
+}
+
+// foreachResult records the outcome of the command in one workspace
+type foreachResult struct {
+	path     string
+	exitCode int
+	skipped  string // Reason the workspace was skipped, empty if it ran
+	err      error
+}
+
+func runForeach(cmd *cobra.Command, args []string) error {
+	ctx, err := common.LoadWorkspaceContext()
+	if err != nil {
+		return err
+	}
+
+	if len(ctx.Manifest.Workspaces) == 0 {
+		fmt.Println(i18n.T("no_subs_registered"))
+		return nil
+	}
+
+	workspaces, err := selectWorkspaces(ctx, foreachPaths, foreachGroups)
+	if err != nil {
+		return err
+	}
+
+	results := make([]foreachResult, len(workspaces))
+	index := make(map[string]int, len(workspaces))
+	for i, ws := range workspaces {
+		index[ws.Path] = i
+	}
+
+	var failed atomic.Bool
+	run := func(ws *manifest.WorkspaceEntry, fullPath string, out io.Writer) error {
+		r := &results[index[ws.Path]]
+		r.path = ws.Path
+
+		fmt.Fprintf(out, "\n▶ %s\n", ws.Path)
+
+		if !git.IsRepo(fullPath) {
+			r.skipped = "not cloned"
+			fmt.Fprintf(out, "  ○ not cloned, skipped\n")
+			return nil
+		}
+		if failed.Load() && !foreachContinueOnError {
+			r.skipped = "skipped"
+			fmt.Fprintf(out, "  ○ skipped after earlier failure\n")
+			return nil
+		}
+
+		r.exitCode, r.err = runInWorkspace(ctx.RepoRoot, ws, fullPath, args, out)
+		if r.err != nil {
+			failed.Store(true)
+			fmt.Fprintf(out, "  ✗ %v\n", r.err)
+		}
+		return r.err
+	}
+
+	if foreachParallel {
+		ctx.RunWorkspacesParallel(workspaces, foreachJobs, os.Stdout, run)
+	} else {
+		for i := range workspaces {
+			ws := &workspaces[i]
+			fullPath := filepath.Join(ctx.RepoRoot, ws.Path)
+			if err := run(ws, fullPath, os.Stdout); err != nil && !foreachContinueOnError {
+				// Mark the rest as not run so they show up in the summary
+				for j := i + 1; j < len(workspaces); j++ {
+					results[j] = foreachResult{path: workspaces[j].Path, skipped: "skipped"}
+				}
+				break
+			}
+		}
+	}
+
+	return printForeachSummary(results)
+}
+
+// runInWorkspace runs command in a workspace directory: a single argument
+// through the shell, several as a program and its arguments.
+// Returns the exit code of the command and an error if it did not succeed
+func runInWorkspace(repoRoot string, ws *manifest.WorkspaceEntry, fullPath string, command []string, out io.Writer) (int, error) {
+	branch, err := git.GetCurrentBranch(fullPath)
+	if err != nil {
+		branch = ws.Branch
+	}
+
+	var c *exec.Cmd
+	switch {
+	case len(command) > 1:
+		c = exec.Command(command[0], command[1:]...)
+	case runtime.GOOS == "windows":
+		c = exec.Command("cmd", "/C", command[0])
+	default:
+		c = exec.Command("sh", "-c", command[0])
+	}
+	c.Dir = fullPath
+	c.Stdout = out
+	c.Stderr = out
+	c.Env = append(os.Environ(),
+		"MULTIREPO_ROOT="+repoRoot,
+		"MULTIREPO_PATH="+ws.Path,
+		"MULTIREPO_REPO="+ws.Repo,
+		"MULTIREPO_BRANCH="+branch,
+	)
+
+	if err := c.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode(), fmt.Errorf("exit status %d", exitErr.ExitCode())
+		}
+		return -1, err
+	}
+	return 0, nil
+}
+
+// printForeachSummary prints one line per workspace and returns an error if any failed
+func printForeachSummary(results []foreachResult) error {
+	fmt.Println("\nSummary:")
+
+	failures := 0
+	for _, r := range results {
+		switch {
+		case r.path == "":
+			continue
+		case r.skipped != "":
+			fmt.Printf("  ○ %s (%s)\n", r.path, r.skipped)
+		case r.err != nil:
+			failures++
+			fmt.Printf("  ✗ %s (exit %d)\n", r.path, r.exitCode)
+		default:
+			fmt.Printf("  ✓ %s\n", r.path)
+		}
+	}
+
+	if failures > 0 {
+		return fmt.Errorf("command failed in %d workspace(s)", failures)
+	}
+	return nil
+}
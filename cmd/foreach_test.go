@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yejune/git-multirepo/internal/manifest"
+)
+
+// resetForeachFlags restores foreach flags to their defaults
+func resetForeachFlags() {
+	foreachParallel = false
+	foreachJobs = 0
+	foreachContinueOnError = false
+	foreachPaths = nil
+	foreachGroups = nil
+}
+
+func TestRunForeach_ExposesEnvironment(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetForeachFlags()
+
+	cloneBranch = ""
+	runClone(cloneCmd, []string{setupRemoteRepo(t), "packages/env"})
+
+	output := captureOutput(func() {
+		if err := runForeach(foreachCmd, []string{`echo "$MULTIREPO_PATH@$MULTIREPO_BRANCH"`}); err != nil {
+			t.Fatalf("runForeach failed: %v", err)
+		}
+	})
+
+	if !strings.Contains(output, "packages/env@main") {
+		t.Errorf("output should contain path and branch from env, got: %s", output)
+	}
+	if !strings.Contains(output, "✓ packages/env") {
+		t.Errorf("summary should report success, got: %s", output)
+	}
+}
+
+func TestRunForeach_RunsInWorkspaceDirectory(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetForeachFlags()
+
+	cloneBranch = ""
+	runClone(cloneCmd, []string{setupRemoteRepo(t), "packages/cwd"})
+
+	output := captureOutput(func() {
+		runForeach(foreachCmd, []string{"cat", "README.md"})
+	})
+
+	if !strings.Contains(output, "# Remote Repo") {
+		t.Errorf("command should run inside the workspace, got: %s", output)
+	}
+}
+
+func TestRunForeach_KeepsArgumentBoundaries(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetForeachFlags()
+
+	cloneBranch = ""
+	runClone(cloneCmd, []string{setupRemoteRepo(t), "packages/args"})
+
+	// Several arguments are passed as they are, not re-split by the shell
+	output := captureOutput(func() {
+		if err := runForeach(foreachCmd, []string{"grep", "-c", "Remote Repo", "README.md"}); err != nil {
+			t.Fatalf("runForeach failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "\n1\n") {
+		t.Errorf("argument with a space should reach grep intact, got: %s", output)
+	}
+
+	output = captureOutput(func() {
+		runForeach(foreachCmd, []string{"echo", "$MULTIREPO_PATH", "a;b"})
+	})
+	if !strings.Contains(output, "$MULTIREPO_PATH a;b") {
+		t.Errorf("arguments should not be interpreted by a shell, got: %s", output)
+	}
+}
+
+func TestRunForeach_StopsOnFirstFailure(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetForeachFlags()
+
+	cloneBranch = ""
+	runClone(cloneCmd, []string{setupRemoteRepo(t), "packages/a"})
+	runClone(cloneCmd, []string{setupRemoteRepo(t), "packages/b"})
+
+	t.Run("stops by default", func(t *testing.T) {
+		var err error
+		output := captureOutput(func() {
+			err = runForeach(foreachCmd, []string{"exit 3"})
+		})
+		if err == nil {
+			t.Fatal("should return error when command fails")
+		}
+		if !strings.Contains(output, "✗ packages/a (exit 3)") {
+			t.Errorf("summary should show exit code, got: %s", output)
+		}
+		if !strings.Contains(output, "○ packages/b (skipped)") {
+			t.Errorf("second workspace should be skipped, got: %s", output)
+		}
+	})
+
+	t.Run("continue on error runs all", func(t *testing.T) {
+		foreachContinueOnError = true
+		defer func() { foreachContinueOnError = false }()
+
+		var err error
+		output := captureOutput(func() {
+			err = runForeach(foreachCmd, []string{"exit 1"})
+		})
+		if err == nil || !strings.Contains(err.Error(), "2 workspace(s)") {
+			t.Errorf("expected failure in 2 workspaces, got: %v", err)
+		}
+		if !strings.Contains(output, "✗ packages/b (exit 1)") {
+			t.Errorf("second workspace should run, got: %s", output)
+		}
+	})
+}
+
+func TestRunForeach_ParallelKeepsOrder(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetForeachFlags()
+
+	cloneBranch = ""
+	for _, p := range []string{"packages/one", "packages/two", "packages/three"} {
+		runClone(cloneCmd, []string{setupRemoteRepo(t), p})
+	}
+
+	foreachParallel = true
+	foreachJobs = 3
+
+	output := captureOutput(func() {
+		if err := runForeach(foreachCmd, []string{`echo "out:$MULTIREPO_PATH"`}); err != nil {
+			t.Fatalf("runForeach failed: %v", err)
+		}
+	})
+
+	one := strings.Index(output, "out:packages/one")
+	two := strings.Index(output, "out:packages/two")
+	three := strings.Index(output, "out:packages/three")
+	if one < 0 || two < 0 || three < 0 || !(one < two && two < three) {
+		t.Errorf("output should be in manifest order, got: %s", output)
+	}
+}
+
+func TestRunForeach_PathFilter(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetForeachFlags()
+
+	cloneBranch = ""
+	runClone(cloneCmd, []string{setupRemoteRepo(t), "apps/web"})
+	runClone(cloneCmd, []string{setupRemoteRepo(t), "packages/lib"})
+
+	foreachPaths = []string{"packages/*"}
+
+	output := captureOutput(func() {
+		runForeach(foreachCmd, []string{"true"})
+	})
+
+	if strings.Contains(output, "apps/web") {
+		t.Errorf("apps/web should be filtered out, got: %s", output)
+	}
+	if !strings.Contains(output, "packages/lib") {
+		t.Errorf("packages/lib should be included, got: %s", output)
+	}
+
+	foreachPaths = []string{"nothing/*"}
+	if err := runForeach(foreachCmd, []string{"true"}); err == nil {
+		t.Error("should error when no workspace matches")
+	}
+}
+
+func TestRunForeach_GroupFilter(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetForeachFlags()
+
+	cloneBranch = ""
+	runClone(cloneCmd, []string{setupRemoteRepo(t), "apps/web"})
+	runClone(cloneCmd, []string{setupRemoteRepo(t), "apps/api"})
+
+	m, _ := manifest.Load(dir)
+	m.Find("apps/api").Groups = []string{"backend"}
+	manifest.Save(dir, m)
+
+	foreachGroups = []string{"backend"}
+
+	output := captureOutput(func() {
+		if err := runForeach(foreachCmd, []string{`echo "in:$MULTIREPO_PATH"`}); err != nil {
+			t.Fatalf("runForeach failed: %v", err)
+		}
+	})
+
+	if !strings.Contains(output, "in:apps/api") {
+		t.Errorf("apps/api should run, got: %s", output)
+	}
+	if strings.Contains(output, "in:apps/web") {
+		t.Errorf("apps/web should be filtered out, got: %s", output)
+	}
+}
+
+func TestRunForeach_SkipsNotCloned(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetForeachFlags()
+
+	m := &manifest.Manifest{}
+	m.Add("packages/missing", "https://example.com/missing.git")
+	manifest.Save(dir, m)
+
+	output := captureOutput(func() {
+		if err := runForeach(foreachCmd, []string{"true"}); err != nil {
+			t.Errorf("not cloned workspaces should not fail: %v", err)
+		}
+	})
+
+	if !strings.Contains(output, "○ packages/missing (not cloned)") {
+		t.Errorf("summary should show not cloned, got: %s", output)
+	}
+}
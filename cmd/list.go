@@ -10,7 +10,10 @@ import (
 	"github.com/yejune/git-multirepo/internal/manifest"
 )
 
-var listRecursive bool
+var (
+	listRecursive bool
+	listOutput    string
+)
 
 var listCmd = &cobra.Command{
 	Use:     "list",
@@ -23,24 +26,87 @@ Shows path, repository URL, branch, and current status.
 Examples:
   git multirepo list
   git multirepo ls
-  git multirepo ls -r`,
+  git multirepo ls -r
+  git multirepo ls -o json`,
 	RunE: runList,
 }
 
 func init() {
 	listCmd.Flags().BoolVarP(&listRecursive, "recursive", "r", false, "Recursively list workspaces within workspaces")
+	listCmd.Flags().StringVarP(&listOutput, "output", "o", outputText, "Output format: text, json or yaml")
 	rootCmd.AddCommand(listCmd)
 }
 
 func runList(cmd *cobra.Command, args []string) error {
+	if err := validateOutputFormat(listOutput); err != nil {
+		return err
+	}
+
 	repoRoot, err := git.GetRepoRoot()
 	if err != nil {
 		return fmt.Errorf("not in a git repository: %w", err)
 	}
 
+	if listOutput != outputText {
+		reports, err := collectListReports(repoRoot, listRecursive)
+		if err != nil {
+			return err
+		}
+		return writeStructured(os.Stdout, listOutput, workspacesReport{Workspaces: reports})
+	}
+
 	return listDir(repoRoot, listRecursive, 0)
 }
 
+// workspaceState returns clean, modified, not_cloned or error for a workspace
+func workspaceState(fullPath string) string {
+	if !git.IsRepo(fullPath) {
+		return "not_cloned"
+	}
+	hasChanges, err := git.HasChanges(fullPath)
+	if err != nil {
+		return "error"
+	}
+	if hasChanges {
+		return "modified"
+	}
+	return "clean"
+}
+
+// collectListReports builds list reports for the manifest in dir,
+// descending into nested manifests when recursive is set
+func collectListReports(dir string, recursive bool) ([]workspaceReport, error) {
+	m, err := manifest.Load(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load manifest: %w", err)
+	}
+
+	reports := []workspaceReport{}
+	for _, ws := range m.Workspaces {
+		fullPath := filepath.Join(dir, ws.Path)
+
+		report := newWorkspaceReport(&ws)
+		report.State = workspaceState(fullPath)
+		report.Cloned = report.State != "not_cloned"
+
+		if recursive {
+			subManifest := filepath.Join(fullPath, manifest.FileName)
+			if _, err := os.Stat(subManifest); err == nil {
+				nested, err := collectListReports(fullPath, recursive)
+				if err != nil {
+					report.Error = err.Error()
+				} else {
+					report.Workspaces = nested
+				}
+			}
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
 func listDir(dir string, recursive bool, depth int) error {
 	indent := ""
 	for i := 0; i < depth; i++ {
@@ -62,28 +128,13 @@ func listDir(dir string, recursive bool, depth int) error {
 	for _, ws := range m.Workspaces {
 		fullPath := filepath.Join(dir, ws.Path)
 
-		// Check status
-		var status string
-		if !git.IsRepo(fullPath) {
-			status = "not cloned"
-		} else {
-			hasChanges, err := git.HasChanges(fullPath)
-			if err != nil {
-				status = "error"
-			} else if hasChanges {
-				status = "modified"
-			} else {
-				status = "clean"
-			}
-		}
-
 		// Format output
 		statusIcon := map[string]string{
 			"clean":      "✓",
 			"modified":   "●",
-			"not cloned": "○",
+			"not_cloned": "○",
 			"error":      "✗",
-		}[status]
+		}[workspaceState(fullPath)]
 
 		fmt.Printf("%s%s %s\n", indent, statusIcon, ws.Path)
 		fmt.Printf("%s  └─ %s\n", indent, ws.Repo)
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/yejune/git-multirepo/internal/common"
+	"github.com/yejune/git-multirepo/internal/git"
+	"github.com/yejune/git-multirepo/internal/i18n"
+	"github.com/yejune/git-multirepo/internal/manifest"
+)
+
+var lockCmd = &cobra.Command{
+	Use:   "lock [path...]",
+	Short: "Record current workspace commits in .git.multirepos.lock",
+	Long: `Record the current HEAD commit of each workspace in .git.multirepos.lock.
+
+Commit the lock file to the parent repository. On a fresh checkout,
+'git multirepo sync' clones each workspace and checks out the locked commit,
+and 'git multirepo sync --locked' fails if any workspace has drifted.
+
+Examples:
+  git multirepo lock                # Lock all workspaces
+  git multirepo lock packages/lib   # Update the lock for one workspace`,
+	RunE: runLock,
+}
+
+var lockGroups []string
+
+func init() {
+	addGroupFlag(lockCmd, &lockGroups)
+	rootCmd.AddCommand(lockCmd)
+}
+
+func runLock(cmd *cobra.Command, args []string) error {
+	ctx, err := common.LoadWorkspaceContext()
+	if err != nil {
+		return err
+	}
+
+	if len(ctx.Manifest.Workspaces) == 0 {
+		fmt.Println(i18n.T("no_subs_registered"))
+		return nil
+	}
+
+	workspacesToLock, err := selectWorkspaces(ctx, args, lockGroups)
+	if err != nil {
+		return err
+	}
+
+	entry := beginJournal(ctx.RepoRoot, "lock", ctx.Manifest)
+	defer entry.Finish()
+
+	lock, err := manifest.LoadLock(ctx.RepoRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load lock file: %w", err)
+	}
+
+	locked := 0
+	for _, ws := range workspacesToLock {
+		fullPath := filepath.Join(ctx.RepoRoot, ws.Path)
+
+		if !git.IsRepo(fullPath) {
+			fmt.Printf("○ %s: not cloned, skipped\n", ws.Path)
+			continue
+		}
+
+		commit, err := git.GetCurrentCommit(fullPath)
+		if err != nil {
+			fmt.Printf("✗ %s: failed to get commit: %v\n", ws.Path, err)
+			continue
+		}
+
+		// Tag is informational only; sync always checks out the commit
+		tag, _ := git.GetExactTag(fullPath)
+
+		lock.Set(manifest.LockEntry{
+			Path:   ws.Path,
+			Commit: commit,
+			Tag:    tag,
+		})
+		locked++
+
+		if tag != "" {
+			fmt.Printf("✓ %s: %s (%s)\n", ws.Path, shortCommit(commit), tag)
+		} else {
+			fmt.Printf("✓ %s: %s\n", ws.Path, shortCommit(commit))
+		}
+
+		// A locked commit that only exists locally cannot be reproduced elsewhere
+		if unpushed, err := git.HasUnpushedCommits(fullPath); err == nil && unpushed {
+			fmt.Printf("  ⚠ Has unpushed commits. Push first: cd %s && git push\n", ws.Path)
+		}
+	}
+
+	lock.SortByManifest(ctx.Manifest)
+	if err := manifest.SaveLock(ctx.RepoRoot, lock); err != nil {
+		return fmt.Errorf("failed to save lock file: %w", err)
+	}
+
+	fmt.Printf("\n✓ Locked %d workspace(s) in %s\n", locked, manifest.LockFileName)
+	return nil
+}
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yejune/git-multirepo/internal/git"
+	"github.com/yejune/git-multirepo/internal/journal"
+	"github.com/yejune/git-multirepo/internal/manifest"
+)
+
+// headCommit returns HEAD of the repository at path
+func headCommit(t *testing.T, path string) string {
+	t.Helper()
+	commit, err := git.GetCurrentCommit(path)
+	if err != nil {
+		t.Fatalf("failed to get HEAD of %s: %v", path, err)
+	}
+	return commit
+}
+
+func TestRunLock_RecordsCommitAndTag(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	remoteRepo := setupRemoteRepo(t)
+	exec.Command("git", "-C", remoteRepo, "tag", "v1.0.0").Run()
+
+	cloneBranch = ""
+	runClone(cloneCmd, []string{remoteRepo, "packages/locked"})
+	wsPath := filepath.Join(dir, "packages/locked")
+
+	output := captureOutput(func() {
+		if err := runLock(lockCmd, []string{}); err != nil {
+			t.Fatalf("runLock failed: %v", err)
+		}
+	})
+
+	lock, err := manifest.LoadLock(dir)
+	if err != nil {
+		t.Fatalf("failed to load lock: %v", err)
+	}
+	entry := lock.Find("packages/locked")
+	if entry == nil {
+		t.Fatalf("lock should contain packages/locked, output: %s", output)
+	}
+	if entry.Commit != headCommit(t, wsPath) {
+		t.Errorf("locked commit %s should equal HEAD", entry.Commit)
+	}
+	if entry.Tag != "v1.0.0" {
+		t.Errorf("expected tag v1.0.0, got %q", entry.Tag)
+	}
+	if entries, _ := journal.List(dir); len(entries) == 0 || entries[0].Command != "lock" {
+		t.Error("lock should be recorded for undo")
+	}
+}
+
+func TestRunLock_SkipsNotCloned(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	m := &manifest.Manifest{}
+	m.Add("packages/missing", "https://example.com/missing.git")
+	manifest.Save(dir, m)
+
+	output := captureOutput(func() {
+		if err := runLock(lockCmd, []string{}); err != nil {
+			t.Fatalf("runLock failed: %v", err)
+		}
+	})
+
+	if !strings.Contains(output, "not cloned") {
+		t.Errorf("output should mention not cloned workspace, got: %s", output)
+	}
+	lock, _ := manifest.LoadLock(dir)
+	if len(lock.Workspaces) != 0 {
+		t.Errorf("lock should be empty, got %+v", lock.Workspaces)
+	}
+}
+
+func TestRunSync_ClonesAtLockedCommit(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	remoteRepo := setupRemoteRepo(t)
+	cloneBranch = ""
+	runClone(cloneCmd, []string{remoteRepo, "packages/pinned"})
+	wsPath := filepath.Join(dir, "packages/pinned")
+
+	captureOutput(func() { runLock(lockCmd, []string{}) })
+	lockedCommit := headCommit(t, wsPath)
+
+	// Remote moves on, workspace disappears (fresh machine)
+	commitToRemote(t, remoteRepo, "new.txt", "new")
+	os.RemoveAll(wsPath)
+
+	output := captureOutput(func() {
+		if err := runSync(syncCmd, []string{}); err != nil {
+			t.Fatalf("runSync failed: %v", err)
+		}
+	})
+
+	if got := headCommit(t, wsPath); got != lockedCommit {
+		t.Errorf("expected workspace at locked commit %s, got %s\n%s", lockedCommit, got, output)
+	}
+	if _, err := os.Stat(filepath.Join(wsPath, "new.txt")); err == nil {
+		t.Error("files from newer commits should not be checked out")
+	}
+}
+
+func TestRunSync_LockedRefusesDrift(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	remoteRepo := setupRemoteRepo(t)
+	cloneBranch = ""
+	runClone(cloneCmd, []string{remoteRepo, "packages/drift"})
+	wsPath := filepath.Join(dir, "packages/drift")
+
+	captureOutput(func() { runLock(lockCmd, []string{}) })
+
+	syncLocked = true
+	defer func() { syncLocked = false }()
+
+	t.Run("matching workspace passes", func(t *testing.T) {
+		var err error
+		captureOutput(func() { err = runSync(syncCmd, []string{}) })
+		if err != nil {
+			t.Errorf("sync --locked should pass when workspaces match: %v", err)
+		}
+	})
+
+	t.Run("drifted workspace fails", func(t *testing.T) {
+		os.WriteFile(filepath.Join(wsPath, "local.txt"), []byte("x"), 0644)
+		exec.Command("git", "-C", wsPath, "add", ".").Run()
+		exec.Command("git", "-C", wsPath, "commit", "-m", "local").Run()
+
+		var err error
+		output := captureOutput(func() { err = runSync(syncCmd, []string{}) })
+		if err == nil {
+			t.Errorf("sync --locked should fail on drift, output: %s", output)
+		}
+		if !strings.Contains(output, "drift") {
+			t.Errorf("output should report drift, got: %s", output)
+		}
+	})
+}
+
+func TestRunSync_LockedRequiresLockFile(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	cloneBranch = ""
+	runClone(cloneCmd, []string{setupRemoteRepo(t), "packages/nolock"})
+
+	syncLocked = true
+	defer func() { syncLocked = false }()
+
+	err := runSync(syncCmd, []string{})
+	if err == nil || !strings.Contains(err.Error(), manifest.LockFileName) {
+		t.Errorf("sync --locked should require a lock file, got: %v", err)
+	}
+}
+
+func TestRunSync_KeepsExistingWorkspaceOffLock(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	remoteRepo := setupRemoteRepo(t)
+	cloneBranch = ""
+	runClone(cloneCmd, []string{remoteRepo, "packages/pinned"})
+	wsPath := filepath.Join(dir, "packages/pinned")
+
+	captureOutput(func() { runLock(lockCmd, []string{}) })
+	lockedCommit := headCommit(t, wsPath)
+
+	// pull moves the branch past the lock
+	commitToRemote(t, remoteRepo, "new.txt", "new")
+	if out, err := exec.Command("git", "-C", wsPath, "pull", "-q").CombinedOutput(); err != nil {
+		t.Fatalf("pull failed: %v\n%s", err, out)
+	}
+	pulledCommit := headCommit(t, wsPath)
+
+	output := captureOutput(func() {
+		if err := runSync(syncCmd, []string{}); err != nil {
+			t.Fatalf("runSync failed: %v", err)
+		}
+	})
+	if got := headCommit(t, wsPath); got != pulledCommit {
+		t.Errorf("sync should not move an existing workspace, got %s", got)
+	}
+	if !strings.Contains(output, shortCommit(lockedCommit)+" is locked") {
+		t.Errorf("sync should report the drift, got: %s", output)
+	}
+}
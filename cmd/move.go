@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yejune/git-multirepo/internal/backup"
+	"github.com/yejune/git-multirepo/internal/common"
+	"github.com/yejune/git-multirepo/internal/git"
+	"github.com/yejune/git-multirepo/internal/manifest"
+	"github.com/yejune/git-multirepo/internal/patch"
+)
+
+var moveCmd = &cobra.Command{
+	Use:     "move <old> <new>",
+	Aliases: []string{"mv"},
+	Short:   "Move a repository to another path",
+	Long: `Move a workspace to another path.
+
+The workspace directory is moved as a whole, so its .git directory, hooks,
+skip-worktree flags and uncommitted changes come along. The manifest entry
+(keeping its place and comments), the lock file, the .gitignore entry, saved
+keep file patches and live backups follow the new path. Archived backups keep
+their original path.
+
+Refuses if the destination exists or overlaps another workspace.
+'git multirepo undo' moves the workspace and its patches back; backups stay
+under the new path.
+
+Examples:
+  git multirepo move packages/lib libs/core
+  git multirepo mv apps/web apps/site`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMove,
+}
+
+func init() {
+	rootCmd.AddCommand(moveCmd)
+}
+
+func runMove(cmd *cobra.Command, args []string) error {
+	oldPath := path.Clean(filepath.ToSlash(args[0]))
+	newPath := path.Clean(filepath.ToSlash(args[1]))
+
+	ctx, err := common.LoadWorkspaceContext()
+	if err != nil {
+		return err
+	}
+
+	ws := ctx.Manifest.Find(oldPath)
+	if ws == nil {
+		return fmt.Errorf("repository not found: %s", oldPath)
+	}
+	if include := ws.IncludedFrom(); include != "" {
+		return fmt.Errorf("repository %s comes from include %s, move it there", oldPath, include)
+	}
+	if newPath == oldPath {
+		return fmt.Errorf("%s is already at %s", oldPath, newPath)
+	}
+	if strings.HasPrefix(newPath, oldPath+"/") {
+		return fmt.Errorf("cannot move %s into itself", oldPath)
+	}
+	if err := checkWorkspacePath(ctx.Manifest, newPath, oldPath); err != nil {
+		return err
+	}
+
+	oldFull := filepath.Join(ctx.RepoRoot, oldPath)
+	newFull := filepath.Join(ctx.RepoRoot, newPath)
+	if _, err := os.Lstat(newFull); err == nil {
+		return fmt.Errorf("destination already exists: %s", newPath)
+	}
+
+	entry := beginJournal(ctx.RepoRoot, "move "+oldPath+" "+newPath, ctx.Manifest)
+	defer entry.Finish()
+
+	// 1. Directory (a workspace that isn't cloned only moves in the manifest)
+	moved := false
+	if _, err := os.Lstat(oldFull); err == nil {
+		if err := os.MkdirAll(filepath.Dir(newFull), 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := os.Rename(oldFull, newFull); err != nil {
+			return fmt.Errorf("failed to move %s: %w", oldPath, err)
+		}
+		common.RemoveEmptyParents(filepath.Dir(oldFull), ctx.RepoRoot)
+		moved = true
+		entry.Moved(oldPath, newPath)
+	}
+
+	// 2. Manifest and lock file, under the manifest lock
+	_, err = manifest.Update(ctx.RepoRoot, func(m *manifest.Manifest) error {
+		if !m.Rename(oldPath, newPath) {
+			return fmt.Errorf("repository not found: %s", oldPath)
+		}
+		if !manifest.LockExists(ctx.RepoRoot) {
+			return nil
+		}
+		lock, err := manifest.LoadLock(ctx.RepoRoot)
+		if err != nil {
+			return fmt.Errorf("failed to load lock file: %w", err)
+		}
+		if lock.Rename(oldPath, newPath) {
+			return manifest.SaveLock(ctx.RepoRoot, lock)
+		}
+		return nil
+	})
+	if err != nil {
+		if moved {
+			if rerr := os.Rename(newFull, oldFull); rerr != nil {
+				return fmt.Errorf("%w (and failed to move %s back: %v)", err, newPath, rerr)
+			}
+			common.RemoveEmptyParents(filepath.Dir(newFull), ctx.RepoRoot)
+		}
+		return err
+	}
+	fmt.Printf("✓ Moved %s → %s\n", oldPath, newPath)
+
+	// 3. .gitignore
+	if err := git.RemoveFromGitignore(ctx.RepoRoot, oldPath); err != nil {
+		fmt.Printf("⚠ Failed to update .gitignore: %v\n", err)
+	} else if err := git.AddToGitignore(ctx.RepoRoot, newPath); err != nil {
+		fmt.Printf("⚠ Failed to update .gitignore: %v\n", err)
+	}
+
+	// 4. Saved patches, keep file conflicts and backups
+	multireposDir := filepath.Join(ctx.RepoRoot, ".multirepos")
+	patchesDir := filepath.Join(multireposDir, "patches")
+	_, statErr := os.Lstat(filepath.Join(patchesDir, newPath))
+	if n, err := backup.MoveTree(filepath.Join(patchesDir, oldPath), filepath.Join(patchesDir, newPath)); err != nil {
+		fmt.Printf("⚠ Failed to move patches: %v\n", err)
+	} else if n > 0 {
+		common.RemoveEmptyParents(filepath.Dir(filepath.Join(patchesDir, oldPath)), patchesDir)
+		if os.IsNotExist(statErr) {
+			rel := filepath.Join(".multirepos", "patches")
+			entry.Moved(filepath.Join(rel, oldPath), filepath.Join(rel, newPath))
+		}
+		fmt.Printf("  ✓ Moved %d patch(es)\n", n)
+	}
+
+	if state, err := patch.LoadConflicts(multireposDir); err == nil && state.RenameWorkspace(oldPath, newPath) {
+		if err := patch.SaveConflicts(multireposDir, state); err != nil {
+			fmt.Printf("⚠ Failed to update keep file conflicts: %v\n", err)
+		}
+	}
+
+	if n, err := backup.Relocate(filepath.Join(multireposDir, "backup"), oldPath, newPath); err != nil {
+		fmt.Printf("⚠ Failed to move backups: %v\n", err)
+	} else if n > 0 {
+		fmt.Printf("  ✓ Moved %d backup(s)\n", n)
+	}
+
+	if moved {
+		fmt.Printf("ℹ Stage the move in the parent repository: git add -A %s %s\n", oldPath, newPath)
+	}
+	return nil
+}
+
+// checkWorkspacePath refuses p as a new workspace path if it is not a usable
+// path or overlaps a workspace of m other than except (the one being moved)
+func checkWorkspacePath(m *manifest.Manifest, p, except string) error {
+	probe := manifest.Manifest{Workspaces: []manifest.WorkspaceEntry{{Path: p, Repo: "-"}}}
+	if problems := probe.Validate(); len(problems) > 0 {
+		return fmt.Errorf("invalid path: %s", problems[0].Error())
+	}
+	top := strings.SplitN(p, "/", 2)[0]
+	if p == "." || top == ".git" || top == ".multirepos" {
+		return fmt.Errorf("invalid path: %s", p)
+	}
+
+	for _, ws := range m.Workspaces {
+		if ws.Path == except {
+			continue
+		}
+		switch {
+		case ws.Path == p:
+			return fmt.Errorf("repository already exists at %s", p)
+		case strings.HasPrefix(p, ws.Path+"/"):
+			return fmt.Errorf("%s is inside workspace %s", p, ws.Path)
+		case strings.HasPrefix(ws.Path, p+"/"):
+			return fmt.Errorf("%s contains workspace %s", p, ws.Path)
+		}
+	}
+	return nil
+}
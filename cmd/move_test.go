@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yejune/git-multirepo/internal/git"
+	"github.com/yejune/git-multirepo/internal/hooks"
+	"github.com/yejune/git-multirepo/internal/manifest"
+)
+
+func TestRunMove(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	writeTestManifest(t, dir,
+		manifest.WorkspaceEntry{Path: "packages/lib", Repo: setupRemoteRepo(t), Keep: []string{"README.md"}},
+		manifest.WorkspaceEntry{Path: "apps/web", Repo: setupRemoteRepo(t)},
+	)
+	// The first sync clones, the second applies keep files and hooks
+	for i := 0; i < 2; i++ {
+		captureOutput(func() {
+			if err := runSync(syncCmd, nil); err != nil {
+				t.Fatalf("sync failed: %v", err)
+			}
+		})
+	}
+	manifest.SaveLock(dir, &manifest.Lock{Workspaces: []manifest.LockEntry{{Path: "packages/lib", Commit: "abc"}}})
+	patchFile := filepath.Join(dir, ".multirepos", "patches", "packages", "lib", "README.md.patch")
+	os.MkdirAll(filepath.Dir(patchFile), 0755)
+	os.WriteFile(patchFile, []byte("patch"), 0644)
+
+	t.Run("refuses overlapping destinations", func(t *testing.T) {
+		for _, dst := range []string{"apps/web", "apps/web/lib", "apps", "packages/lib/sub", "../lib", ".multirepos/lib"} {
+			if err := runMove(moveCmd, []string{"packages/lib", dst}); err == nil {
+				t.Errorf("move to %s should fail", dst)
+			}
+		}
+		os.WriteFile(filepath.Join(dir, "taken"), nil, 0644)
+		if err := runMove(moveCmd, []string{"packages/lib", "taken"}); err == nil {
+			t.Error("move onto an existing file should fail")
+		}
+		if err := runMove(moveCmd, []string{"missing", "x"}); err == nil {
+			t.Error("move of an unknown workspace should fail")
+		}
+	})
+
+	output := captureOutput(func() {
+		if err := runMove(moveCmd, []string{"packages/lib/", "libs/core"}); err != nil {
+			t.Fatalf("move failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "✓ Moved packages/lib → libs/core") || !strings.Contains(output, "Moved 1 patch(es)") {
+		t.Errorf("unexpected output: %s", output)
+	}
+
+	newFull := filepath.Join(dir, "libs", "core")
+	if !git.IsRepo(newFull) {
+		t.Fatal("workspace should be at the new path")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "packages")); !os.IsNotExist(err) {
+		t.Error("emptied parent directory should be removed")
+	}
+	if files, _ := git.ListSkipWorktree(newFull); len(files) != 1 {
+		t.Errorf("skip-worktree flags should be kept, got %v", files)
+	}
+	if !hooks.IsWorkspaceHookInstalled(newFull) {
+		t.Error("workspace hook should be kept")
+	}
+
+	m, _ := manifest.Load(dir)
+	if m.Exists("packages/lib") || m.Find("libs/core") == nil || m.Workspaces[0].Path != "libs/core" {
+		t.Errorf("manifest entry should be renamed in place, got %+v", m.Workspaces)
+	}
+	lock, _ := manifest.LoadLock(dir)
+	if lock.Find("libs/core") == nil {
+		t.Error("lock entry should be renamed")
+	}
+	gitignore, _ := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	if strings.Contains(string(gitignore), "packages/lib/.git/") || !strings.Contains(string(gitignore), "libs/core/.git/") {
+		t.Errorf(".gitignore should follow the move, got:\n%s", gitignore)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".multirepos", "patches", "libs", "core", "README.md.patch")); err != nil {
+		t.Error("patches should follow the move")
+	}
+
+	// undo moves everything back
+	undoForce = true
+	defer func() { undoForce = false }()
+	captureOutput(func() {
+		if err := runUndo(undoCmd, nil); err != nil {
+			t.Fatalf("undo failed: %v", err)
+		}
+	})
+	if !git.IsRepo(filepath.Join(dir, "packages", "lib")) {
+		t.Error("undo should move the workspace back")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "libs")); !os.IsNotExist(err) {
+		t.Error("undo should remove the emptied destination parents")
+	}
+	if _, err := os.Stat(patchFile); err != nil {
+		t.Error("undo should move the patches back")
+	}
+	if m, _ := manifest.Load(dir); !m.Exists("packages/lib") {
+		t.Error("undo should restore the manifest")
+	}
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/yejune/git-multirepo/internal/manifest"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats accepted by --output
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputYAML = "yaml"
+)
+
+// workspacesReport is the top-level document printed by --output json|yaml
+type workspacesReport struct {
+	Workspaces []workspaceReport `json:"workspaces" yaml:"workspaces"`
+}
+
+// workspaceReport is the machine-readable description of a single workspace.
+// Field names are part of the public output schema; only add fields, never rename.
+type workspaceReport struct {
+	Path           string            `json:"path" yaml:"path"`
+	Repo           string            `json:"repo" yaml:"repo"`
+	Branch         string            `json:"branch,omitempty" yaml:"branch,omitempty"` // Branch configured in manifest
+	Keep           []string          `json:"keep" yaml:"keep"`
+	Groups         []string          `json:"groups,omitempty" yaml:"groups,omitempty"`
+	Cloned         bool              `json:"cloned" yaml:"cloned"`
+	State          string            `json:"state,omitempty" yaml:"state,omitempty"` // clean, modified, not_cloned, error
+	CurrentBranch  string            `json:"current_branch,omitempty" yaml:"current_branch,omitempty"`
+	TrackingBranch string            `json:"tracking_branch,omitempty" yaml:"tracking_branch,omitempty"`
+	Shallow        bool              `json:"shallow,omitempty" yaml:"shallow,omitempty"`           // Shallow clone (status)
+	Filter         string            `json:"filter,omitempty" yaml:"filter,omitempty"`             // Partial clone filter (status)
+	Sparse         []string          `json:"sparse,omitempty" yaml:"sparse,omitempty"`             // Sparse checkout directories (status)
+	Origin         string            `json:"origin,omitempty" yaml:"origin,omitempty"`             // URL of the origin remote (status)
+	RemoteDrift    bool              `json:"remote_drift,omitempty" yaml:"remote_drift,omitempty"` // origin is not the manifest repo (status)
+	Local          *localReport      `json:"local,omitempty" yaml:"local,omitempty"`
+	Remote         *remoteReport     `json:"remote,omitempty" yaml:"remote,omitempty"`
+	Conflicts      []string          `json:"conflicts,omitempty" yaml:"conflicts,omitempty"` // Keep files with unresolved merge conflicts
+	Error          string            `json:"error,omitempty" yaml:"error,omitempty"`
+	Workspaces     []workspaceReport `json:"workspaces,omitempty" yaml:"workspaces,omitempty"` // Nested workspaces (list -r)
+}
+
+// localReport mirrors git.WorkspaceStatus
+type localReport struct {
+	Modified         []string `json:"modified" yaml:"modified"`
+	Untracked        []string `json:"untracked" yaml:"untracked"`
+	Staged           []string `json:"staged" yaml:"staged"`
+	TotalUncommitted int      `json:"total_uncommitted" yaml:"total_uncommitted"`
+}
+
+// remoteReport holds ahead/behind counts against origin
+type remoteReport struct {
+	Ahead      int    `json:"ahead" yaml:"ahead"`
+	Behind     int    `json:"behind" yaml:"behind"`
+	FetchError string `json:"fetch_error,omitempty" yaml:"fetch_error,omitempty"`
+}
+
+// newWorkspaceReport creates a report with the manifest fields filled in
+func newWorkspaceReport(ws *manifest.WorkspaceEntry) workspaceReport {
+	return workspaceReport{
+		Path:   ws.Path,
+		Repo:   ws.Repo,
+		Branch: ws.Branch,
+		Keep:   nonNil(ws.Keep),
+		Groups: ws.Groups,
+	}
+}
+
+// nonNil returns an empty slice instead of nil so JSON prints [] rather than null
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// validateOutputFormat checks the value of an --output flag
+func validateOutputFormat(format string) error {
+	switch format {
+	case outputText, outputJSON, outputYAML:
+		return nil
+	default:
+		return fmt.Errorf("invalid output format: %s (expected text, json or yaml)", format)
+	}
+}
+
+// writeStructured serializes v as JSON or YAML to w
+func writeStructured(w io.Writer, format string, v interface{}) error {
+	switch format {
+	case outputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case outputYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		return enc.Close()
+	default:
+		return fmt.Errorf("invalid output format: %s", format)
+	}
+}
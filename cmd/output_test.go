@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestValidateOutputFormat(t *testing.T) {
+	for _, format := range []string{"text", "json", "yaml"} {
+		if err := validateOutputFormat(format); err != nil {
+			t.Errorf("%s should be valid: %v", format, err)
+		}
+	}
+	if err := validateOutputFormat("xml"); err == nil {
+		t.Error("xml should be rejected")
+	}
+}
+
+func TestRunStatus_JSONOutput(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	remoteRepo := setupRemoteRepo(t)
+	cloneBranch = ""
+	runClone(cloneCmd, []string{remoteRepo, "packages/json-status"})
+
+	// Make workspace dirty
+	os.WriteFile(filepath.Join(dir, "packages/json-status", "README.md"), []byte("changed"), 0644)
+
+	statusOutput = outputJSON
+	defer func() { statusOutput = outputText }()
+
+	output := captureOutput(func() {
+		if err := runStatus(statusCmd, []string{}); err != nil {
+			t.Fatalf("runStatus failed: %v", err)
+		}
+	})
+
+	var report workspacesReport
+	if err := json.Unmarshal([]byte(output), &report); err != nil {
+		t.Fatalf("output should be valid JSON: %v\n%s", err, output)
+	}
+	if len(report.Workspaces) != 1 {
+		t.Fatalf("expected 1 workspace, got %d", len(report.Workspaces))
+	}
+
+	ws := report.Workspaces[0]
+	if ws.Path != "packages/json-status" || !ws.Cloned {
+		t.Errorf("unexpected workspace report: %+v", ws)
+	}
+	if ws.State != "modified" {
+		t.Errorf("expected state modified, got %s", ws.State)
+	}
+	if ws.Local == nil || len(ws.Local.Modified) != 1 || ws.Local.Modified[0] != "README.md" {
+		t.Errorf("expected README.md as modified, got %+v", ws.Local)
+	}
+	if ws.Remote == nil {
+		t.Error("remote section should be present")
+	}
+	if ws.CurrentBranch == "" || ws.TrackingBranch == "" {
+		t.Errorf("expected current and tracking branch, got %q / %q", ws.CurrentBranch, ws.TrackingBranch)
+	}
+}
+
+func TestRunStatus_InvalidOutput(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	statusOutput = "xml"
+	defer func() { statusOutput = outputText }()
+
+	if err := runStatus(statusCmd, []string{}); err == nil {
+		t.Error("should error on invalid output format")
+	}
+}
+
+func TestRunList_YAMLOutput(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	cloneBranch = ""
+	runClone(cloneCmd, []string{setupRemoteRepo(t), "packages/yaml-list"})
+
+	listOutput = outputYAML
+	defer func() { listOutput = outputText }()
+
+	output := captureOutput(func() {
+		if err := runList(listCmd, []string{}); err != nil {
+			t.Fatalf("runList failed: %v", err)
+		}
+	})
+
+	var report workspacesReport
+	if err := yaml.Unmarshal([]byte(output), &report); err != nil {
+		t.Fatalf("output should be valid YAML: %v\n%s", err, output)
+	}
+	if len(report.Workspaces) != 1 || report.Workspaces[0].State != "clean" {
+		t.Errorf("expected one clean workspace, got: %s", output)
+	}
+}
+
+func TestRunList_JSONOutputEmpty(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	listOutput = outputJSON
+	defer func() { listOutput = outputText }()
+
+	output := captureOutput(func() {
+		runList(listCmd, []string{})
+	})
+
+	if !strings.Contains(output, `"workspaces": []`) {
+		t.Errorf("empty list should print an empty array, got: %s", output)
+	}
+}
+
+func TestRunBranch_JSONOutput(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	cloneBranch = ""
+	runClone(cloneCmd, []string{setupRemoteRepo(t), "packages/json-branch"})
+
+	branchOutput = outputJSON
+	defer func() { branchOutput = outputText }()
+
+	output := captureOutput(func() {
+		if err := runBranch(branchCmd, []string{"packages/json-branch"}); err != nil {
+			t.Fatalf("runBranch failed: %v", err)
+		}
+	})
+
+	var report workspacesReport
+	if err := json.Unmarshal([]byte(output), &report); err != nil {
+		t.Fatalf("output should be valid JSON: %v\n%s", err, output)
+	}
+	if len(report.Workspaces) != 1 {
+		t.Fatalf("expected 1 workspace, got %d", len(report.Workspaces))
+	}
+	ws := report.Workspaces[0]
+	if ws.CurrentBranch != "main" {
+		t.Errorf("expected current branch main, got %q", ws.CurrentBranch)
+	}
+	if ws.TrackingBranch != "origin/main" {
+		t.Errorf("expected tracking branch origin/main, got %q", ws.TrackingBranch)
+	}
+}
@@ -2,9 +2,12 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/yejune/git-multirepo/internal/backup"
@@ -12,30 +15,94 @@ import (
 	"github.com/yejune/git-multirepo/internal/git"
 	"github.com/yejune/git-multirepo/internal/i18n"
 	"github.com/yejune/git-multirepo/internal/interactive"
+	"github.com/yejune/git-multirepo/internal/manifest"
 	"github.com/yejune/git-multirepo/internal/patch"
 )
 
 var pullCmd = &cobra.Command{
-	Use:   "pull [path]",
+	Use:   "pull [path...]",
 	Short: "Pull latest changes for repositories",
 	Long: `Pull latest changes for registered repositories.
 
 Examples:
   git multirepo pull              # Pull all repositories with confirmation
   git multirepo pull apps/admin   # Pull specific repository only
+  git multirepo pull -g backend   # Pull repositories in group "backend"
+  git multirepo pull -j 8         # Fetch up to 8 repositories in parallel
+  git multirepo pull --yes --keep-strategy=fail   # Non-interactive (CI, cron)
+  git multirepo pull -r           # Also pull workspaces of nested manifests
 
-For each repository:
+All repositories are fetched in parallel first. Then, for each repository:
   1. Shows current branch and uncommitted files
-  2. Asks for confirmation (Y/n)
+  2. Asks for confirmation (Y/n), unless --yes
   3. Pulls from remote
-  4. Shows result (✓ Updated / ✗ Failed)`,
+  4. Shows result (✓ Updated / ✗ Failed)
+
+When a keep file changed upstream, --keep-strategy (or keep_strategy in
+.git.multirepos) decides what happens instead of the interactive menu:
+  reapply  Update to remote version and reapply local changes as a patch
+  theirs   Update to remote version, discard local changes
+  ours     Keep the local file as is
+  fail     Leave the repository untouched and report a failure
+With --yes and no strategy configured, reapply is used.
+
+With --recursive, selected workspaces that have their own .git.multirepos are
+processed afterwards the same way, depth-first (all of their workspaces).
+
+Exits non-zero if any repository failed to pull or a patch conflicted.`,
 	RunE: runPull,
 }
 
+// Keep strategies for keep files changed upstream, in interactive menu order
+const (
+	keepStrategyReapply = "reapply"
+	keepStrategyTheirs  = "theirs"
+	keepStrategyOurs    = "ours"
+	keepStrategyFail    = "fail"
+)
+
+var (
+	pullJobs         int
+	pullGroups       []string
+	pullYes          bool
+	pullKeepStrategy string
+	pullRecursive    bool
+)
+
 func init() {
+	pullCmd.Flags().IntVarP(&pullJobs, "jobs", "j", common.DefaultJobs(), "Number of workspaces to fetch in parallel")
+	pullCmd.Flags().BoolVarP(&pullYes, "yes", "y", false, "Pull without asking for confirmation")
+	pullCmd.Flags().StringVar(&pullKeepStrategy, "keep-strategy", "", "Keep files changed upstream: reapply, theirs, ours or fail")
+	pullCmd.Flags().BoolVarP(&pullRecursive, "recursive", "r", false, "Also pull workspaces of nested .git.multirepos, depth-first")
+	addGroupFlag(pullCmd, &pullGroups)
 	rootCmd.AddCommand(pullCmd)
 }
 
+// validateKeepStrategy checks a --keep-strategy or keep_strategy value
+func validateKeepStrategy(strategy string) error {
+	switch strategy {
+	case "", keepStrategyReapply, keepStrategyTheirs, keepStrategyOurs, keepStrategyFail:
+		return nil
+	}
+	return fmt.Errorf("invalid keep strategy %q (use reapply, theirs, ours or fail)", strategy)
+}
+
+// resolveKeepStrategy picks the keep strategy from the flag, then the manifest.
+// An empty result means ask interactively.
+func resolveKeepStrategy(m *manifest.Manifest) (string, error) {
+	strategy := pullKeepStrategy
+	if strategy == "" {
+		strategy = m.KeepStrategy
+	}
+	if err := validateKeepStrategy(strategy); err != nil {
+		return "", err
+	}
+	if strategy == "" && pullYes {
+		strategy = keepStrategyReapply
+	}
+	return strategy, nil
+}
+
 func runPull(cmd *cobra.Command, args []string) error {
 	// Load workspace context
 	ctx, err := common.LoadWorkspaceContext()
@@ -48,13 +115,55 @@ func runPull(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	if _, err := resolveKeepStrategy(ctx.Manifest); err != nil {
+		return err
+	}
+
 	// Filter workspaces if path argument provided
-	workspacesToProcess, err := ctx.FilterWorkspaces(args)
+	workspacesToProcess, err := selectWorkspaces(ctx, args, pullGroups)
+	if err != nil {
+		return err
+	}
+
+	err = pullRepo(ctx, workspacesToProcess)
+	if pullRecursive {
+		nestedErr := forEachNestedManifest(ctx, workspacesToProcess, func(nested *common.WorkspaceContext, path string) error {
+			if len(nested.Manifest.Workspaces) == 0 {
+				fmt.Println(i18n.T("no_subs_registered"))
+				return nil
+			}
+			return pullRepo(nested, nested.Manifest.Workspaces)
+		})
+		if err == nil {
+			err = nestedErr
+		}
+	}
+	return err
+}
+
+// pullRepo pulls the given workspaces of one repository's manifest
+func pullRepo(ctx *common.WorkspaceContext, workspacesToProcess []manifest.WorkspaceEntry) error {
+	strategy, err := resolveKeepStrategy(ctx.Manifest)
 	if err != nil {
-		return fmt.Errorf(i18n.T("sub_not_found", args[0]))
+		return err
 	}
 
-	for _, workspace := range workspacesToProcess {
+	entry := beginJournal(ctx.RepoRoot, "pull", ctx.Manifest)
+	defer entry.Finish()
+
+	// Forget conflicts the user has already resolved
+	pruneKeepConflicts(ctx.RepoRoot)
+
+	// Fetch all workspaces concurrently up front; confirmation and pull stay sequential
+	fetchErrs := ctx.RunWorkspacesParallel(workspacesToProcess, pullJobs, io.Discard, func(ws *manifest.WorkspaceEntry, fullPath string, out io.Writer) error {
+		if !git.IsRepo(fullPath) {
+			return nil
+		}
+		return git.Fetch(fullPath)
+	})
+
+	failed := 0
+	for idx, workspace := range workspacesToProcess {
 		fullPath := filepath.Join(ctx.RepoRoot, workspace.Path)
 
 		// Check if directory exists and is a git repo
@@ -71,6 +180,7 @@ func runPull(cmd *cobra.Command, args []string) error {
 			fmt.Printf("%s:\n", workspace.Path)
 			fmt.Printf("  %s\n", i18n.T("failed_get_branch", err))
 			fmt.Println()
+			failed++
 			continue
 		}
 
@@ -80,6 +190,7 @@ func runPull(cmd *cobra.Command, args []string) error {
 			fmt.Printf("%s:\n", workspace.Path)
 			fmt.Printf("  Failed to get status: %v\n", err)
 			fmt.Println()
+			failed++
 			continue
 		}
 
@@ -92,41 +203,65 @@ func runPull(cmd *cobra.Command, args []string) error {
 		}
 
 		// Ask for confirmation using unified prompt
-		confirmed, err := interactive.ConfirmYesNo("  " + i18n.T("pull_confirm"))
-		if err != nil {
-			fmt.Printf("  %s\n", i18n.T("failed_read_input", err))
-			fmt.Println()
-			continue
-		}
+		if !pullYes {
+			confirmed, err := interactive.ConfirmYesNo("  " + i18n.T("pull_confirm"))
+			if err != nil {
+				fmt.Printf("  %s\n", i18n.T("failed_read_input", err))
+				fmt.Println()
+				continue
+			}
 
-		if !confirmed {
-			fmt.Printf("  %s\n", i18n.T("pull_skipped"))
-			fmt.Println()
-			continue
+			if !confirmed {
+				fmt.Printf("  %s\n", i18n.T("pull_skipped"))
+				fmt.Println()
+				continue
+			}
 		}
 
-		// Fetch remote changes first
-		if err := git.Fetch(fullPath); err != nil {
+		// Remote changes were fetched before the loop
+		if fetchErrs[idx] != nil {
 			fmt.Printf("  %s\n", i18n.T("fetch_failed"))
 			fmt.Println()
+			failed++
 			continue
 		}
 
 		// Handle keep files before pulling
+		var kept map[string][]byte
 		keepFiles := workspace.Keep
 		if len(keepFiles) > 0 {
-			if err := handleKeepFiles(fullPath, branch, keepFiles, ctx.RepoRoot, workspace.Path); err != nil {
+			result, err := handleKeepFiles(fullPath, branch, keepFiles, ctx.RepoRoot, workspace.Path, strategy)
+			if err != nil {
 				fmt.Printf("  Keep file handling failed: %v\n", err)
 				fmt.Println()
+				failed++
 				continue
 			}
+			if len(result.conflicts) > 0 {
+				// Pull still runs; conflict markers are left for manual resolution
+				recordKeepConflicts(ctx.RepoRoot, workspace.Path, result.conflicts)
+				failed++
+			}
+			kept = result.kept
+		}
+
+		// Follow changes to the sparse checkout directories in the manifest
+		if msg, err := updateSparseCheckout(fullPath, &workspace); err != nil {
+			fmt.Printf("  %s\n", i18n.T("sparse_failed", err))
+		} else if msg != "" {
+			fmt.Printf("  %s\n", msg)
 		}
 
-		// Pull from remote
-		if err := git.Pull(fullPath); err != nil {
+		// Pull from remote, then put back skipped keep files whether or not it worked
+		pullErr := git.Pull(fullPath)
+		if err := restoreKeptFiles(fullPath, kept); err != nil {
+			fmt.Printf("  ⚠ %v\n", err)
+		}
+		if pullErr != nil {
 			fmt.Printf("  %s\n", i18n.T("pull_failed"))
 			fmt.Printf("  %s\n", i18n.T("run_status", workspace.Path))
 			fmt.Println()
+			failed++
 			continue
 		}
 
@@ -144,19 +279,110 @@ func runPull(cmd *cobra.Command, args []string) error {
 		fmt.Println()
 	}
 
+	if failed > 0 {
+		return fmt.Errorf("%s", i18n.T("pull_failures", failed))
+	}
 	return nil
 }
 
-// handleKeepFiles handles keep files with remote changes interactively
-func handleKeepFiles(wsPath, branch string, keepFiles []string, repoRoot string, workspacePath string) error {
+// keepResult is the outcome of handling keep files before a pull
+type keepResult struct {
+	conflicts []string          // Files left with conflict markers
+	kept      map[string][]byte // Skipped files: local content to restore after the pull
+}
+
+// handleKeepFiles handles keep files with remote changes using strategy,
+// or interactively if strategy is empty
+func handleKeepFiles(wsPath, branch string, keepFiles []string, repoRoot string, workspacePath string, strategy string) (*keepResult, error) {
+	result := &keepResult{kept: map[string][]byte{}}
 	// Use transaction pattern for skip-worktree handling
-	return git.WithSkipWorktreeTransaction(wsPath, keepFiles, func() error {
-		return handleKeepFilesWork(wsPath, branch, keepFiles, repoRoot, workspacePath)
+	err := git.WithSkipWorktreeTransaction(wsPath, keepFiles, func() error {
+		return handleKeepFilesWork(wsPath, branch, keepFiles, repoRoot, workspacePath, strategy, result)
 	})
+	return result, err
 }
 
+// restoreKeptFiles writes back the local content of keep files skipped before the pull
+func restoreKeptFiles(wsPath string, kept map[string][]byte) error {
+	for file, content := range kept {
+		if err := os.WriteFile(filepath.Join(wsPath, file), content, 0644); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// recordKeepConflicts adds conflicted keep files to the conflict state so status can show them
+func recordKeepConflicts(repoRoot, workspacePath string, files []string) {
+	multireposDir := filepath.Join(repoRoot, ".multirepos")
+	state, err := patch.LoadConflicts(multireposDir)
+	if err != nil {
+		fmt.Printf("  ⚠ Failed to record conflicts: %v\n", err)
+		return
+	}
+
+	for _, file := range files {
+		state.Add(patch.Conflict{
+			Workspace: workspacePath,
+			File:      file,
+			Patch:     filepath.Join(".multirepos", "patches", workspacePath, filepath.Base(file)+".patch"),
+			Time:      time.Now(),
+		})
+	}
+
+	if err := patch.SaveConflicts(multireposDir, state); err != nil {
+		fmt.Printf("  ⚠ Failed to record conflicts: %v\n", err)
+	}
+}
+
+// pruneKeepConflicts drops recorded conflicts whose conflict markers have been removed
+func pruneKeepConflicts(repoRoot string) {
+	multireposDir := filepath.Join(repoRoot, ".multirepos")
+	state, err := patch.LoadConflicts(multireposDir)
+	if err != nil || !state.Prune(repoRoot) {
+		return
+	}
+	if err := patch.SaveConflicts(multireposDir, state); err != nil {
+		fmt.Printf("⚠ Failed to update %s: %v\n", patch.ConflictsFileName, err)
+	}
+}
+
+// unresolvedKeepConflicts returns keep files still containing conflict markers, by workspace path
+func unresolvedKeepConflicts(repoRoot string) map[string][]string {
+	result := map[string][]string{}
+	state, err := patch.LoadConflicts(filepath.Join(repoRoot, ".multirepos"))
+	if err != nil {
+		return result
+	}
+	for _, c := range state.Conflicts {
+		if patch.IsUnresolved(repoRoot, c) {
+			result[c.Workspace] = append(result[c.Workspace], c.File)
+		}
+	}
+	return result
+}
+
+// keepMenu is the interactive menu for a keep file changed upstream.
+// The first three entries line up with the keep strategies.
+var keepMenu = []string{
+	"Update origin and merge local changes (recommended)",
+	"Update origin only (discard patch)",
+	"Skip (keep current state)",
+	"Show diff",
+}
+
+// keepStrategyChoice maps a keep strategy onto its keepMenu index
+var keepStrategyChoice = map[string]int{
+	keepStrategyReapply: 0,
+	keepStrategyTheirs:  1,
+	keepStrategyOurs:    2,
+}
+
+// errPatchConflict reports that local changes did not merge cleanly with upstream
+var errPatchConflict = errors.New("merge has conflicts")
+
 // handleKeepFilesWork contains the actual work logic (extracted for transaction)
-func handleKeepFilesWork(wsPath, branch string, keepFiles []string, repoRoot string, workspacePath string) error {
+func handleKeepFilesWork(wsPath, branch string, keepFiles []string, repoRoot string, workspacePath string, strategy string, result *keepResult) error {
 	for _, file := range keepFiles {
 		// Check if file has remote changes
 		hasChanges, err := git.HasRemoteChanges(wsPath, file, branch)
@@ -168,6 +394,10 @@ func handleKeepFilesWork(wsPath, branch string, keepFiles []string, repoRoot str
 			continue // No remote changes, skip
 		}
 
+		if strategy == keepStrategyFail {
+			return fmt.Errorf("%s changed upstream (--keep-strategy=fail)", file)
+		}
+
 		// Create patch directory in .multirepos/patches/{workspace-path}/
 		patchDir := filepath.Join(repoRoot, ".multirepos", "patches", workspacePath)
 		if err := os.MkdirAll(patchDir, 0755); err != nil {
@@ -176,81 +406,26 @@ func handleKeepFilesWork(wsPath, branch string, keepFiles []string, repoRoot str
 
 		patchPath := filepath.Join(patchDir, filepath.Base(file)+".patch")
 
+		if strategy != "" {
+			err := applyKeepChoice(keepStrategyChoice[strategy], wsPath, branch, file, patchPath, repoRoot, result)
+			if errors.Is(err, errPatchConflict) {
+				result.conflicts = append(result.conflicts, file)
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("%s: %w", file, err)
+			}
+			continue
+		}
+
 		// Interactive loop for this file
 		for {
-			choice, err := interactive.ResolveConflict(file, []string{
-				"Update origin and reapply patch (recommended)",
-				"Update origin only (discard patch)",
-				"Skip (keep current state)",
-				"Show diff",
-			})
+			choice, err := interactive.ResolveConflict(file, keepMenu)
 			if err != nil {
 				return fmt.Errorf("failed to get user choice: %w", err)
 			}
 
-			switch choice {
-			case 0: // Update origin and reapply patch (recommended)
-				// Backup original file
-				backupDir := filepath.Join(repoRoot, ".multirepos", "backup")
-				if err := backup.CreateFileBackup(filepath.Join(wsPath, file), backupDir, repoRoot); err != nil {
-					return fmt.Errorf("backup failed for %s: %w", file, err)
-				}
-
-				// Create patch from current local changes
-				if err := patch.Create(wsPath, file, patchPath); err != nil {
-					fmt.Printf("  ⚠ Failed to create patch: %v\n", err)
-					continue
-				}
-
-				// Backup patch file
-				if err := backup.CreatePatchBackup(patchPath, backupDir); err != nil {
-					fmt.Printf("  ⚠ Patch backup failed: %v\n", err)
-				}
-
-				// Reset file to remote version
-				if err := git.ResetFile(wsPath, file, branch); err != nil {
-					fmt.Printf("  ⚠ Failed to reset file: %v\n", err)
-					continue
-				}
-
-				// Check patch for conflicts before applying
-				hasConflicts, err := patch.Check(wsPath, patchPath)
-				if err != nil {
-					fmt.Printf("  ⚠ Failed to check patch: %v\n", err)
-					fmt.Printf("  ℹ Original backed up, patch saved to: %s\n", patchPath)
-					continue
-				}
-				if hasConflicts {
-					fmt.Printf("  ⚠ Patch has conflicts\n")
-					fmt.Printf("  ℹ Original backed up, patch saved to: %s\n", patchPath)
-					continue
-				}
-
-				// Apply patch
-				if err := patch.Apply(wsPath, patchPath); err != nil {
-					fmt.Printf("  ⚠ Failed to apply patch: %v\n", err)
-					fmt.Printf("  ℹ Original backed up\n")
-				} else {
-					fmt.Printf("  ✓ Updated %s and reapplied local changes\n", file)
-					// Clean up successful patch
-					os.Remove(patchPath)
-				}
-				return nil
-
-			case 1: // Update origin only (discard patch)
-				// Reset file to remote version
-				if err := git.ResetFile(wsPath, file, branch); err != nil {
-					fmt.Printf("  ⚠ Failed to reset file: %v\n", err)
-					continue
-				}
-				fmt.Printf("  ✓ Updated %s to remote version (local changes discarded)\n", file)
-				return nil
-
-			case 2: // Skip (keep current state)
-				fmt.Printf("  ⏭ Skipped %s (keeping current state)\n", file)
-				return nil
-
-			case 3: // Show diff
+			if choice == 3 { // Show diff
 				diff, err := git.GetFileDiff(wsPath, file, branch)
 				if err != nil {
 					fmt.Printf("  ⚠ Failed to get diff: %v\n", err)
@@ -261,12 +436,105 @@ func handleKeepFilesWork(wsPath, branch string, keepFiles []string, repoRoot str
 				}
 				// Continue loop to show menu again
 				continue
+			}
 
-			default:
-				return fmt.Errorf("invalid choice: %d", choice)
+			err = applyKeepChoice(choice, wsPath, branch, file, patchPath, repoRoot, result)
+			if errors.Is(err, errPatchConflict) {
+				result.conflicts = append(result.conflicts, file)
+				break
+			}
+			if err != nil {
+				// Let the user pick another action
+				fmt.Printf("  ⚠ %v\n", err)
+				continue
 			}
+			break
 		}
 	}
 
 	return nil
 }
+
+// applyKeepChoice performs a keepMenu action (other than Show diff) for one keep file
+func applyKeepChoice(choice int, wsPath, branch, file, patchPath, repoRoot string, result *keepResult) error {
+	switch choice {
+	case 0: // Update origin and reapply patch (recommended)
+		// Backup original file
+		backupDir := filepath.Join(repoRoot, ".multirepos", "backup")
+		if err := backup.CreateFileBackup(filepath.Join(wsPath, file), backupDir, repoRoot); err != nil {
+			return fmt.Errorf("backup failed for %s: %w", file, err)
+		}
+
+		// Create patch from current local changes
+		if err := patch.Create(wsPath, file, patchPath); err != nil {
+			return fmt.Errorf("failed to create patch: %w", err)
+		}
+
+		// Backup patch file
+		if err := backup.CreatePatchBackup(patchPath, backupDir); err != nil {
+			fmt.Printf("  ⚠ Patch backup failed: %v\n", err)
+		}
+
+		// Three-way merge: base is the last pulled version, upstream the new remote version
+		base, err := git.ShowFile(wsPath, "HEAD", file)
+		if err != nil {
+			return fmt.Errorf("failed to read base version: %w", err)
+		}
+		upstream, err := git.ShowFile(wsPath, "origin/"+branch, file)
+		if err != nil {
+			return fmt.Errorf("failed to read upstream version: %w", err)
+		}
+		local, err := os.ReadFile(filepath.Join(wsPath, file))
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+
+		merged, conflicts, err := patch.Merge(base, local, upstream)
+		if err != nil {
+			return fmt.Errorf("merge failed: %w", err)
+		}
+
+		// Reset file to remote version so the pull can fast-forward it, then write the merge result
+		if err := git.ResetFile(wsPath, file, branch); err != nil {
+			return fmt.Errorf("failed to reset file: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(wsPath, file), merged, 0644); err != nil {
+			return fmt.Errorf("failed to write merged %s: %w", file, err)
+		}
+
+		if conflicts > 0 {
+			fmt.Printf("  ⚠ %d conflict(s) in %s, conflict markers written\n", conflicts, file)
+			fmt.Printf("  ℹ Original backed up, patch saved to: %s\n", patchPath)
+			return errPatchConflict
+		}
+
+		fmt.Printf("  ✓ Updated %s and merged local changes\n", file)
+		// Clean up successful patch
+		os.Remove(patchPath)
+		return nil
+
+	case 1: // Update origin only (discard patch)
+		// Reset file to remote version
+		if err := git.ResetFile(wsPath, file, branch); err != nil {
+			return fmt.Errorf("failed to reset file: %w", err)
+		}
+		fmt.Printf("  ✓ Updated %s to remote version (local changes discarded)\n", file)
+		return nil
+
+	case 2: // Skip (keep current state)
+		// Step the file aside so the pull can update it, then restore it afterwards
+		content, err := os.ReadFile(filepath.Join(wsPath, file))
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		if err := git.ResetFile(wsPath, file, branch); err != nil {
+			return fmt.Errorf("failed to reset file: %w", err)
+		}
+		result.kept[file] = content
+		fmt.Printf("  ⏭ Skipped %s (keeping current state)\n", file)
+		return nil
+
+	default:
+		return fmt.Errorf("invalid choice: %d", choice)
+	}
+}
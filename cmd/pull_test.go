@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/yejune/git-multirepo/internal/manifest"
+	"github.com/yejune/git-multirepo/internal/patch"
 )
 
 // ============================================================================
@@ -610,3 +611,208 @@ func TestRunPull_PullFailed(t *testing.T) {
 		_ = dir
 	})
 }
+
+// ============================================================================
+// Test Cases: Non-interactive pull
+// ============================================================================
+
+// resetPullFlags restores pull flags to their defaults
+func resetPullFlags() {
+	pullYes = false
+	pullKeepStrategy = ""
+	pullGroups = nil
+}
+
+func TestRunPull_YesSkipsConfirmation(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetPullFlags()
+
+	remoteRepo := setupRemoteRepoWithCommits(t)
+	cloneBranch = ""
+	runClone(cloneCmd, []string{remoteRepo, "packages/yes"})
+	commitToRemote(t, remoteRepo, "new.txt", "new")
+
+	pullYes = true
+
+	output := captureOutput(func() {
+		if err := runPull(pullCmd, []string{}); err != nil {
+			t.Fatalf("runPull failed: %v", err)
+		}
+	})
+
+	if _, err := os.Stat(filepath.Join(dir, "packages/yes", "new.txt")); err != nil {
+		t.Errorf("remote change should be pulled without prompting, output: %s", output)
+	}
+}
+
+func TestRunPull_KeepStrategies(t *testing.T) {
+	tests := []struct {
+		strategy    string
+		wantErr     bool
+		wantContent string
+	}{
+		{keepStrategyTheirs, false, "version: 2.0"},
+		{keepStrategyOurs, false, "version: 1.0\nlocal: true"},
+		{keepStrategyFail, true, "version: 1.0\nlocal: true"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			dir, cleanup := setupTestEnv(t)
+			defer cleanup()
+			defer resetPullFlags()
+
+			remoteRepo := setupRemoteRepoWithCommits(t)
+			setupWorkspaceWithKeepFile(t, dir, remoteRepo, "packages/keep")
+
+			wsPath := filepath.Join(dir, "packages/keep")
+			os.WriteFile(filepath.Join(wsPath, "config.yml"), []byte("version: 1.0\nlocal: true"), 0644)
+			commitToRemote(t, remoteRepo, "config.yml", "version: 2.0")
+
+			pullYes = true
+			pullKeepStrategy = tt.strategy
+
+			var err error
+			output := captureOutput(func() {
+				err = runPull(pullCmd, []string{})
+			})
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error=%v, got %v\n%s", tt.wantErr, err, output)
+			}
+			content, _ := os.ReadFile(filepath.Join(wsPath, "config.yml"))
+			if string(content) != tt.wantContent {
+				t.Errorf("expected config.yml %q, got %q\n%s", tt.wantContent, content, output)
+			}
+		})
+	}
+}
+
+func TestRunPull_KeepStrategyFromManifest(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetPullFlags()
+
+	remoteRepo := setupRemoteRepoWithCommits(t)
+	setupWorkspaceWithKeepFile(t, dir, remoteRepo, "packages/keep")
+
+	m, _ := manifest.Load(dir)
+	m.KeepStrategy = keepStrategyFail
+	manifest.Save(dir, m)
+
+	os.WriteFile(filepath.Join(dir, "packages/keep", "config.yml"), []byte("local"), 0644)
+	commitToRemote(t, remoteRepo, "config.yml", "version: 2.0")
+
+	pullYes = true
+
+	var err error
+	captureOutput(func() { err = runPull(pullCmd, []string{}) })
+	if err == nil {
+		t.Error("keep_strategy: fail from manifest should make pull fail")
+	}
+
+	t.Run("flag overrides manifest", func(t *testing.T) {
+		pullKeepStrategy = keepStrategyOurs
+		captureOutput(func() { err = runPull(pullCmd, []string{}) })
+		if err != nil {
+			t.Errorf("--keep-strategy=ours should override manifest: %v", err)
+		}
+	})
+}
+
+func TestRunPull_InvalidKeepStrategy(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetPullFlags()
+
+	cloneBranch = ""
+	runClone(cloneCmd, []string{setupRemoteRepoWithCommits(t), "packages/x"})
+
+	pullKeepStrategy = "merge"
+	err := runPull(pullCmd, []string{})
+	if err == nil || !strings.Contains(err.Error(), "invalid keep strategy") {
+		t.Errorf("expected invalid keep strategy error, got: %v", err)
+	}
+}
+
+// ============================================================================
+// Test Cases: Three-way merge of keep files
+// ============================================================================
+
+// setupMergeWorkspace creates a workspace whose keep file config.yml has several lines
+func setupMergeWorkspace(t *testing.T, dir string) (remoteRepo, wsPath string) {
+	t.Helper()
+
+	remoteRepo = setupRemoteRepoWithCommits(t)
+	commitToRemote(t, remoteRepo, "config.yml", "name: app\nhost: localhost\nmode: dev\nlevel: info\nport: 80\n")
+	setupWorkspaceWithKeepFile(t, dir, remoteRepo, "packages/merge")
+	return remoteRepo, filepath.Join(dir, "packages/merge")
+}
+
+func TestRunPull_ReapplyMergesKeepFile(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetPullFlags()
+
+	remoteRepo, wsPath := setupMergeWorkspace(t, dir)
+	os.WriteFile(filepath.Join(wsPath, "config.yml"), []byte("name: app\nhost: localhost\nmode: dev\nlevel: info\nport: 8080\n"), 0644)
+	commitToRemote(t, remoteRepo, "config.yml", "name: service\nhost: localhost\nmode: dev\nlevel: info\nport: 80\n")
+
+	pullYes = true
+	pullKeepStrategy = keepStrategyReapply
+
+	var err error
+	output := captureOutput(func() { err = runPull(pullCmd, []string{}) })
+	if err != nil {
+		t.Fatalf("runPull failed: %v\n%s", err, output)
+	}
+
+	content, _ := os.ReadFile(filepath.Join(wsPath, "config.yml"))
+	want := "name: service\nhost: localhost\nmode: dev\nlevel: info\nport: 8080\n"
+	if string(content) != want {
+		t.Errorf("expected merged config %q, got %q\n%s", want, content, output)
+	}
+}
+
+func TestRunPull_ReapplyConflictIsRecorded(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetPullFlags()
+
+	remoteRepo, wsPath := setupMergeWorkspace(t, dir)
+	configPath := filepath.Join(wsPath, "config.yml")
+	os.WriteFile(configPath, []byte("name: app\nhost: localhost\nmode: dev\nlevel: info\nport: 8080\n"), 0644)
+	commitToRemote(t, remoteRepo, "config.yml", "name: app\nhost: localhost\nmode: dev\nlevel: info\nport: 9090\n")
+
+	pullYes = true
+	pullKeepStrategy = keepStrategyReapply
+
+	var err error
+	output := captureOutput(func() { err = runPull(pullCmd, []string{}) })
+	if err == nil {
+		t.Fatalf("pull should fail on merge conflict\n%s", output)
+	}
+
+	content, _ := os.ReadFile(configPath)
+	if !patch.HasConflictMarkers(content) {
+		t.Errorf("config.yml should contain conflict markers, got %q", content)
+	}
+
+	t.Run("status shows conflict", func(t *testing.T) {
+		output := captureOutput(func() { runStatus(statusCmd, []string{}) })
+		if !strings.Contains(output, "merge conflicts") || !strings.Contains(output, "config.yml") {
+			t.Errorf("status should report keep file conflict, got: %s", output)
+		}
+	})
+
+	t.Run("resolved conflict is pruned by sync", func(t *testing.T) {
+		os.WriteFile(configPath, []byte("name: app\nhost: localhost\nmode: dev\nlevel: info\nport: 8080\n"), 0644)
+		captureOutput(func() { runSync(syncCmd, []string{}) })
+
+		state, _ := patch.LoadConflicts(filepath.Join(dir, ".multirepos"))
+		if len(state.Conflicts) != 0 {
+			t.Errorf("resolved conflict should be pruned, got %+v", state.Conflicts)
+		}
+	})
+}
@@ -3,41 +3,65 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/spf13/cobra"
+	"github.com/yejune/git-multirepo/internal/common"
 	"github.com/yejune/git-multirepo/internal/config"
 	"github.com/yejune/git-multirepo/internal/git"
 	"github.com/yejune/git-multirepo/internal/github"
+	"github.com/yejune/git-multirepo/internal/manifest"
 )
 
 var pushCmd = &cobra.Command{
-	Use:    "push [path]",
-	Short:  "Push repository to organization",
-	Long: `Push local commits to organization repository.
+	Use:   "push [--all] [path...]",
+	Short: "Push workspaces with unpushed commits",
+	Long: `Push workspaces with unpushed commits.
 
-Creates a private repository if it doesn't exist.
+With --all (or --group), every selected workspace that is ahead of its
+upstream is pushed to that upstream, in parallel, followed by a result table.
+With --set-upstream, branches without one are pushed to origin under their own
+name.
 
 Examples:
+  git multirepo push --all                   # Push every ahead workspace
+  git multirepo push --all apps/api apps/web # Only these workspaces
+  git multirepo push -g backend              # Only workspaces in group "backend"
+  git multirepo push --all --set-upstream    # Also push branches without upstream
+  git multirepo push --all --dry-run         # Show what would be pushed
+
+Without --all, push a single directory to the organization configured in
+~/.git.multirepo, creating a private repository if it doesn't exist:
   git multirepo push              # Push current directory
   git multirepo push apps/admin   # Push specific repository
 
-Prerequisites:
+Prerequisites (single directory mode):
   - ~/.git.multirepo must exist with organization configured
   - GitHub authentication (gh CLI or git credential helper)`,
-	Hidden: true, // Hidden command
-	RunE:   runPush,
+	RunE: runPush,
 }
 
+var (
+	pushAll         bool
+	pushGroups      []string
+	pushSetUpstream bool
+	pushDryRun      bool
+	pushJobs        int
+)
+
 func init() {
-	// CRITICAL: Only register command if config exists
-	if shouldEnablePushCommand() {
-		rootCmd.AddCommand(pushCmd)
-	}
+	pushCmd.Flags().BoolVarP(&pushAll, "all", "a", false, "Push every workspace with unpushed commits")
+	pushCmd.Flags().BoolVarP(&pushSetUpstream, "set-upstream", "u", false, "Push branches without upstream and set it (with --all)")
+	pushCmd.Flags().BoolVar(&pushDryRun, "dry-run", false, "Show what would be pushed (with --all)")
+	pushCmd.Flags().IntVarP(&pushJobs, "jobs", "j", common.DefaultJobs(), "Number of workspaces to push in parallel")
+	addGroupFlag(pushCmd, &pushGroups)
+	rootCmd.AddCommand(pushCmd)
 }
 
 // shouldEnablePushCommand checks if push command should be enabled
@@ -50,8 +74,23 @@ func shouldEnablePushCommand() bool {
 	return err == nil && org != ""
 }
 
-// runPush implements the push workflow
+// runPush pushes all selected workspaces with --all, otherwise one directory to the organization
 func runPush(cmd *cobra.Command, args []string) error {
+	if pushAll || len(pushGroups) > 0 {
+		return runPushAll(args)
+	}
+	if !shouldEnablePushCommand() {
+		return fmt.Errorf("organization not configured in ~/.git.multirepo (use --all to push workspaces to their upstream)")
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("only one path can be pushed to the organization (use --all for several workspaces)")
+	}
+
+	return runPushToOrganization(args)
+}
+
+// runPushToOrganization pushes one directory to the organization, creating the repository if needed
+func runPushToOrganization(args []string) error {
 	// 1. Determine workspace path (from args or current dir)
 	workspacePath, err := determineWorkspacePath(args)
 	if err != nil {
@@ -222,3 +261,134 @@ func setupRemote(workspacePath, repoURL string) error {
 
 	return nil // URL matches
 }
+
+// pushResult is the outcome of pushing one workspace
+type pushResult struct {
+	Path    string
+	Branch  string
+	Commits int    // Commits ahead of upstream, -1 if unknown
+	Result  string // pushed, would push, up to date, no upstream, not cloned, failed
+	Err     error
+}
+
+// runPushAll pushes every selected workspace that has unpushed commits
+func runPushAll(args []string) error {
+	ctx, err := common.LoadWorkspaceContext()
+	if err != nil {
+		return err
+	}
+
+	workspaces, err := selectWorkspaces(ctx, args, pushGroups)
+	if err != nil {
+		return err
+	}
+	if len(workspaces) == 0 {
+		fmt.Println("No repositories registered.")
+		return nil
+	}
+
+	results := make([]pushResult, len(workspaces))
+	index := make(map[string]int, len(workspaces))
+	for i, ws := range workspaces {
+		index[ws.Path] = i
+	}
+
+	// git push output is printed per workspace, in manifest order
+	ctx.RunWorkspacesParallel(workspaces, pushJobs, os.Stdout, func(ws *manifest.WorkspaceEntry, fullPath string, out io.Writer) error {
+		results[index[ws.Path]] = pushWorkspace(ws, fullPath, out)
+		return nil
+	})
+
+	return printPushResults(os.Stdout, results)
+}
+
+// pushWorkspace pushes the current branch of a workspace if it is ahead of its upstream
+func pushWorkspace(ws *manifest.WorkspaceEntry, fullPath string, out io.Writer) pushResult {
+	result := pushResult{Path: ws.Path, Commits: -1}
+
+	if !git.IsRepo(fullPath) {
+		result.Result = "not cloned"
+		return result
+	}
+
+	branch, err := git.GetCurrentBranch(fullPath)
+	if err != nil || branch == "HEAD" {
+		result.Result = "failed"
+		result.Err = fmt.Errorf("not on a branch")
+		return result
+	}
+	result.Branch = branch
+
+	upstream, err := git.GetTrackingBranch(fullPath)
+	setUpstream := false
+	if err != nil {
+		if !pushSetUpstream {
+			result.Result = "no upstream"
+			return result
+		}
+		setUpstream = true
+	} else {
+		result.Commits, err = git.CountAhead(fullPath, upstream)
+		if err != nil {
+			result.Result = "failed"
+			result.Err = fmt.Errorf("failed to count commits: %w", err)
+			return result
+		}
+		if result.Commits == 0 {
+			result.Result = "up to date"
+			return result
+		}
+	}
+
+	if pushDryRun {
+		result.Result = "would push"
+		return result
+	}
+
+	fmt.Fprintf(out, "%s:\n", ws.Path)
+	if err := git.PushBranch(fullPath, branch, setUpstream, out); err != nil {
+		result.Result = "failed"
+		result.Err = err
+		return result
+	}
+	result.Result = "pushed"
+	return result
+}
+
+// printPushResults prints a table of push results and returns an error if any failed
+func printPushResults(w io.Writer, results []pushResult) error {
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "WORKSPACE\tBRANCH\tCOMMITS\tRESULT")
+
+	failed := 0
+	for _, r := range results {
+		commits := "-"
+		if r.Commits >= 0 {
+			commits = fmt.Sprintf("%d", r.Commits)
+		}
+
+		status := r.Result
+		switch r.Result {
+		case "pushed", "would push":
+			status = "✓ " + r.Result
+		case "failed":
+			failed++
+			status = fmt.Sprintf("✗ failed: %v", r.Err)
+		default:
+			status = "○ " + r.Result
+		}
+
+		branch := r.Branch
+		if branch == "" {
+			branch = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.Path, branch, commits, status)
+	}
+	tw.Flush()
+
+	if failed > 0 {
+		return fmt.Errorf("push failed in %d workspace(s)", failed)
+	}
+	return nil
+}
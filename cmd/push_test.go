@@ -430,3 +430,153 @@ func TestGitOperations(t *testing.T) {
 		}
 	})
 }
+
+// ============================================================================
+// Batch push (--all)
+// ============================================================================
+
+// resetPushFlags restores batch push flags to their defaults
+func resetPushFlags() {
+	pushAll = false
+	pushGroups = nil
+	pushSetUpstream = false
+	pushDryRun = false
+}
+
+// setupBareRemote creates a bare repository that accepts pushes
+func setupBareRemote(t *testing.T) string {
+	t.Helper()
+	bare := filepath.Join(t.TempDir(), "remote.git")
+	if err := exec.Command("git", "clone", "--bare", setupRemoteRepo(t), bare).Run(); err != nil {
+		t.Fatalf("failed to create bare remote: %v", err)
+	}
+	return bare
+}
+
+// commitInWorkspace creates a commit in a workspace
+func commitInWorkspace(t *testing.T, wsPath, file string) {
+	t.Helper()
+	os.WriteFile(filepath.Join(wsPath, file), []byte(file), 0644)
+	exec.Command("git", "-C", wsPath, "add", file).Run()
+	if err := exec.Command("git", "-C", wsPath, "-c", "user.email=test@test.com", "-c", "user.name=Test", "commit", "-m", "Add "+file).Run(); err != nil {
+		t.Fatalf("commit failed: %v", err)
+	}
+}
+
+func TestRunPush_All(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetPushFlags()
+
+	remote1 := setupBareRemote(t)
+	cloneBranch = ""
+	runClone(cloneCmd, []string{remote1, "packages/ahead"})
+	runClone(cloneCmd, []string{setupBareRemote(t), "packages/clean"})
+	runClone(cloneCmd, []string{setupBareRemote(t), "packages/feature"})
+
+	ahead := filepath.Join(dir, "packages/ahead")
+	feature := filepath.Join(dir, "packages/feature")
+	commitInWorkspace(t, ahead, "one.txt")
+	commitInWorkspace(t, ahead, "two.txt")
+	exec.Command("git", "-C", feature, "checkout", "-b", "feature/x").Run()
+	commitInWorkspace(t, feature, "feature.txt")
+
+	pushAll = true
+
+	t.Run("dry run", func(t *testing.T) {
+		pushDryRun = true
+		defer func() { pushDryRun = false }()
+
+		output := captureOutput(func() {
+			if err := runPush(pushCmd, nil); err != nil {
+				t.Fatalf("push --all --dry-run failed: %v", err)
+			}
+		})
+		for _, want := range []string{"WORKSPACE", "✓ would push", "○ up to date", "○ no upstream"} {
+			if !strings.Contains(output, want) {
+				t.Errorf("output should contain %q, got:\n%s", want, output)
+			}
+		}
+		if count, _ := git.CountAhead(ahead, "origin/HEAD"); count != 2 {
+			t.Errorf("dry run should not push, ahead = %d", count)
+		}
+	})
+
+	t.Run("push ahead workspaces", func(t *testing.T) {
+		output := captureOutput(func() {
+			if err := runPush(pushCmd, nil); err != nil {
+				t.Fatalf("push --all failed: %v", err)
+			}
+		})
+		if !strings.Contains(output, "✓ pushed") {
+			t.Errorf("expected pushed result, got:\n%s", output)
+		}
+
+		branch, _ := git.GetCurrentBranch(ahead)
+		out, _ := exec.Command("git", "-C", remote1, "log", "--format=%s", "-1", branch).Output()
+		if strings.TrimSpace(string(out)) != "Add two.txt" {
+			t.Errorf("remote should have the pushed commits, got %q", out)
+		}
+	})
+
+	t.Run("set upstream", func(t *testing.T) {
+		pushSetUpstream = true
+		defer func() { pushSetUpstream = false }()
+
+		captureOutput(func() {
+			if err := runPush(pushCmd, []string{"packages/feature"}); err != nil {
+				t.Fatalf("push --all --set-upstream failed: %v", err)
+			}
+		})
+		if upstream, err := git.GetTrackingBranch(feature); err != nil || upstream != "origin/feature/x" {
+			t.Errorf("upstream should be origin/feature/x, got %q (%v)", upstream, err)
+		}
+	})
+
+	t.Run("push to the tracked branch", func(t *testing.T) {
+		// A local branch of another name, tracking a remote other than origin
+		exec.Command("git", "-C", ahead, "remote", "add", "upstream", remote1).Run()
+		exec.Command("git", "-C", ahead, "fetch", "-q", "upstream").Run()
+		base, _ := git.GetCurrentBranch(ahead)
+		exec.Command("git", "-C", ahead, "checkout", "-q", "-b", "work", "--track", "upstream/"+base).Run()
+		commitInWorkspace(t, ahead, "three.txt")
+
+		captureOutput(func() {
+			if err := runPush(pushCmd, []string{"packages/ahead"}); err != nil {
+				t.Fatalf("push failed: %v", err)
+			}
+		})
+		out, _ := exec.Command("git", "-C", remote1, "log", "--format=%s", "-1", base).Output()
+		if strings.TrimSpace(string(out)) != "Add three.txt" {
+			t.Errorf("the tracked branch %s should have the commit, got %q", base, out)
+		}
+		if exec.Command("git", "-C", remote1, "rev-parse", "--verify", "-q", "refs/heads/work").Run() == nil {
+			t.Error("the local branch name should not be created on the remote")
+		}
+	})
+}
+
+func TestRunPush_AllReportsFailures(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetPushFlags()
+
+	remote := setupBareRemote(t)
+	cloneBranch = ""
+	runClone(cloneCmd, []string{remote, "packages/broken"})
+	wsPath := filepath.Join(dir, "packages/broken")
+	commitInWorkspace(t, wsPath, "one.txt")
+
+	// Remote disappears
+	os.RemoveAll(remote)
+
+	pushAll = true
+	var err error
+	output := captureOutput(func() { err = runPush(pushCmd, nil) })
+	if err == nil || !strings.Contains(err.Error(), "1 workspace(s)") {
+		t.Errorf("expected failure, got: %v", err)
+	}
+	if !strings.Contains(output, "✗ failed") {
+		t.Errorf("table should show failure, got:\n%s", output)
+	}
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/yejune/git-multirepo/internal/common"
+	"github.com/yejune/git-multirepo/internal/manifest"
+)
+
+// errManifestVisited is returned for a nested manifest that was already processed
+var errManifestVisited = errors.New("already visited (manifest cycle), skipped")
+
+// nestedManifests tracks the repositories visited by --recursive, so
+// workspaces that lead back to an ancestor (e.g. through a symlink) or that
+// are reachable twice are processed only once
+type nestedManifests struct {
+	visited map[string]bool
+}
+
+func newNestedManifests(repoRoot string) *nestedManifests {
+	n := &nestedManifests{visited: map[string]bool{}}
+	n.visited[realPath(repoRoot)] = true
+	return n
+}
+
+// enter loads the manifest of the workspace at fullPath. Returns nil without
+// error if the workspace has no manifest of its own.
+func (n *nestedManifests) enter(fullPath string) (*common.WorkspaceContext, error) {
+	if _, err := os.Stat(filepath.Join(fullPath, manifest.FileName)); err != nil {
+		return nil, nil
+	}
+
+	real := realPath(fullPath)
+	if n.visited[real] {
+		return nil, errManifestVisited
+	}
+	n.visited[real] = true
+
+	return common.LoadWorkspaceContextAt(fullPath)
+}
+
+// forEachNestedManifest calls fn for every workspace of workspaces that has
+// its own manifest, depth-first in manifest order: after a nested manifest
+// is processed, its own nested manifests are. fn receives the nested context
+// and its path relative to the top-level repository.
+func forEachNestedManifest(ctx *common.WorkspaceContext, workspaces []manifest.WorkspaceEntry, fn func(nested *common.WorkspaceContext, path string) error) error {
+	nested := newNestedManifests(ctx.RepoRoot)
+	failed := 0
+
+	var walk func(parent *common.WorkspaceContext, workspaces []manifest.WorkspaceEntry, prefix string)
+	walk = func(parent *common.WorkspaceContext, workspaces []manifest.WorkspaceEntry, prefix string) {
+		for _, ws := range workspaces {
+			path := filepath.ToSlash(filepath.Join(prefix, ws.Path))
+			child, err := nested.enter(filepath.Join(parent.RepoRoot, ws.Path))
+			if err == errManifestVisited {
+				fmt.Printf("\n⚠ %s: %v\n", path, err)
+				continue
+			}
+			if err != nil {
+				fmt.Printf("\n✗ %s: %v\n", path, err)
+				failed++
+				continue
+			}
+			if child == nil {
+				continue
+			}
+
+			fmt.Printf("\n══ %s ══\n", path)
+			if err := fn(child, path); err != nil {
+				fmt.Printf("✗ %s: %v\n", path, err)
+				failed++
+			}
+			walk(child, child.Manifest.Workspaces, path)
+		}
+	}
+	walk(ctx, workspaces, "")
+
+	if failed > 0 {
+		return fmt.Errorf("%d nested manifest(s) failed", failed)
+	}
+	return nil
+}
+
+// realPath resolves symlinks of path, falling back to the cleaned path
+func realPath(path string) string {
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		return real
+	}
+	return filepath.Clean(path)
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yejune/git-multirepo/internal/common"
+	"github.com/yejune/git-multirepo/internal/manifest"
+)
+
+// writeTestManifest writes a manifest listing the given workspaces to dir
+func writeTestManifest(t *testing.T, dir string, workspaces ...manifest.WorkspaceEntry) {
+	t.Helper()
+	os.MkdirAll(dir, 0755)
+	if err := manifest.Save(dir, &manifest.Manifest{Workspaces: workspaces}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestForEachNestedManifest_DepthFirstAndCycles(t *testing.T) {
+	dir := t.TempDir()
+	writeTestManifest(t, dir, manifest.WorkspaceEntry{Path: "a", Repo: "r"}, manifest.WorkspaceEntry{Path: "c", Repo: "r"}, manifest.WorkspaceEntry{Path: "plain", Repo: "r"})
+	writeTestManifest(t, filepath.Join(dir, "a"), manifest.WorkspaceEntry{Path: "b", Repo: "r"}, manifest.WorkspaceEntry{Path: "back", Repo: "r"})
+	writeTestManifest(t, filepath.Join(dir, "a", "b"))
+	writeTestManifest(t, filepath.Join(dir, "c"))
+	os.MkdirAll(filepath.Join(dir, "plain"), 0755)
+	if err := os.Symlink(dir, filepath.Join(dir, "a", "back")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	ctx, err := common.LoadWorkspaceContextAt(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var visited []string
+	output := captureOutput(func() {
+		err = forEachNestedManifest(ctx, ctx.Manifest.Workspaces, func(nested *common.WorkspaceContext, path string) error {
+			visited = append(visited, path)
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.Join(visited, ",") != "a,a/b,c" {
+		t.Errorf("expected depth-first a,a/b,c, got %v", visited)
+	}
+	if !strings.Contains(output, "a/back: already visited") {
+		t.Errorf("cycle should be reported, got: %s", output)
+	}
+}
+
+func TestRunSync_RecursiveAndIncludes(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer func() { syncRecursive = false }()
+
+	// "platform" has its own manifest listing libs/core
+	coreRemote := setupRemoteRepo(t)
+	platformRemote := setupRemoteRepo(t)
+	writeTestManifest(t, platformRemote, manifest.WorkspaceEntry{Path: "libs/core", Repo: coreRemote})
+	exec.Command("git", "-C", platformRemote, "add", "-A").Run()
+	exec.Command("git", "-C", platformRemote, "commit", "-m", "Add manifest").Run()
+
+	// The parent includes a shared workspace list
+	sharedRemote := setupRemoteRepo(t)
+	os.WriteFile(filepath.Join(dir, "base.yml"), []byte("workspaces:\n  - path: shared\n    repo: "+sharedRemote+"\n"), 0644)
+	manifestContent := "include:\n  - base.yml\nworkspaces:\n  - path: platform\n    repo: " + platformRemote + "\n"
+	os.WriteFile(filepath.Join(dir, manifest.FileName), []byte(manifestContent), 0644)
+
+	syncRecursive = true
+	output := captureOutput(func() {
+		if err := runSync(syncCmd, nil); err != nil {
+			t.Errorf("sync failed: %v", err)
+		}
+	})
+
+	for _, path := range []string{"shared", "platform", "platform/libs/core"} {
+		if _, err := os.Stat(filepath.Join(dir, path, ".git")); err != nil {
+			t.Errorf("%s should be cloned, output: %s", path, output)
+		}
+	}
+	if !strings.Contains(output, "══ platform ══") {
+		t.Errorf("nested manifest should be announced, got: %s", output)
+	}
+
+	// Included workspaces are not copied into the manifest
+	if content, _ := os.ReadFile(filepath.Join(dir, manifest.FileName)); string(content) != manifestContent {
+		t.Errorf("manifest should be unchanged, got:\n%s", content)
+	}
+}
+
+func TestRunStatus_RecursiveJSON(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer func() { statusRecursive = false; statusOutput = outputText }()
+
+	cloneBranch = ""
+	runClone(cloneCmd, []string{setupRemoteRepo(t), "platform"})
+	writeTestManifest(t, filepath.Join(dir, "platform"), manifest.WorkspaceEntry{Path: "libs/core", Repo: "https://example.com/core.git"})
+
+	statusRecursive = true
+	statusOutput = outputJSON
+	output := captureOutput(func() {
+		if err := runStatus(statusCmd, nil); err != nil {
+			t.Errorf("status failed: %v", err)
+		}
+	})
+
+	var report workspacesReport
+	if err := json.Unmarshal([]byte(output), &report); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, output)
+	}
+	if len(report.Workspaces) != 1 || len(report.Workspaces[0].Workspaces) != 1 {
+		t.Fatalf("expected nested workspace report, got: %s", output)
+	}
+	if nested := report.Workspaces[0].Workspaces[0]; nested.Path != "libs/core" || nested.State != "not_cloned" {
+		t.Errorf("unexpected nested report: %+v", nested)
+	}
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/yejune/git-multirepo/internal/config"
+	"github.com/yejune/git-multirepo/internal/git"
+	"github.com/yejune/git-multirepo/internal/manifest"
+)
+
+// remoteURLs returns the repo and fallback URLs of a workspace with the
+// rewrite rules of the manifest and ~/.git.multirepo applied
+func remoteURLs(m *manifest.Manifest, ws *manifest.WorkspaceEntry) []string {
+	var rules []manifest.RewriteRule
+	rewrites, _ := config.GetURLRewrites()
+	for insteadOf, url := range rewrites {
+		rules = append(rules, manifest.RewriteRule{URL: url, InsteadOf: insteadOf})
+	}
+	return m.RemoteURLs(ws, rules)
+}
+
+// expectedOrigin returns the origin a workspace is cloned with: its repo with
+// the rewrite rules applied
+func expectedOrigin(m *manifest.Manifest, ws *manifest.WorkspaceEntry) string {
+	if urls := remoteURLs(m, ws); len(urls) > 0 {
+		return urls[0]
+	}
+	return ws.Repo
+}
+
+// originDrift returns the origin URL of a cloned workspace ("" if it has no
+// origin remote) and whether it points somewhere else than the manifest repo,
+// as written or as rewritten
+func originDrift(m *manifest.Manifest, ws *manifest.WorkspaceEntry, fullPath string) (string, bool) {
+	if ws.Repo == "" {
+		return "", false
+	}
+	origin, err := git.GetRemoteURL(fullPath)
+	if err != nil || origin == "" {
+		return "", true
+	}
+	if sameRepoURL(origin, ws.Repo) {
+		return origin, false
+	}
+	return origin, !sameRepoURL(origin, expectedOrigin(m, ws))
+}
+
+// sameRepoURL compares repository URLs, treating the SSH and HTTPS forms of
+// the same repository (and a trailing slash or .git suffix) as equal
+func sameRepoURL(a, b string) bool {
+	if keyA, keyB := git.RemoteKey(a), git.RemoteKey(b); keyA != "" && keyB != "" {
+		return keyA == keyB
+	}
+	normalize := func(url string) string {
+		url = strings.TrimSuffix(strings.TrimSpace(url), "/")
+		return strings.TrimSuffix(url, ".git")
+	}
+	return normalize(a) == normalize(b)
+}
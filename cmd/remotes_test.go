@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yejune/git-multirepo/internal/git"
+	"github.com/yejune/git-multirepo/internal/manifest"
+)
+
+func TestSameRepoURL(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"git@github.com:user/repo.git", "https://github.com/user/repo", true},
+		{"ssh://git@github.com/user/repo.git", "https://github.com/user/repo.git/", true},
+		{"https://GitHub.com/user/repo.git", "git@github.com:user/repo", true},
+		{"git@github.com:user/repo.git", "git@github.com:user/other.git", false},
+		{"git@github.com:user/repo.git", "git@gitlab.com:user/repo.git", false},
+		{"/srv/git/repo.git", "/srv/git/repo", true},
+		{"/srv/git/repo.git", "/srv/git/other.git", false},
+	}
+	for _, tt := range tests {
+		if got := sameRepoURL(tt.a, tt.b); got != tt.want {
+			t.Errorf("sameRepoURL(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestRunSync_RemoteDrift(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer func() {
+		syncUpdateRemotes = false
+		syncAdoptRemotes = false
+		statusOutput = outputText
+	}()
+
+	remote := setupRemoteRepo(t)
+	other := setupRemoteRepo(t)
+	writeTestManifest(t, dir, manifest.WorkspaceEntry{Path: "lib", Repo: remote})
+	captureOutput(func() { runSync(syncCmd, nil) })
+
+	lib := filepath.Join(dir, "lib")
+	exec.Command("git", "-C", lib, "remote", "set-url", "origin", other).Run()
+
+	// Reported by sync and status
+	output := captureOutput(func() {
+		if err := runSync(syncCmd, nil); err != nil {
+			t.Fatalf("sync failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "origin is "+other+", manifest repo is "+remote) {
+		t.Errorf("sync should report the drift, got: %s", output)
+	}
+
+	statusOutput = outputJSON
+	output = captureOutput(func() { runStatus(statusCmd, nil) })
+	var report workspacesReport
+	if err := json.Unmarshal([]byte(output), &report); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, output)
+	}
+	if got := report.Workspaces[0]; !got.RemoteDrift || got.Origin != other {
+		t.Errorf("status should report the drift, got %+v", got)
+	}
+
+	// --update-remotes: origin follows the manifest
+	syncUpdateRemotes = true
+	captureOutput(func() { runSync(syncCmd, nil) })
+	syncUpdateRemotes = false
+	if origin, _ := git.GetRemoteURL(lib); origin != remote {
+		t.Errorf("origin = %s, want %s", origin, remote)
+	}
+
+	// --adopt-remotes: the manifest follows origin
+	exec.Command("git", "-C", lib, "remote", "set-url", "origin", other).Run()
+	syncAdoptRemotes = true
+	output = captureOutput(func() { runSync(syncCmd, nil) })
+	if !strings.Contains(output, "Manifest repo set to "+other) {
+		t.Errorf("sync should adopt origin, got: %s", output)
+	}
+	m, _ := manifest.Load(dir)
+	if ws := m.Find("lib"); ws == nil || ws.Repo != other {
+		t.Errorf("manifest repo should be %s, got %+v", other, ws)
+	}
+}
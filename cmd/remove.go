@@ -49,6 +49,9 @@ func runRemove(cmd *cobra.Command, args []string) error {
 	if !ctx.Manifest.Exists(path) {
 		return fmt.Errorf("repository not found: %s", path)
 	}
+	if include := ctx.Manifest.Find(path).IncludedFrom(); include != "" {
+		return fmt.Errorf("repository %s comes from include %s, remove it there", path, include)
+	}
 
 	fullPath := filepath.Join(ctx.RepoRoot, path)
 
@@ -106,12 +109,15 @@ func runRemove(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Remove from manifest
-	// Note: ctx.Manifest.Remove always succeeds if ctx.Manifest.Exists returned true
-	ctx.Manifest.Remove(path)
+	entry := beginJournal(ctx.RepoRoot, "remove "+path, ctx.Manifest)
+	defer entry.Finish()
 
-	if err := ctx.SaveManifest(); err != nil {
-		return fmt.Errorf("failed to save manifest: %w", err)
+	// Remove from manifest
+	if _, err := manifest.Update(ctx.RepoRoot, func(m *manifest.Manifest) error {
+		m.Remove(path)
+		return nil
+	}); err != nil {
+		return err
 	}
 
 	// Remove from .gitignore
@@ -121,6 +127,17 @@ func runRemove(cmd *cobra.Command, args []string) error {
 
 	// Delete files
 	if !removeKeepFiles {
+		// Move to the journal so that 'git multirepo undo' can bring them back
+		if entry != nil {
+			if _, err := os.Stat(fullPath); err == nil {
+				err := entry.Trash(path)
+				if err == nil {
+					fmt.Printf("✓ Removed repository: %s (files deleted, 'git multirepo undo' restores them)\n", path)
+					return nil
+				}
+				fmt.Printf("⚠ Failed to move files to the journal, deleting them: %v\n", err)
+			}
+		}
 		if err := os.RemoveAll(fullPath); err != nil {
 			return fmt.Errorf("failed to delete files: %w", err)
 		}
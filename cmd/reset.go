@@ -42,8 +42,18 @@ func runReset(cmd *cobra.Command, args []string) error {
 
 	backupDir := filepath.Join(repoRoot, ".multirepos", "backup")
 
+	entry := beginJournal(repoRoot, "reset", m)
+	defer entry.Finish()
+
 	fmt.Println("Resetting repository state (unhiding all)...")
 
+	// 초기화한 항목 (manifest 저장 시 반영)
+	var (
+		resetMotherKeep bool
+		resetIgnore     bool
+		resetWorkspaces []string
+	)
+
 	// ============ 1. Keep 파일 처리 ============
 	// Mother repo
 	if len(m.Keep) > 0 {
@@ -65,6 +75,7 @@ func runReset(cmd *cobra.Command, args []string) error {
 
 		// Keep 리스트 제거
 		m.Keep = []string{}
+		resetMotherKeep = true
 	}
 
 	// Workspaces
@@ -90,6 +101,7 @@ func runReset(cmd *cobra.Command, args []string) error {
 
 			// Keep 리스트 제거
 			ws.Keep = []string{}
+			resetWorkspaces = append(resetWorkspaces, ws.Path)
 		}
 	}
 
@@ -104,10 +116,26 @@ func runReset(cmd *cobra.Command, args []string) error {
 
 		// Ignore 리스트 제거
 		m.Ignore = []string{}
+		resetIgnore = true
 	}
 
-	// Manifest 저장
-	manifest.Save(repoRoot, m)
+	// Manifest 저장 (현재 파일 기준으로 초기화한 항목만 반영)
+	if _, err := manifest.Update(repoRoot, func(current *manifest.Manifest) error {
+		if resetMotherKeep {
+			current.Keep = []string{}
+		}
+		if resetIgnore {
+			current.Ignore = []string{}
+		}
+		for _, path := range resetWorkspaces {
+			if entry := current.Find(path); entry != nil {
+				entry.Keep = []string{}
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
 
 	fmt.Println("\n✓ All hidden files are now visible")
 	fmt.Println("ℹ Backups saved to .multirepos/backup/")
@@ -0,0 +1,300 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yejune/git-multirepo/internal/backup"
+	"github.com/yejune/git-multirepo/internal/common"
+	"github.com/yejune/git-multirepo/internal/git"
+	"github.com/yejune/git-multirepo/internal/manifest"
+	"github.com/yejune/git-multirepo/internal/patch"
+)
+
+var (
+	restoreDate string
+	restoreKind string
+)
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Browse and recover backups of keep files and patches",
+	Long: `Browse and recover backups written by sync, pull and reset.
+
+Backups are searched in .multirepos/backup/modified, .multirepos/backup/patched
+and the monthly archives in .multirepos/backup/archived/*.tar.gz.
+
+A backup is referenced as <path>@<timestamp> (as printed by 'restore list'),
+or just <path> for the most recent backup of that path. Paths of modified files
+are relative to the parent repository; patch paths end in .patch.
+
+Examples:
+  git multirepo restore list                        # All backups, newest first
+  git multirepo restore list apps/api --date 2026-01
+  git multirepo restore show apps/api/config.json   # Print latest backup
+  git multirepo restore diff apps/api/config.json@20260109_143022
+  git multirepo restore apply apps/api/config.json  # Overwrite current file
+  git multirepo restore apply apps/api/config.json.patch   # git apply a saved patch`,
+}
+
+var restoreListCmd = &cobra.Command{
+	Use:   "list [path...]",
+	Short: "List backups, newest first",
+	RunE:  runRestoreList,
+}
+
+var restoreShowCmd = &cobra.Command{
+	Use:   "show <path[@timestamp]>",
+	Short: "Print the content of a backup",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runRestoreShow,
+}
+
+var restoreDiffCmd = &cobra.Command{
+	Use:   "diff <path[@timestamp]>",
+	Short: "Show differences between a backup and the current file",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runRestoreDiff,
+}
+
+var restoreApplyCmd = &cobra.Command{
+	Use:   "apply <path[@timestamp]>",
+	Short: "Restore a file from a backup, or apply a backed up patch",
+	Long: `Restore a file from a backup.
+
+For a modified-file backup, the current file is backed up and then overwritten.
+For a patch backup (path ending in .patch), the patch is applied to the
+workspace with git apply.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRestoreApply,
+}
+
+func init() {
+	restoreListCmd.Flags().StringVar(&restoreDate, "date", "", "Only backups from this day or month (YYYY-MM-DD or YYYY-MM)")
+	restoreListCmd.Flags().StringVar(&restoreKind, "kind", "", "Only this kind of backup: modified or patched")
+	restoreCmd.AddCommand(restoreListCmd, restoreShowCmd, restoreDiffCmd, restoreApplyCmd)
+	rootCmd.AddCommand(restoreCmd)
+}
+
+// loadBackups returns the repository root and all backups, newest first
+func loadBackups() (string, []backup.Entry, error) {
+	repoRoot, err := git.GetRepoRoot()
+	if err != nil {
+		return "", nil, fmt.Errorf("not in a git repository: %w", err)
+	}
+
+	entries, err := backup.List(filepath.Join(repoRoot, ".multirepos", "backup"))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read backups: %w", err)
+	}
+	return repoRoot, entries, nil
+}
+
+// findBackup loads backups and returns the one selected by ref
+func findBackup(ref string) (string, *backup.Entry, error) {
+	repoRoot, entries, err := loadBackups()
+	if err != nil {
+		return "", nil, err
+	}
+
+	entry, err := backup.Find(entries, ref)
+	if err != nil {
+		return "", nil, err
+	}
+	return repoRoot, entry, nil
+}
+
+// backupTarget returns the current file a backup was taken from
+func backupTarget(repoRoot string, e *backup.Entry) string {
+	if e.Kind == backup.KindPatched {
+		return filepath.Join(repoRoot, ".multirepos", "patches", e.Path)
+	}
+	return filepath.Join(repoRoot, e.Path)
+}
+
+func runRestoreList(cmd *cobra.Command, args []string) error {
+	if restoreKind != "" && restoreKind != backup.KindModified && restoreKind != backup.KindPatched {
+		return fmt.Errorf("invalid kind %q (use modified or patched)", restoreKind)
+	}
+
+	_, entries, err := loadBackups()
+	if err != nil {
+		return err
+	}
+
+	shown := 0
+	for _, e := range entries {
+		if restoreKind != "" && e.Kind != restoreKind {
+			continue
+		}
+		if restoreDate != "" && !strings.HasPrefix(e.Time.Format("2006-01-02"), restoreDate) {
+			continue
+		}
+		if len(args) > 0 && !matchesAnyPath(args, e.Path) {
+			continue
+		}
+
+		location := ""
+		if e.Archived() {
+			location = "  (archived: " + filepath.Base(e.Archive) + ")"
+		}
+		fmt.Printf("%s  %-8s  %s%s\n", e.Time.Format("2006-01-02 15:04:05"), e.Kind, e.Ref(), location)
+		shown++
+	}
+
+	if shown == 0 {
+		fmt.Println("No backups found.")
+	}
+	return nil
+}
+
+// matchesAnyPath checks if path matches one of the path/glob patterns
+func matchesAnyPath(patterns []string, path string) bool {
+	for _, pattern := range patterns {
+		if common.MatchPath(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func runRestoreShow(cmd *cobra.Command, args []string) error {
+	_, entry, err := findBackup(args[0])
+	if err != nil {
+		return err
+	}
+
+	content, err := backup.Read(*entry)
+	if err != nil {
+		return fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	_, err = os.Stdout.Write(content)
+	return err
+}
+
+func runRestoreDiff(cmd *cobra.Command, args []string) error {
+	repoRoot, entry, err := findBackup(args[0])
+	if err != nil {
+		return err
+	}
+
+	backupFile, cleanup, err := extractBackup(entry)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	current := backupTarget(repoRoot, entry)
+	if _, err := os.Stat(current); os.IsNotExist(err) {
+		current = os.DevNull
+	}
+
+	c := exec.Command("git", "diff", "--no-index", "--", backupFile, current)
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		// Exit code 1 only means the files differ
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return nil
+		}
+		return fmt.Errorf("git diff failed: %w", err)
+	}
+
+	fmt.Printf("%s is identical to the backup from %s\n", entry.Path, entry.Time.Format("2006-01-02 15:04:05"))
+	return nil
+}
+
+func runRestoreApply(cmd *cobra.Command, args []string) error {
+	repoRoot, entry, err := findBackup(args[0])
+	if err != nil {
+		return err
+	}
+
+	if entry.Kind == backup.KindPatched {
+		return applyPatchBackup(repoRoot, entry)
+	}
+
+	content, err := backup.Read(*entry)
+	if err != nil {
+		return fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	target := backupTarget(repoRoot, entry)
+
+	// Keep the current version recoverable as well
+	backupDir := filepath.Join(repoRoot, ".multirepos", "backup")
+	if err := backup.CreateFileBackup(target, backupDir, repoRoot); err != nil {
+		return fmt.Errorf("failed to back up current %s: %w", entry.Path, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(target, content, 0644); err != nil {
+		return fmt.Errorf("failed to restore %s: %w", entry.Path, err)
+	}
+
+	fmt.Printf("✓ Restored %s from backup %s\n", entry.Path, entry.Timestamp())
+	return nil
+}
+
+// applyPatchBackup applies a backed up patch to the workspace it was created in
+func applyPatchBackup(repoRoot string, entry *backup.Entry) error {
+	patchFile, cleanup, err := extractBackup(entry)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	// Patches are stored under the workspace path; the parent repo owns the rest
+	m, err := manifest.Load(repoRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load manifest: %w", err)
+	}
+	target, label := repoRoot, "parent repository"
+	longest := 0
+	for _, ws := range m.Workspaces {
+		if strings.HasPrefix(entry.Path, ws.Path+"/") && len(ws.Path) > longest {
+			target, label, longest = filepath.Join(repoRoot, ws.Path), ws.Path, len(ws.Path)
+		}
+	}
+
+	if err := patch.Apply(target, patchFile); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Applied %s (backup %s) to %s\n", entry.Path, entry.Timestamp(), label)
+	return nil
+}
+
+// extractBackup returns a file path holding the backup content.
+// Archived backups are extracted to a temporary file removed by cleanup.
+func extractBackup(entry *backup.Entry) (string, func(), error) {
+	if !entry.Archived() {
+		return entry.File, func() {}, nil
+	}
+
+	content, err := backup.Read(*entry)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	tmp, err := os.CreateTemp("", "multirepo-restore-*-"+filepath.Base(entry.Path))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer tmp.Close()
+
+	if _, err := tmp.Write(content); err != nil {
+		os.Remove(tmp.Name())
+		return "", nil, fmt.Errorf("failed to write temp file: %w", err)
+	}
+	return tmp.Name(), func() { os.Remove(tmp.Name()) }, nil
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yejune/git-multirepo/internal/backup"
+	"github.com/yejune/git-multirepo/internal/patch"
+)
+
+// resetRestoreFlags restores restore flags to their defaults
+func resetRestoreFlags() {
+	restoreDate = ""
+	restoreKind = ""
+}
+
+func TestRestore_ListShowApply(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer resetRestoreFlags()
+
+	cloneBranch = ""
+	runClone(cloneCmd, []string{setupRemoteRepo(t), "packages/app"})
+
+	configPath := filepath.Join(dir, "packages/app", "config.json")
+	backupDir := filepath.Join(dir, ".multirepos", "backup")
+	os.WriteFile(configPath, []byte(`{"port": 8080}`), 0644)
+	if err := backup.CreateFileBackup(configPath, backupDir, dir); err != nil {
+		t.Fatalf("CreateFileBackup failed: %v", err)
+	}
+	os.WriteFile(configPath, []byte(`{"port": 80}`), 0644)
+
+	t.Run("list", func(t *testing.T) {
+		output := captureOutput(func() {
+			if err := runRestoreList(restoreListCmd, []string{"packages/app"}); err != nil {
+				t.Fatalf("restore list failed: %v", err)
+			}
+		})
+		if !strings.Contains(output, "modified") || !strings.Contains(output, "packages/app/config.json@") {
+			t.Errorf("list should show the backup, got: %s", output)
+		}
+
+		output = captureOutput(func() { runRestoreList(restoreListCmd, []string{"other"}) })
+		if !strings.Contains(output, "No backups found") {
+			t.Errorf("list should filter by path, got: %s", output)
+		}
+	})
+
+	t.Run("show", func(t *testing.T) {
+		output := captureOutput(func() {
+			if err := runRestoreShow(restoreShowCmd, []string{"packages/app/config.json"}); err != nil {
+				t.Fatalf("restore show failed: %v", err)
+			}
+		})
+		if !strings.Contains(output, `{"port": 8080}`) {
+			t.Errorf("show should print backup content, got: %s", output)
+		}
+	})
+
+	t.Run("diff", func(t *testing.T) {
+		output := captureOutput(func() {
+			if err := runRestoreDiff(restoreDiffCmd, []string{"packages/app/config.json"}); err != nil {
+				t.Fatalf("restore diff failed: %v", err)
+			}
+		})
+		if !strings.Contains(output, `-{"port": 8080}`) || !strings.Contains(output, `+{"port": 80}`) {
+			t.Errorf("diff should compare backup with current file, got: %s", output)
+		}
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		captureOutput(func() {
+			if err := runRestoreApply(restoreApplyCmd, []string{"packages/app/config.json"}); err != nil {
+				t.Fatalf("restore apply failed: %v", err)
+			}
+		})
+		content, _ := os.ReadFile(configPath)
+		if string(content) != `{"port": 8080}` {
+			t.Errorf("apply should restore backup content, got %q", content)
+		}
+	})
+}
+
+func TestRestore_ApplyPatchBackup(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	cloneBranch = ""
+	runClone(cloneCmd, []string{setupRemoteRepo(t), "packages/lib"})
+	wsPath := filepath.Join(dir, "packages/lib")
+
+	// Save local README change as a patch the way pull does, then discard it
+	os.WriteFile(filepath.Join(wsPath, "README.md"), []byte("# Local edit\n"), 0644)
+	patchPath := filepath.Join(dir, ".multirepos", "patches", "packages/lib", "README.md.patch")
+	if err := patch.Create(wsPath, "README.md", patchPath); err != nil {
+		t.Fatalf("patch.Create failed: %v", err)
+	}
+	if err := backup.CreatePatchBackup(patchPath, filepath.Join(dir, ".multirepos", "backup")); err != nil {
+		t.Fatalf("CreatePatchBackup failed: %v", err)
+	}
+	exec.Command("git", "-C", wsPath, "checkout", "--", "README.md").Run()
+
+	output := captureOutput(func() {
+		if err := runRestoreApply(restoreApplyCmd, []string{"packages/lib/README.md.patch"}); err != nil {
+			t.Fatalf("restore apply failed: %v", err)
+		}
+	})
+
+	content, _ := os.ReadFile(filepath.Join(wsPath, "README.md"))
+	if string(content) != "# Local edit\n" {
+		t.Errorf("patch should be applied to the workspace, got %q\n%s", content, output)
+	}
+}
+
+func TestRestore_NoBackup(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	if err := runRestoreShow(restoreShowCmd, []string{"missing.json"}); err == nil {
+		t.Error("show should fail when there is no backup")
+	}
+}
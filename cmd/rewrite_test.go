@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yejune/git-multirepo/internal/git"
+	"github.com/yejune/git-multirepo/internal/manifest"
+)
+
+func TestRunSync_RewriteAndFallbacks(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	// The "server" holds lib.git, reachable through the rewrite rule only
+	server := t.TempDir()
+	exec.Command("git", "clone", "--bare", setupRemoteRepo(t), filepath.Join(server, "lib.git")).Run()
+	missing := filepath.Join(t.TempDir(), "missing.git")
+
+	m := &manifest.Manifest{
+		Rewrite: []manifest.RewriteRule{{URL: server + "/", InsteadOf: "https://git.example.com/"}},
+		Workspaces: []manifest.WorkspaceEntry{
+			{Path: "lib", Repo: "https://git.example.com/lib.git"},
+			{Path: "app", Repo: missing, Fallbacks: []string{"https://git.example.com/lib.git"}},
+		},
+	}
+	if err := manifest.Save(dir, m); err != nil {
+		t.Fatal(err)
+	}
+
+	output := captureOutput(func() {
+		if err := runSync(syncCmd, nil); err != nil {
+			t.Fatalf("sync failed: %v", err)
+		}
+	})
+
+	lib := filepath.Join(dir, "lib")
+	if !git.IsRepo(lib) {
+		t.Fatalf("lib should be cloned through the rewritten URL, got: %s", output)
+	}
+	if origin, _ := git.GetRemoteURL(lib); origin != server+"/lib.git" {
+		t.Errorf("origin of lib = %s, want the rewritten URL", origin)
+	}
+
+	app := filepath.Join(dir, "app")
+	if !git.IsRepo(app) {
+		t.Fatalf("app should be cloned from its fallback, got: %s", output)
+	}
+	if !strings.Contains(output, "trying "+server+"/lib.git") {
+		t.Errorf("sync should report the fallback, got: %s", output)
+	}
+	// fetch and push keep using the repository itself
+	if origin, _ := git.GetRemoteURL(app); origin != missing {
+		t.Errorf("origin of app = %s, want %s", origin, missing)
+	}
+}
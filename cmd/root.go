@@ -27,13 +27,26 @@ while the parent project tracks the source files (but not .git).
 
 Commands:
   clone    Clone a new repository
+  add      Register an existing repository
   sync     Clone or pull all repositories
   list     List all registered repositories
   remove   Remove a repository
+  move     Move a repository to another path
   status   Show repository status
   pull     Pull repository changes
+  commit   Commit changes across repositories
+  push     Push repositories with unpushed commits
   reset    Reset repository state
+  restore  Browse and recover backups
+  undo     Undo the last manifest-changing command
+  backup   Prune backups by retention settings
+  cache    Manage the shared mirror cache for clones
+  doctor   Check the manifest and workspaces for problems
   branch   Manage repository branches
+  lock     Record workspace commits in a lock file
+  foreach  Run a command in every repository
+  import-submodules Convert git submodules into workspaces
+  export-submodules Convert workspaces into git submodules
   selfupdate Update git-multirepo to latest version`,
 	Version: Version,
 	Args:    cobra.MaximumNArgs(2),
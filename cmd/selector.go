@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/yejune/git-multirepo/internal/common"
+	"github.com/yejune/git-multirepo/internal/manifest"
+)
+
+// addGroupFlag registers --group on a command that accepts workspace paths
+func addGroupFlag(c *cobra.Command, groups *[]string) {
+	c.Flags().StringSliceVarP(groups, "group", "g", nil, "Only repositories in this group (repeatable)")
+}
+
+// selectWorkspaces returns the workspaces selected by path/glob arguments and groups
+func selectWorkspaces(ctx *common.WorkspaceContext, args, groups []string) ([]manifest.WorkspaceEntry, error) {
+	return ctx.SelectWorkspaces(common.Selector{Patterns: args, Groups: groups})
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yejune/git-multirepo/internal/manifest"
+)
+
+// setupMonorepoRemote creates a remote with apps/api and apps/web directories,
+// returned as a file:// URL so that --depth and --filter are honoured
+func setupMonorepoRemote(t *testing.T) string {
+	t.Helper()
+	dir := setupRemoteRepo(t)
+	exec.Command("git", "-C", dir, "config", "uploadpack.allowFilter", "true").Run()
+	for _, file := range []string{"apps/api/main.go", "apps/web/index.html"} {
+		os.MkdirAll(filepath.Join(dir, filepath.Dir(file)), 0755)
+		os.WriteFile(filepath.Join(dir, file), []byte(file), 0644)
+		exec.Command("git", "-C", dir, "add", ".").Run()
+		exec.Command("git", "-C", dir, "commit", "-m", "Add "+file).Run()
+	}
+	return "file://" + filepath.ToSlash(dir)
+}
+
+func TestRunSync_ShallowSparseWorkspace(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer func() { statusOutput = outputText }()
+
+	writeTestManifest(t, dir, manifest.WorkspaceEntry{
+		Path:   "mono",
+		Repo:   setupMonorepoRemote(t),
+		Depth:  1,
+		Filter: "blob:none",
+		Sparse: []string{"apps/api"},
+	})
+
+	captureOutput(func() {
+		if err := runSync(syncCmd, nil); err != nil {
+			t.Fatalf("sync failed: %v", err)
+		}
+	})
+
+	ws := filepath.Join(dir, "mono")
+	if _, err := os.Stat(filepath.Join(ws, "apps/api/main.go")); err != nil {
+		t.Error("sparse directory should be checked out")
+	}
+	if _, err := os.Stat(filepath.Join(ws, "apps/web")); !os.IsNotExist(err) {
+		t.Error("apps/web should not be checked out")
+	}
+
+	// Changing the sparse directories in the manifest is applied by the next sync
+	m, _ := manifest.Load(dir)
+	m.Find("mono").Sparse = []string{"apps/web/", "apps/api"}
+	manifest.Save(dir, m)
+
+	output := captureOutput(func() {
+		if err := runSync(syncCmd, nil); err != nil {
+			t.Fatalf("sync failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "Sparse checkout: apps/web/, apps/api") {
+		t.Errorf("sync should update the sparse checkout, got: %s", output)
+	}
+	if _, err := os.Stat(filepath.Join(ws, "apps/web/index.html")); err != nil {
+		t.Error("apps/web should be checked out after the update")
+	}
+
+	// git lists them sorted and cleaned; that is no change
+	output = captureOutput(func() { runSync(syncCmd, nil) })
+	if strings.Contains(output, "Sparse checkout:") {
+		t.Errorf("an unchanged sparse checkout should not be set again, got: %s", output)
+	}
+
+	statusOutput = outputJSON
+	output = captureOutput(func() {
+		if err := runStatus(statusCmd, nil); err != nil {
+			t.Fatalf("status failed: %v", err)
+		}
+	})
+
+	var report workspacesReport
+	if err := json.Unmarshal([]byte(output), &report); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, output)
+	}
+	got := report.Workspaces[0]
+	if !got.Shallow || got.Filter != "blob:none" || strings.Join(got.Sparse, ",") != "apps/api,apps/web" {
+		t.Errorf("status should report shallow, partial and sparse clone, got %+v", got)
+	}
+}
+
+func TestRunClone_RecordsCloneOptions(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer func() { cloneDepth = 0; cloneSparse = nil }()
+
+	cloneBranch = ""
+	cloneDepth = 1
+	cloneSparse = []string{"apps/web"}
+	captureOutput(func() {
+		if err := runClone(cloneCmd, []string{setupMonorepoRemote(t), "mono"}); err != nil {
+			t.Fatalf("clone failed: %v", err)
+		}
+	})
+
+	m, _ := manifest.Load(dir)
+	ws := m.Find("mono")
+	if ws == nil || ws.Depth != 1 || strings.Join(ws.Sparse, ",") != "apps/web" {
+		t.Fatalf("clone options should be recorded in the manifest, got %+v", ws)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "mono", "apps/api")); !os.IsNotExist(err) {
+		t.Error("apps/api should not be checked out")
+	}
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -11,16 +12,22 @@ import (
 	"github.com/yejune/git-multirepo/internal/common"
 	"github.com/yejune/git-multirepo/internal/git"
 	"github.com/yejune/git-multirepo/internal/i18n"
+	"github.com/yejune/git-multirepo/internal/manifest"
 )
 
 var statusCmd = &cobra.Command{
-	Use:   "status [path]",
+	Use:   "status [path...]",
 	Short: "Show detailed status of repositories",
 	Long: `Display comprehensive status information for each repository:
 
 Examples:
   git multirepo status              # Show status for all repositories
   git multirepo status apps/admin   # Show status for specific repository
+  git multirepo status 'apps/*'     # Paths may be directories or glob patterns
+  git multirepo status -g backend   # Show status for repositories in group "backend"
+  git multirepo status -j 8         # Check up to 8 repositories in parallel
+  git multirepo status -o json      # Machine-readable output (json or yaml)
+  git multirepo status -r           # Include workspaces of nested manifests
 
 For each repository, shows:
   1. Local Status (modified, untracked, staged files)
@@ -29,21 +36,52 @@ For each repository, shows:
 	RunE: runStatus,
 }
 
+var (
+	statusJobs      int
+	statusOutput    string
+	statusGroups    []string
+	statusRecursive bool
+)
+
 func init() {
+	statusCmd.Flags().IntVarP(&statusJobs, "jobs", "j", common.DefaultJobs(), "Number of workspaces to check in parallel")
+	statusCmd.Flags().StringVarP(&statusOutput, "output", "o", outputText, "Output format: text, json or yaml")
+	statusCmd.Flags().BoolVarP(&statusRecursive, "recursive", "r", false, "Also show workspaces of nested .git.multirepos, depth-first")
+	addGroupFlag(statusCmd, &statusGroups)
 	rootCmd.AddCommand(statusCmd)
 }
 
+// statusPrinter writes colored status output to out
+type statusPrinter struct {
+	out io.Writer
+}
+
+func (p statusPrinter) cyan(format string, a ...interface{}) {
+	color.New(color.FgCyan, color.Bold).Fprintf(p.out, format, a...)
+}
+func (p statusPrinter) blue(format string, a ...interface{}) {
+	color.New(color.FgBlue, color.Bold).Fprintf(p.out, format, a...)
+}
+func (p statusPrinter) green(format string, a ...interface{}) {
+	color.New(color.FgGreen).Fprintf(p.out, format, a...)
+}
+func (p statusPrinter) yellow(format string, a ...interface{}) {
+	color.New(color.FgYellow).Fprintf(p.out, format, a...)
+}
+func (p statusPrinter) red(format string, a ...interface{}) {
+	color.New(color.FgRed, color.Bold).Fprintf(p.out, format, a...)
+}
+func (p statusPrinter) gray(format string, a ...interface{}) {
+	color.New(color.Faint).Fprintf(p.out, format, a...)
+}
+func (p statusPrinter) newline() {
+	fmt.Fprintln(p.out)
+}
+
 func runStatus(cmd *cobra.Command, args []string) error {
-	// Define color printers
-	// Use Fprintf to always print to the correct stdout
-	var (
-		printCyan   = func(format string, a ...interface{}) { color.New(color.FgCyan, color.Bold).Fprintf(os.Stdout, format, a...) }
-		printBlue   = func(format string, a ...interface{}) { color.New(color.FgBlue, color.Bold).Fprintf(os.Stdout, format, a...) }
-		printGreen  = func(format string, a ...interface{}) { color.New(color.FgGreen).Fprintf(os.Stdout, format, a...) }
-		printYellow = func(format string, a ...interface{}) { color.New(color.FgYellow).Fprintf(os.Stdout, format, a...) }
-		printRed    = func(format string, a ...interface{}) { color.New(color.FgRed, color.Bold).Fprintf(os.Stdout, format, a...) }
-		printGray   = func(format string, a ...interface{}) { color.New(color.Faint).Fprintf(os.Stdout, format, a...) }
-	)
+	if err := validateOutputFormat(statusOutput); err != nil {
+		return err
+	}
 
 	ctx, err := common.LoadWorkspaceContext()
 	if err != nil {
@@ -51,151 +89,310 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	}
 
 	if len(ctx.Manifest.Workspaces) == 0 {
+		if statusOutput != outputText {
+			return writeStructured(os.Stdout, statusOutput, workspacesReport{Workspaces: []workspaceReport{}})
+		}
 		fmt.Println(i18n.T("no_subs_registered"))
 		return nil
 	}
 
 	// Filter workspaces if path argument provided
-	workspacesToProcess, err := ctx.FilterWorkspaces(args)
+	workspacesToProcess, err := selectWorkspaces(ctx, args, statusGroups)
 	if err != nil {
-		return fmt.Errorf(i18n.T("sub_not_found", args[0]))
+		return err
 	}
 
-	for idx, ws := range workspacesToProcess {
-		if idx > 0 {
-			// Add separator between workspaces
-			printGray("%s\n", strings.Repeat("─", 80))
-			fmt.Println()
+	// Machine-readable output: collect reports concurrently, then print one document
+	if statusOutput != outputText {
+		var nested *nestedManifests
+		if statusRecursive {
+			nested = newNestedManifests(ctx.RepoRoot)
 		}
+		reports := collectStatusReports(ctx, workspacesToProcess, nested)
+		return writeStructured(os.Stdout, statusOutput, workspacesReport{Workspaces: reports})
+	}
 
-		fullPath := filepath.Join(ctx.RepoRoot, ws.Path)
+	printStatus(ctx, workspacesToProcess)
+	if statusRecursive {
+		return forEachNestedManifest(ctx, workspacesToProcess, func(nested *common.WorkspaceContext, path string) error {
+			if len(nested.Manifest.Workspaces) == 0 {
+				fmt.Println(i18n.T("no_subs_registered"))
+				return nil
+			}
+			printStatus(nested, nested.Manifest.Workspaces)
+			return nil
+		})
+	}
 
-		// Workspace header
-		printCyan("%s", ws.Path)
+	return nil
+}
 
-		if !git.IsRepo(fullPath) {
-			printRed(" %s\n", i18n.T("not_cloned"))
-			fmt.Println()
-			printBlue("  %s\n", i18n.T("how_to_resolve"))
-			printGray("    git multirepo sync\n")
-			fmt.Println()
-			continue
-		}
+// printStatus prints the status of workspaces of one repository's manifest
+func printStatus(ctx *common.WorkspaceContext, workspaces []manifest.WorkspaceEntry) {
+	conflicts := unresolvedKeepConflicts(ctx.RepoRoot)
 
-		// Get current branch
-		branch, err := git.GetCurrentBranch(fullPath)
-		if err != nil {
-			branch = "unknown"
+	// Check workspaces concurrently; output is printed in manifest order
+	firstPath := workspaces[0].Path
+	ctx.RunWorkspacesParallel(workspaces, statusJobs, os.Stdout, func(ws *manifest.WorkspaceEntry, fullPath string, out io.Writer) error {
+		p := statusPrinter{out: out}
+		if ws.Path != firstPath {
+			// Add separator between workspaces
+			p.gray("%s\n", strings.Repeat("─", 80))
+			p.newline()
 		}
-		printGray(" (%s)\n", branch)
-		fmt.Println()
+		printWorkspaceStatus(p, collectWorkspaceStatus(ctx.Manifest, ws, fullPath, conflicts[ws.Path]))
+		return nil
+	})
+}
 
-		// Section 1: Local Status
-		printBlue("  %s\n", i18n.T("local_status"))
+// collectStatusReports collects status reports of workspaces concurrently.
+// If nested is not nil, the reports of nested manifests are added to their
+// workspace's report, depth-first.
+func collectStatusReports(ctx *common.WorkspaceContext, workspaces []manifest.WorkspaceEntry, nested *nestedManifests) []workspaceReport {
+	conflicts := unresolvedKeepConflicts(ctx.RepoRoot)
 
-		// Get workspace status using unified pattern
-		status, err := git.GetWorkspaceStatus(fullPath, ws.Keep)
-		hasLocalChanges := false
-		if err != nil {
-			printRed("    Failed to get status: %v\n", err)
-		} else {
-			if len(status.ModifiedFiles) > 0 {
-				hasLocalChanges = true
-				printYellow("    %s\n", i18n.T("files_modified", len(status.ModifiedFiles)))
-				for _, file := range status.ModifiedFiles {
-					printGray("      - %s\n", file)
+	reports := make([]workspaceReport, len(workspaces))
+	index := make(map[string]int, len(workspaces))
+	for i, ws := range workspaces {
+		index[ws.Path] = i
+	}
+	ctx.RunWorkspacesParallel(workspaces, statusJobs, io.Discard, func(ws *manifest.WorkspaceEntry, fullPath string, out io.Writer) error {
+		reports[index[ws.Path]] = collectWorkspaceStatus(ctx.Manifest, ws, fullPath, conflicts[ws.Path])
+		return nil
+	})
+
+	if nested != nil {
+		for i, ws := range workspaces {
+			child, err := nested.enter(filepath.Join(ctx.RepoRoot, ws.Path))
+			if err != nil {
+				if reports[i].Error == "" {
+					reports[i].Error = err.Error()
 				}
+				continue
 			}
-
-			if len(status.UntrackedFiles) > 0 {
-				hasLocalChanges = true
-				printYellow("    %s\n", i18n.T("files_untracked", len(status.UntrackedFiles)))
-				for _, file := range status.UntrackedFiles {
-					printGray("      - %s\n", file)
-				}
+			if child != nil {
+				reports[i].Workspaces = collectStatusReports(child, child.Manifest.Workspaces, nested)
 			}
+		}
+	}
 
-			if len(status.StagedFiles) > 0 {
-				hasLocalChanges = true
-				printYellow("    %s\n", i18n.T("files_staged", len(status.StagedFiles)))
-				for _, file := range status.StagedFiles {
-					printGray("      - %s\n", file)
-				}
-			}
+	return reports
+}
 
-			if !hasLocalChanges {
-				printGreen("    %s\n", i18n.T("clean_working_tree"))
-			}
+// collectWorkspaceStatus gathers local and remote status of a workspace.
+// conflicts lists keep files left with conflict markers by pull.
+func collectWorkspaceStatus(m *manifest.Manifest, ws *manifest.WorkspaceEntry, fullPath string, conflicts []string) workspaceReport {
+	report := newWorkspaceReport(ws)
+	report.Conflicts = conflicts
+
+	if !git.IsRepo(fullPath) {
+		report.State = "not_cloned"
+		return report
+	}
+	report.Cloned = true
+
+	// Get current branch
+	branch, err := git.GetCurrentBranch(fullPath)
+	if err != nil {
+		branch = "unknown"
+	}
+	report.CurrentBranch = branch
+	report.TrackingBranch, _ = git.GetTrackingBranch(fullPath)
+	report.Shallow = git.IsShallow(fullPath)
+	report.Filter = git.PartialCloneFilter(fullPath)
+	report.Sparse, _ = git.SparseCheckoutDirs(fullPath)
+	report.Origin, report.RemoteDrift = originDrift(m, ws, fullPath)
+
+	// Get workspace status using unified pattern
+	status, err := git.GetWorkspaceStatus(fullPath, ws.Keep)
+	if err != nil {
+		report.State = "error"
+		report.Error = fmt.Sprintf("failed to get status: %v", err)
+	} else {
+		report.Local = &localReport{
+			Modified:         nonNil(status.ModifiedFiles),
+			Untracked:        nonNil(status.UntrackedFiles),
+			Staged:           nonNil(status.StagedFiles),
+			TotalUncommitted: status.TotalUncommitted,
+		}
+		if status.TotalUncommitted > 0 {
+			report.State = "modified"
+		} else {
+			report.State = "clean"
 		}
-		fmt.Println()
+	}
 
-		// Section 2: Remote Status
-		printBlue("  %s\n", i18n.T("remote_status"))
+	// Fetch once; the error is reported if nothing else is known about the remote
+	report.Remote = &remoteReport{}
+	if err := git.Fetch(fullPath); err != nil {
+		report.Remote.FetchError = err.Error()
+	}
+	report.Remote.Behind, _ = git.GetBehindCount(fullPath, branch)
+	report.Remote.Ahead, _ = git.GetAheadCount(fullPath, branch)
 
-		// Fetch from remote (suppress errors)
-		_ = git.Fetch(fullPath)
+	return report
+}
 
-		behindCount, _ := git.GetBehindCount(fullPath, branch)
-		aheadCount, _ := git.GetAheadCount(fullPath, branch)
+// checkoutLimits describes how a shallow, partial or sparse clone differs from a full one
+func checkoutLimits(report workspaceReport) []string {
+	var limits []string
+	if report.Shallow {
+		limits = append(limits, i18n.T("shallow_clone"))
+	}
+	if report.Filter != "" {
+		limits = append(limits, i18n.T("partial_clone", report.Filter))
+	}
+	if report.Sparse != nil {
+		limits = append(limits, i18n.T("sparse_checkout", strings.Join(report.Sparse, " ")))
+	}
+	return limits
+}
 
-		if behindCount > 0 {
-			printYellow("    %s\n", i18n.T("commits_behind", behindCount, branch))
+// printWorkspaceStatus prints local status, remote status and resolution steps for a workspace
+func printWorkspaceStatus(p statusPrinter, report workspaceReport) {
+	// Workspace header
+	p.cyan("%s", report.Path)
+
+	if !report.Cloned {
+		p.red(" %s\n", i18n.T("not_cloned"))
+		p.newline()
+		p.blue("  %s\n", i18n.T("how_to_resolve"))
+		p.gray("    git multirepo sync\n")
+		p.newline()
+		return
+	}
+
+	branch := report.CurrentBranch
+	p.gray(" (%s)", branch)
+	if limits := checkoutLimits(report); len(limits) > 0 {
+		p.yellow(" [%s]", strings.Join(limits, ", "))
+	}
+	p.newline()
+	p.newline()
+
+	if report.RemoteDrift {
+		if report.Origin == "" {
+			p.yellow("  %s\n", i18n.T("origin_missing", report.Repo))
+		} else {
+			p.yellow("  %s\n", i18n.T("origin_drift", report.Origin, report.Repo))
 		}
+		p.newline()
+	}
 
-		if aheadCount > 0 {
-			printYellow("    %s\n", i18n.T("commits_ahead", aheadCount))
+	// Section 1: Local Status
+	p.blue("  %s\n", i18n.T("local_status"))
+
+	local := report.Local
+	hasLocalChanges := false
+	if local == nil {
+		p.red("    %s\n", report.Error)
+		local = &localReport{}
+	} else {
+		if len(local.Modified) > 0 {
+			hasLocalChanges = true
+			p.yellow("    %s\n", i18n.T("files_modified", len(local.Modified)))
+			for _, file := range local.Modified {
+				p.gray("      - %s\n", file)
+			}
 		}
 
-		if behindCount == 0 && aheadCount == 0 {
-			printGreen("    %s\n", i18n.T("up_to_date"))
+		if len(local.Untracked) > 0 {
+			hasLocalChanges = true
+			p.yellow("    %s\n", i18n.T("files_untracked", len(local.Untracked)))
+			for _, file := range local.Untracked {
+				p.gray("      - %s\n", file)
+			}
 		}
 
-		// Check if remote branch exists
-		if behindCount == 0 && aheadCount == 0 {
-			// Try to verify remote branch exists
-			if err := git.Fetch(fullPath); err != nil {
-				printRed("    %s\n", i18n.T("cannot_fetch"))
+		if len(local.Staged) > 0 {
+			hasLocalChanges = true
+			p.yellow("    %s\n", i18n.T("files_staged", len(local.Staged)))
+			for _, file := range local.Staged {
+				p.gray("      - %s\n", file)
 			}
 		}
-		fmt.Println()
 
-		// Section 3: How to resolve
-		needsResolution := hasLocalChanges || behindCount > 0 || aheadCount > 0
+		if !hasLocalChanges {
+			p.green("    %s\n", i18n.T("clean_working_tree"))
+		}
+	}
 
-		if needsResolution {
-			printBlue("  %s\n", i18n.T("how_to_resolve"))
-			fmt.Println()
+	if len(report.Conflicts) > 0 {
+		p.red("    %s\n", i18n.T("keep_conflicts", len(report.Conflicts)))
+		for _, file := range report.Conflicts {
+			p.gray("      - %s\n", file)
+		}
+	}
+	p.newline()
 
-			if hasLocalChanges {
-				printYellow("    %s\n", i18n.T("resolve_commit"))
-				printGray("       cd %s\n", ws.Path)
-				if len(status.StagedFiles) > 0 || len(status.ModifiedFiles) > 0 {
-					printGray("       git add .\n")
-					printGray("       git commit -m \"your message\"\n")
-				}
-				if len(status.UntrackedFiles) > 0 {
-					printGray("       %s\n", i18n.T("resolve_or_gitignore"))
-				}
-				fmt.Println()
-			}
+	// Section 2: Remote Status
+	p.blue("  %s\n", i18n.T("remote_status"))
 
-			if behindCount > 0 {
-				printYellow("    %s\n", i18n.T("resolve_pull"))
-				printGray("       git multirepo pull %s\n", ws.Path)
-				fmt.Println()
-			}
+	behindCount := report.Remote.Behind
+	aheadCount := report.Remote.Ahead
 
-			if aheadCount > 0 {
-				printYellow("    %s\n", i18n.T("resolve_push"))
-				printGray("       cd %s\n", ws.Path)
-				printGray("       git push\n")
-				fmt.Println()
-			}
-		} else {
-			printGreen("  %s\n", i18n.T("no_action_needed"))
-			fmt.Println()
+	if behindCount > 0 {
+		p.yellow("    %s\n", i18n.T("commits_behind", behindCount, branch))
+	}
+
+	if aheadCount > 0 {
+		p.yellow("    %s\n", i18n.T("commits_ahead", aheadCount))
+	}
+
+	if behindCount == 0 && aheadCount == 0 {
+		p.green("    %s\n", i18n.T("up_to_date"))
+		if report.Remote.FetchError != "" {
+			p.red("    %s\n", i18n.T("cannot_fetch"))
 		}
 	}
+	p.newline()
 
-	return nil
+	// Section 3: How to resolve
+	needsResolution := hasLocalChanges || behindCount > 0 || aheadCount > 0 || len(report.Conflicts) > 0 || report.RemoteDrift
+
+	if needsResolution {
+		p.blue("  %s\n", i18n.T("how_to_resolve"))
+		p.newline()
+
+		if report.RemoteDrift {
+			p.yellow("    %s\n", i18n.T("resolve_remote"))
+			p.gray("       git multirepo sync --update-remotes\n")
+			p.gray("       git multirepo sync --adopt-remotes\n")
+			p.newline()
+		}
+
+		if len(report.Conflicts) > 0 {
+			p.yellow("    %s\n", i18n.T("resolve_keep_conflicts"))
+			p.gray("       git multirepo sync\n")
+			p.newline()
+		}
+
+		if hasLocalChanges {
+			p.yellow("    %s\n", i18n.T("resolve_commit"))
+			p.gray("       cd %s\n", report.Path)
+			if len(local.Staged) > 0 || len(local.Modified) > 0 {
+				p.gray("       git add .\n")
+				p.gray("       git commit -m \"your message\"\n")
+			}
+			if len(local.Untracked) > 0 {
+				p.gray("       %s\n", i18n.T("resolve_or_gitignore"))
+			}
+			p.newline()
+		}
+
+		if behindCount > 0 {
+			p.yellow("    %s\n", i18n.T("resolve_pull"))
+			p.gray("       git multirepo pull %s\n", report.Path)
+			p.newline()
+		}
+
+		if aheadCount > 0 {
+			p.yellow("    %s\n", i18n.T("resolve_push"))
+			p.gray("       git multirepo push --all %s\n", report.Path)
+			p.newline()
+		}
+	} else {
+		p.green("  %s\n", i18n.T("no_action_needed"))
+		p.newline()
+	}
 }
@@ -0,0 +1,367 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yejune/git-multirepo/internal/common"
+	"github.com/yejune/git-multirepo/internal/git"
+	"github.com/yejune/git-multirepo/internal/hooks"
+	"github.com/yejune/git-multirepo/internal/manifest"
+)
+
+var exportSubmodulesGroups []string
+
+var importSubmodulesCmd = &cobra.Command{
+	Use:   "import-submodules [path...]",
+	Short: "Convert git submodules into workspaces",
+	Long: `Convert the submodules listed in .gitmodules (all, or those matching the
+given paths or globs) into workspaces.
+
+Each submodule becomes a standalone repository: its git directory moves from
+.git/modules into its own .git, so its files, branches and uncommitted changes
+stay as they are. It is removed from .gitmodules, .git/config and the index,
+and added to .git.multirepos with its URL (relative URLs resolved), branch and
+shallow setting. The commit the parent recorded for it is pinned in
+.git.multirepos.lock. Submodules that are not checked out are registered too;
+sync clones them.
+
+The changes to .gitmodules and the index are staged; add the workspace files
+and commit.
+
+Examples:
+  git multirepo import-submodules
+  git multirepo import-submodules libs/core 'vendor/*'`,
+	RunE: runImportSubmodules,
+}
+
+var exportSubmodulesCmd = &cobra.Command{
+	Use:   "export-submodules [path...]",
+	Short: "Convert workspaces into git submodules",
+	Long: `Convert workspaces (all, or those selected by path, glob or --group) into
+git submodules, for handing the project to submodule users.
+
+Each workspace is added to .gitmodules with its repo, branch and shallow
+setting, and the parent records its current HEAD commit as a gitlink instead
+of tracking its files. It is removed from .git.multirepos, the lock file and
+.gitignore, and loses the post-commit hook. Its .git directory stays where it
+is. Keep files, sparse directories, fallbacks and partial clone filters have no
+submodule equivalent and are dropped.
+
+Workspaces must be cloned and have a repo; their commits should be pushed so
+submodule users can fetch them. The changes to .gitmodules and the index are
+staged; commit them.
+
+Examples:
+  git multirepo export-submodules
+  git multirepo export-submodules packages/lib
+  git multirepo export-submodules -g vendor`,
+	RunE: runExportSubmodules,
+}
+
+func init() {
+	addGroupFlag(exportSubmodulesCmd, &exportSubmodulesGroups)
+	rootCmd.AddCommand(importSubmodulesCmd)
+	rootCmd.AddCommand(exportSubmodulesCmd)
+}
+
+func runImportSubmodules(cmd *cobra.Command, args []string) error {
+	ctx, err := common.LoadWorkspaceContext()
+	if err != nil {
+		return err
+	}
+
+	subs, err := git.ListSubmodules(ctx.RepoRoot)
+	if err != nil {
+		return fmt.Errorf("failed to read .gitmodules: %w", err)
+	}
+	subs, err = selectSubmodules(subs, args)
+	if err != nil {
+		return err
+	}
+	if len(subs) == 0 {
+		fmt.Println("No submodules found in .gitmodules.")
+		return nil
+	}
+
+	entry := beginJournal(ctx.RepoRoot, "import-submodules", ctx.Manifest)
+	defer entry.Finish()
+
+	var imported []string
+	failed := 0
+	for _, sub := range subs {
+		if err := importSubmodule(ctx, sub); err != nil {
+			fmt.Printf("✗ %s: %v\n", sub.Path, err)
+			failed++
+			continue
+		}
+		imported = append(imported, sub.Path)
+	}
+
+	if len(imported) > 0 {
+		fmt.Printf("\nℹ Stage the workspace files and commit: git add -A %s\n", strings.Join(append(conversionFiles(ctx.RepoRoot), imported...), " "))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d submodule(s) could not be imported", failed)
+	}
+	return nil
+}
+
+// importSubmodule converts one submodule into a workspace
+func importSubmodule(ctx *common.WorkspaceContext, sub git.Submodule) error {
+	if err := checkWorkspacePath(ctx.Manifest, sub.Path, ""); err != nil {
+		return err
+	}
+
+	url, err := git.SubmoduleURL(ctx.RepoRoot, sub)
+	if err != nil {
+		return fmt.Errorf("failed to resolve URL: %w", err)
+	}
+	commit, _ := git.GetGitlink(ctx.RepoRoot, sub.Path)
+
+	fullPath := filepath.Join(ctx.RepoRoot, sub.Path)
+	_, statErr := os.Lstat(filepath.Join(fullPath, ".git"))
+	checkedOut := statErr == nil
+
+	ws := manifest.WorkspaceEntry{Path: sub.Path, Repo: url}
+	if sub.Branch != "" && sub.Branch != "." {
+		ws.Branch = sub.Branch
+	}
+	if sub.Shallow {
+		ws.Depth = 1
+	}
+	if checkedOut {
+		if skipFiles, err := git.ListSkipWorktree(fullPath); err == nil && len(skipFiles) > 0 {
+			ws.Keep = skipFiles
+		}
+	}
+
+	// Registered first: a failure below leaves the submodule in place, and the
+	// workspace is unregistered again
+	updated, err := manifest.Update(ctx.RepoRoot, func(m *manifest.Manifest) error {
+		if m.Exists(ws.Path) {
+			return fmt.Errorf("repository already exists at %s", ws.Path)
+		}
+		m.Workspaces = append(m.Workspaces, ws)
+		if commit == "" {
+			return nil
+		}
+		lock, err := manifest.LoadLock(ctx.RepoRoot)
+		if err != nil {
+			return fmt.Errorf("failed to load lock file: %w", err)
+		}
+		lock.Set(manifest.LockEntry{Path: ws.Path, Commit: commit})
+		lock.SortByManifest(m)
+		return manifest.SaveLock(ctx.RepoRoot, lock)
+	})
+	if err != nil {
+		return err
+	}
+	ctx.Manifest = updated
+
+	if err := detachSubmodule(ctx.RepoRoot, sub, checkedOut); err != nil {
+		if updated, rerr := unregisterWorkspace(ctx.RepoRoot, ws.Path); rerr != nil {
+			fmt.Printf("⚠ Failed to remove %s from the manifest: %v\n", ws.Path, rerr)
+		} else {
+			ctx.Manifest = updated
+		}
+		return err
+	}
+
+	if err := git.AddToGitignore(ctx.RepoRoot, ws.Path); err != nil {
+		fmt.Printf("⚠ Failed to update .gitignore: %v\n", err)
+	}
+	if checkedOut {
+		if err := hooks.InstallWorkspaceHook(fullPath); err != nil {
+			fmt.Printf("⚠ Failed to install hook: %v\n", err)
+		}
+	}
+
+	fmt.Printf("✓ Imported submodule: %s\n", ws.Path)
+	fmt.Printf("  Repository: %s\n", ws.Repo)
+	if ws.Branch != "" {
+		fmt.Printf("  Branch: %s\n", ws.Branch)
+	}
+	if commit != "" {
+		fmt.Printf("  Locked at: %s\n", shortCommit(commit))
+	}
+	if !checkedOut {
+		fmt.Println("  Not checked out; 'git multirepo sync' clones it")
+	}
+	return nil
+}
+
+// detachSubmodule moves the git directory of a checked out submodule into it
+// and removes the submodule from the parent repository
+func detachSubmodule(repoRoot string, sub git.Submodule, checkedOut bool) error {
+	if checkedOut {
+		if err := git.EmbedGitDir(filepath.Join(repoRoot, sub.Path)); err != nil {
+			return fmt.Errorf("failed to move its git directory: %w", err)
+		}
+	}
+	if err := git.RemoveSubmodule(repoRoot, sub); err != nil {
+		return fmt.Errorf("failed to remove submodule: %w", err)
+	}
+	return nil
+}
+
+// selectSubmodules returns the submodules matching the path/glob patterns,
+// or all of them without patterns. A pattern matching none is an error.
+func selectSubmodules(subs []git.Submodule, patterns []string) ([]git.Submodule, error) {
+	if len(patterns) == 0 {
+		return subs, nil
+	}
+
+	var selected []git.Submodule
+	for _, sub := range subs {
+		for _, pattern := range patterns {
+			if common.MatchPath(pattern, sub.Path) {
+				selected = append(selected, sub)
+				break
+			}
+		}
+	}
+	for _, pattern := range patterns {
+		found := false
+		for _, sub := range selected {
+			if common.MatchPath(pattern, sub.Path) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no submodule matches %s", pattern)
+		}
+	}
+	return selected, nil
+}
+
+func runExportSubmodules(cmd *cobra.Command, args []string) error {
+	ctx, err := common.LoadWorkspaceContext()
+	if err != nil {
+		return err
+	}
+
+	workspaces, err := selectWorkspaces(ctx, args, exportSubmodulesGroups)
+	if err != nil {
+		return err
+	}
+	if len(workspaces) == 0 {
+		fmt.Println("No repositories to export.")
+		return nil
+	}
+
+	entry := beginJournal(ctx.RepoRoot, "export-submodules", ctx.Manifest)
+	defer entry.Finish()
+
+	exported, failed := 0, 0
+	for i := range workspaces {
+		if err := exportSubmodule(ctx, &workspaces[i]); err != nil {
+			fmt.Printf("✗ %s: %v\n", workspaces[i].Path, err)
+			failed++
+			continue
+		}
+		exported++
+	}
+
+	if exported > 0 {
+		fmt.Printf("\nℹ Commit the submodules: git add -A %s && git commit\n", strings.Join(conversionFiles(ctx.RepoRoot), " "))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d repository(ies) could not be exported", failed)
+	}
+	return nil
+}
+
+// exportSubmodule converts one workspace into a submodule
+func exportSubmodule(ctx *common.WorkspaceContext, ws *manifest.WorkspaceEntry) error {
+	if include := ws.IncludedFrom(); include != "" {
+		return fmt.Errorf("comes from include %s, export it there", include)
+	}
+	if ws.Repo == "" {
+		return fmt.Errorf("has no repo URL")
+	}
+	fullPath := filepath.Join(ctx.RepoRoot, ws.Path)
+	if !git.IsRepo(fullPath) {
+		return fmt.Errorf("not cloned (run 'git multirepo sync' first)")
+	}
+	commit, err := git.GetCurrentCommit(fullPath)
+	if err != nil {
+		return fmt.Errorf("has no commit: %w", err)
+	}
+
+	sub := git.Submodule{Name: ws.Path, Path: ws.Path, URL: ws.Repo, Branch: ws.Branch, Shallow: ws.Depth > 0}
+	if err := git.AddSubmodule(ctx.RepoRoot, sub, commit); err != nil {
+		return fmt.Errorf("failed to add submodule: %w", err)
+	}
+
+	updated, err := unregisterWorkspace(ctx.RepoRoot, ws.Path)
+	if err != nil {
+		return err
+	}
+	ctx.Manifest = updated
+
+	if err := git.RemoveFromGitignore(ctx.RepoRoot, ws.Path); err != nil {
+		fmt.Printf("⚠ Failed to update .gitignore: %v\n", err)
+	}
+	if err := hooks.UninstallWorkspaceHook(fullPath); err != nil {
+		fmt.Printf("⚠ Failed to remove hook: %v\n", err)
+	}
+
+	fmt.Printf("✓ Exported submodule: %s (%s)\n", ws.Path, shortCommit(commit))
+	var dropped []string
+	if len(ws.Keep) > 0 {
+		dropped = append(dropped, "keep files")
+	}
+	if len(ws.Sparse) > 0 {
+		dropped = append(dropped, "sparse")
+	}
+	if len(ws.Fallbacks) > 0 {
+		dropped = append(dropped, "fallbacks")
+	}
+	if ws.Filter != "" {
+		dropped = append(dropped, "filter")
+	}
+	if len(dropped) > 0 {
+		fmt.Printf("  ⚠ Dropped (no submodule equivalent): %s\n", strings.Join(dropped, ", "))
+	}
+	if !git.IsOnRemote(fullPath, commit) {
+		fmt.Println("  ⚠ The commit is on no remote branch; push it so submodule users can fetch it")
+	}
+	return nil
+}
+
+// unregisterWorkspace removes the workspace at path from the manifest and the
+// lock file
+func unregisterWorkspace(repoRoot, path string) (*manifest.Manifest, error) {
+	return manifest.Update(repoRoot, func(m *manifest.Manifest) error {
+		m.Remove(path)
+		if !manifest.LockExists(repoRoot) {
+			return nil
+		}
+		lock, err := manifest.LoadLock(repoRoot)
+		if err != nil {
+			return fmt.Errorf("failed to load lock file: %w", err)
+		}
+		if lock.Find(path) == nil {
+			return nil
+		}
+		lock.SortByManifest(m)
+		return manifest.SaveLock(repoRoot, lock)
+	})
+}
+
+// conversionFiles returns the files of the parent repository that importing
+// or exporting submodules changes, that exist or are tracked
+func conversionFiles(repoRoot string) []string {
+	var files []string
+	for _, file := range []string{".gitmodules", ".gitignore", manifest.FileName, manifest.LockFileName} {
+		if _, err := os.Stat(filepath.Join(repoRoot, file)); err == nil || git.IsTracked(repoRoot, file) {
+			files = append(files, file)
+		}
+	}
+	return files
+}
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yejune/git-multirepo/internal/git"
+	"github.com/yejune/git-multirepo/internal/hooks"
+	"github.com/yejune/git-multirepo/internal/journal"
+	"github.com/yejune/git-multirepo/internal/manifest"
+)
+
+// addTestSubmodule adds remote as a submodule at path of the repository at dir
+func addTestSubmodule(t *testing.T, dir, remote, path string) {
+	t.Helper()
+	cmd := exec.Command("git", "-C", dir, "-c", "protocol.file.allow=always", "submodule", "add", "-q", remote, path)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git submodule add failed: %v\n%s", err, out)
+	}
+}
+
+func TestSubmodules_ImportAndExport(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	remote := setupRemoteRepo(t)
+	addTestSubmodule(t, dir, remote, "libs/core")
+	addTestSubmodule(t, dir, remote, "vendor/x")
+	exec.Command("git", "-C", dir, "config", "-f", ".gitmodules", "submodule.libs/core.branch", "main").Run()
+	exec.Command("git", "-C", dir, "commit", "-qam", "Add submodules").Run()
+	exec.Command("git", "-C", dir, "submodule", "deinit", "-q", "-f", "vendor/x").Run()
+	core := filepath.Join(dir, "libs", "core")
+	commit, _ := git.GetCurrentCommit(core)
+
+	if err := runImportSubmodules(importSubmodulesCmd, []string{"missing"}); err == nil {
+		t.Error("a pattern matching no submodule should fail")
+	}
+
+	output := captureOutput(func() {
+		if err := runImportSubmodules(importSubmodulesCmd, nil); err != nil {
+			t.Fatalf("import-submodules failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "✓ Imported submodule: libs/core") || !strings.Contains(output, "Not checked out") {
+		t.Errorf("unexpected output: %s", output)
+	}
+
+	if info, err := os.Stat(filepath.Join(core, ".git")); err != nil || !info.IsDir() {
+		t.Fatal("libs/core should have its own .git directory")
+	}
+	if head, err := git.GetCurrentCommit(core); err != nil || head != commit {
+		t.Errorf("standalone repository should work and keep its commit, got %q, %v", head, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".gitmodules")); !os.IsNotExist(err) {
+		t.Error(".gitmodules should be removed")
+	}
+	if _, err := git.GetGitlink(dir, "libs/core"); err == nil {
+		t.Error("gitlink should be removed from the index")
+	}
+	if !hooks.IsWorkspaceHookInstalled(core) {
+		t.Error("workspace hook should be installed")
+	}
+
+	m, _ := manifest.Load(dir)
+	ws := m.Find("libs/core")
+	if len(m.Workspaces) != 2 || ws == nil || ws.Repo != remote || ws.Branch != "main" || m.Find("vendor/x") == nil {
+		t.Fatalf("unexpected workspaces: %+v", m.Workspaces)
+	}
+	lock, _ := manifest.LoadLock(dir)
+	if entry := lock.Find("libs/core"); entry == nil || entry.Commit != commit || lock.Find("vendor/x") == nil {
+		t.Errorf("submodule commits should be locked, got %+v", lock.Workspaces)
+	}
+	gitignore, _ := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	if !strings.Contains(string(gitignore), "libs/core/.git/") {
+		t.Errorf(".gitignore should ignore the workspace's .git, got:\n%s", gitignore)
+	}
+	if entries, _ := journal.List(dir); len(entries) == 0 || entries[0].Command != "import-submodules" {
+		t.Error("import-submodules should be recorded for undo")
+	}
+
+	// And back: libs/core only; vendor/x is not cloned
+	captureOutput(func() {
+		if err := runExportSubmodules(exportSubmodulesCmd, []string{"vendor/x"}); err == nil {
+			t.Error("exporting a workspace that is not cloned should fail")
+		}
+	})
+	output = captureOutput(func() {
+		if err := runExportSubmodules(exportSubmodulesCmd, []string{"libs/core"}); err != nil {
+			t.Fatalf("export-submodules failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "✓ Exported submodule: libs/core") {
+		t.Errorf("unexpected output: %s", output)
+	}
+
+	subs, _ := git.ListSubmodules(dir)
+	if len(subs) != 1 || subs[0].Path != "libs/core" || subs[0].URL != remote || subs[0].Branch != "main" {
+		t.Errorf("unexpected .gitmodules: %+v", subs)
+	}
+	if gitlink, err := git.GetGitlink(dir, "libs/core"); err != nil || gitlink != commit {
+		t.Errorf("index should record the gitlink, got %q, %v", gitlink, err)
+	}
+	if out, err := exec.Command("git", "-C", dir, "submodule", "status", "libs/core").CombinedOutput(); err != nil || !strings.Contains(string(out), commit) {
+		t.Errorf("git submodule status should work, got %s (%v)", out, err)
+	}
+	if hooks.IsWorkspaceHookInstalled(core) {
+		t.Error("workspace hook should be removed")
+	}
+	m, _ = manifest.Load(dir)
+	if m.Exists("libs/core") || !m.Exists("vendor/x") {
+		t.Errorf("only libs/core should leave the manifest, got %+v", m.Workspaces)
+	}
+	if lock, _ := manifest.LoadLock(dir); lock.Find("libs/core") != nil {
+		t.Error("lock entry should be removed")
+	}
+	gitignore, _ = os.ReadFile(filepath.Join(dir, ".gitignore"))
+	if strings.Contains(string(gitignore), "libs/core/.git/") {
+		t.Errorf(".gitignore entry should be removed, got:\n%s", gitignore)
+	}
+	if entries, _ := journal.List(dir); len(entries) == 0 || entries[0].Command != "export-submodules" {
+		t.Error("export-submodules should be recorded for undo")
+	}
+}
+
+func TestSubmodules_ImportFailureKeepsSubmodule(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	remote := setupRemoteRepo(t)
+	addTestSubmodule(t, dir, remote, "libs/core")
+	exec.Command("git", "-C", dir, "commit", "-qam", "Add submodule").Run()
+
+	tests := []struct {
+		name string
+		file string // Blocks the import
+	}{
+		{"unreadable lock file", manifest.LockFileName},
+		{"locked index", filepath.Join(".git", "index.lock")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocker := filepath.Join(dir, tt.file)
+			os.WriteFile(blocker, []byte("{not yaml"), 0644)
+			captureOutput(func() {
+				if err := runImportSubmodules(importSubmodulesCmd, nil); err == nil {
+					t.Error("import should fail")
+				}
+			})
+			os.Remove(blocker)
+
+			if m, _ := manifest.Load(dir); m.Exists("libs/core") {
+				t.Error("a failed import should not leave the workspace in the manifest")
+			}
+			if _, err := git.GetGitlink(dir, "libs/core"); err != nil {
+				t.Errorf("gitlink should be kept: %v", err)
+			}
+			if subs, _ := git.ListSubmodules(dir); len(subs) != 1 {
+				t.Errorf(".gitmodules should be kept, got %+v", subs)
+			}
+			if _, err := git.GetCurrentCommit(filepath.Join(dir, "libs", "core")); err != nil {
+				t.Errorf("the submodule should still work: %v", err)
+			}
+		})
+	}
+}
@@ -1,15 +1,19 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/yejune/git-multirepo/internal/backup"
 	"github.com/yejune/git-multirepo/internal/common"
+	"github.com/yejune/git-multirepo/internal/discover"
 	"github.com/yejune/git-multirepo/internal/git"
 	"github.com/yejune/git-multirepo/internal/hooks"
 	"github.com/yejune/git-multirepo/internal/i18n"
@@ -27,12 +31,56 @@ var syncCmd = &cobra.Command{
   - Apply skip-worktree to specified files
   - Verify .gitignore entries for workspaces
 
+Workspaces are cloned and processed in parallel (--jobs); output for each
+workspace is printed in manifest order.
+
+If .git.multirepos.lock exists (see 'git multirepo lock'), newly cloned
+workspaces are checked out at their locked commit. Existing workspaces are
+never moved; one that is elsewhere is reported. With --locked, sync fails
+instead.
+
+The origin of each workspace is compared with its manifest repo (SSH and HTTPS
+URLs of the same repository match). A mismatch is reported; --update-remotes
+sets origin from the manifest, --adopt-remotes writes origin to the manifest.
+
+With --discover, git repositories below the project that are not in the
+manifest are registered first (see 'git multirepo add'). Without a manifest,
+this happens automatically. Directories ignored by .gitignore, symlinked
+directories and repositories without an origin remote are skipped; the
+'discover:' section of the manifest sets a depth limit, exclude globs and
+whether to scan those too. Worktrees and submodules are reported, not registered.
+
+Workspaces listed by 'include:' files are synced too; an include inside a
+workspace is read once that workspace is cloned. With --recursive, workspaces
+that have their own .git.multirepos are synced afterwards, depth-first.
+
 Examples:
-  git multirepo sync`,
+  git multirepo sync
+  git multirepo sync -j 4
+  git multirepo sync --locked
+  git multirepo sync --update-remotes
+  git multirepo sync --discover
+  git multirepo sync -r`,
 	RunE: runSync,
 }
 
+var (
+	syncJobs          int
+	syncLocked        bool
+	syncRecursive     bool
+	syncUpdateRemotes bool
+	syncAdoptRemotes  bool
+	syncDiscover      bool
+)
+
 func init() {
+	syncCmd.Flags().IntVarP(&syncJobs, "jobs", "j", common.DefaultJobs(), "Number of workspaces to process in parallel")
+	syncCmd.Flags().BoolVar(&syncLocked, "locked", false, "Require every workspace to match .git.multirepos.lock")
+	syncCmd.Flags().BoolVarP(&syncRecursive, "recursive", "r", false, "Also sync workspaces that have their own .git.multirepos, depth-first")
+	syncCmd.Flags().BoolVar(&syncUpdateRemotes, "update-remotes", false, "Set the origin of workspaces to the manifest repo")
+	syncCmd.Flags().BoolVar(&syncAdoptRemotes, "adopt-remotes", false, "Write the origin of workspaces to the manifest")
+	syncCmd.Flags().BoolVar(&syncDiscover, "discover", false, "Register nested repositories that are not in the manifest")
+	syncCmd.MarkFlagsMutuallyExclusive("update-remotes", "adopt-remotes")
 	rootCmd.AddCommand(syncCmd)
 }
 
@@ -43,8 +91,38 @@ func runSync(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	err = syncRepo(ctx)
+	if syncRecursive {
+		nestedErr := forEachNestedManifest(ctx, ctx.Manifest.Workspaces, func(nested *common.WorkspaceContext, path string) error {
+			return syncRepo(nested)
+		})
+		if err == nil {
+			err = nestedErr
+		}
+	}
+	return err
+}
+
+// syncRepo syncs the workspaces of one repository's manifest
+func syncRepo(ctx *common.WorkspaceContext) error {
+	lock, err := manifest.LoadLock(ctx.RepoRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load lock file: %w", err)
+	}
+	if syncLocked {
+		if err := checkLockCoverage(ctx.RepoRoot, ctx.Manifest, lock); err != nil {
+			return err
+		}
+	}
+
 	fmt.Println(i18n.T("syncing"))
 
+	entry := beginJournal(ctx.RepoRoot, "sync", ctx.Manifest)
+	defer entry.Finish()
+
+	// Forget keep file conflicts the user has resolved since the last pull
+	pruneKeepConflicts(ctx.RepoRoot)
+
 	// 1. Auto-install hooks
 	if !hooks.IsInstalled(ctx.RepoRoot) {
 		fmt.Println(i18n.T("installing_hooks"))
@@ -55,30 +133,47 @@ func runSync(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// 2. If no workspaces in manifest, scan for existing sub repos
-	if len(ctx.Manifest.Workspaces) == 0 {
-		fmt.Println(i18n.T("no_gitsubs_found"))
-		discovered, scanErr := scanForWorkspaces(ctx.RepoRoot)
+	// 2. If no workspaces in manifest (or with --discover), scan for unregistered sub repos
+	if firstScan := len(ctx.Manifest.Workspaces) == 0; firstScan || syncDiscover {
+		if firstScan {
+			fmt.Println(i18n.T("no_gitsubs_found"))
+		} else {
+			fmt.Println(i18n.T("discovering"))
+		}
+		discovered, scanErr := scanForWorkspaces(ctx, syncJobs)
 		if scanErr != nil {
 			return fmt.Errorf(i18n.T("failed_scan"), scanErr)
 		}
 
 		if len(discovered) > 0 {
-			// Create manifest from discovered workspaces
-			ctx.Manifest = &manifest.Manifest{
-				Workspaces: discovered,
-				Ignore:     ctx.Manifest.Ignore, // Preserve ignore patterns
-				Keep:       ctx.Manifest.Keep,   // Preserve keep files
+			// Record discovered workspaces; other settings are preserved
+			updated, err := manifest.Update(ctx.RepoRoot, func(m *manifest.Manifest) error {
+				for _, ws := range discovered {
+					if !m.Exists(ws.Path) {
+						m.Workspaces = append(m.Workspaces, ws)
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return err
 			}
+			ctx.Manifest = updated
 
-			if err := ctx.SaveManifest(); err != nil {
-				return fmt.Errorf("failed to save manifest: %w", err)
+			if firstScan {
+				fmt.Printf(i18n.T("created_gitsubs", len(discovered)))
+			} else {
+				fmt.Printf(i18n.T("registered_discovered", len(discovered)))
 			}
-
-			fmt.Printf(i18n.T("created_gitsubs", len(discovered)))
 			for _, ws := range discovered {
-				fmt.Printf("  - %s (%s)\n", ws.Path, ws.Repo)
+				repo := ws.Repo
+				if repo == "" {
+					repo = i18n.T("no_remote_label")
+				}
+				fmt.Printf("  - %s (%s)\n", ws.Path, repo)
 			}
+		} else if !firstScan {
+			fmt.Println(i18n.T("no_unregistered"))
 		} else {
 			fmt.Println(i18n.T("no_subs_found"))
 			fmt.Println(i18n.T("to_add_sub"))
@@ -102,7 +197,7 @@ func runSync(cmd *cobra.Command, args []string) error {
 	motherKeepFiles := ctx.Manifest.Keep
 	if len(motherKeepFiles) > 0 {
 		fmt.Printf("\n%s\n", i18n.T("processing_mother_keep"))
-		processKeepFiles(ctx.RepoRoot, ctx.RepoRoot, motherKeepFiles, &issues)
+		processKeepFiles(os.Stdout, ctx.RepoRoot, ctx.RepoRoot, motherKeepFiles, &issues)
 	}
 
 	if len(ctx.Manifest.Workspaces) == 0 {
@@ -110,121 +205,345 @@ func runSync(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	// 5. Process each workspace
+	// 5. Process each workspace (concurrently, output buffered per workspace)
 	fmt.Println(i18n.T("processing_subclones"))
 
-	for _, ws := range ctx.Manifest.Workspaces {
-		fullPath := filepath.Join(ctx.RepoRoot, ws.Path)
-		fmt.Printf("\n  %s\n", ws.Path)
-
-		// Check if workspace exists
-		if !git.IsRepo(fullPath) {
-			// Check if directory has files (parent is tracking source)
-			entries, err := os.ReadDir(fullPath)
-			if err == nil && len(entries) > 0 {
-				// Directory exists with files - init git in place
-				fmt.Printf("    %s\n", i18n.T("initializing_git"))
+	var issuesMu sync.Mutex
+	handler := func(ws *manifest.WorkspaceEntry, fullPath string, out io.Writer) error {
+		wsIssues, err := syncWorkspace(ctx, ws, lock.Find(ws.Path), fullPath, out)
+		issuesMu.Lock()
+		issues += wsIssues
+		issuesMu.Unlock()
+		return err
+	}
+	errs := ctx.ForEachWorkspaceParallel(syncJobs, os.Stdout, handler)
 
-				if err := git.InitRepo(fullPath, ws.Repo, ws.Branch); err != nil {
-					fmt.Printf("    %s\n", i18n.T("failed_initialize", err))
-					issues++
-					continue
-				}
+	// Includes inside workspaces become readable once those are cloned
+	for len(ctx.Manifest.MissingIncludes()) > 0 {
+		reloaded, err := manifest.Load(ctx.RepoRoot)
+		if err != nil || len(reloaded.MissingIncludes()) >= len(ctx.Manifest.MissingIncludes()) {
+			break
+		}
+		var added []manifest.WorkspaceEntry
+		for _, ws := range reloaded.Workspaces {
+			if !ctx.Manifest.Exists(ws.Path) {
+				added = append(added, ws)
+			}
+		}
+		ctx.Manifest = reloaded
+		errs = append(errs, ctx.RunWorkspacesParallel(added, syncJobs, os.Stdout, handler)...)
+	}
+	for _, include := range ctx.Manifest.MissingIncludes() {
+		fmt.Printf("\n%s\n", i18n.T("include_not_found", include))
+		issues++
+	}
 
-				// Add to .gitignore
-				if err := git.AddToGitignore(ctx.RepoRoot, ws.Path); err != nil {
-					fmt.Printf("    %s\n", i18n.T("failed_update_gitignore", err))
-				}
+	drifted := 0
+	for _, err := range errs {
+		if err != nil {
+			drifted++
+		}
+	}
 
-				fmt.Printf("    %s\n", i18n.T("initialized_git"))
-				continue
+	// 6. Check if archiving should run (24 hours check)
+	multireposDir := filepath.Join(ctx.RepoRoot, ".multirepos")
+	if backup.ShouldRunArchive(multireposDir) {
+		backupDir := filepath.Join(multireposDir, "backup")
+		if err := backup.ArchiveOldBackups(backupDir); err != nil {
+			fmt.Printf("\n⚠️  Archive failed: %v\n", err)
+			// Don't fail the entire sync if archiving fails
+		} else {
+			// Update check time only on success
+			if err := backup.UpdateArchiveCheck(multireposDir); err != nil {
+				fmt.Printf("\n⚠️  Failed to update archive check time: %v\n", err)
 			}
+		}
 
-			// Directory empty or doesn't exist - clone normally
-			fmt.Printf("    %s\n", i18n.T("cloning_from", ws.Repo))
+		// Apply retention settings, if any
+		if err := pruneBackups(ctx.Manifest, backupDir); err != nil {
+			fmt.Printf("\n⚠️  Backup prune failed: %v\n", err)
+		}
+	}
 
-			// Create parent directory if needed
-			parentDir := filepath.Dir(fullPath)
-			if err := os.MkdirAll(parentDir, 0755); err != nil {
-				fmt.Printf("    %s\n", i18n.T("failed_create_dir", err))
-				issues++
-				continue
+	// Summary
+	fmt.Println()
+	if issues > 0 {
+		fmt.Println(i18n.T("completed_issues", issues))
+	} else {
+		fmt.Println(i18n.T("all_success"))
+	}
+
+	if drifted > 0 {
+		return fmt.Errorf("%d workspace(s) do not match %s", drifted, manifest.LockFileName)
+	}
+
+	return nil
+}
+
+// syncSharedMu serializes writes to files shared by all workspaces
+// (.gitignore and .git.multirepos) while workspaces are synced in parallel
+var syncSharedMu sync.Mutex
+
+// checkLockCoverage verifies that a lock file exists and pins every workspace (sync --locked)
+func checkLockCoverage(repoRoot string, m *manifest.Manifest, lock *manifest.Lock) error {
+	if !manifest.LockExists(repoRoot) {
+		return fmt.Errorf("%s not found. Run 'git multirepo lock' first", manifest.LockFileName)
+	}
+
+	var missing []string
+	for _, ws := range m.Workspaces {
+		if lock.Find(ws.Path) == nil {
+			missing = append(missing, ws.Path)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("workspaces missing from %s:\n  - %s", manifest.LockFileName, strings.Join(missing, "\n  - "))
+	}
+	return nil
+}
+
+// syncWorkspace clones or initializes a single workspace and applies its configuration.
+// Newly cloned workspaces are checked out at the locked commit if locked is not nil.
+// All output is written to out. Returns the number of issues encountered, and an
+// error if --locked is set and the workspace has drifted from its locked commit.
+func syncWorkspace(ctx *common.WorkspaceContext, ws *manifest.WorkspaceEntry, locked *manifest.LockEntry, fullPath string, out io.Writer) (int, error) {
+	repoRoot := ctx.RepoRoot
+	issues := 0
+	fmt.Fprintf(out, "\n  %s\n", ws.Path)
+
+	// Check if workspace exists
+	if !git.IsRepo(fullPath) {
+		// Registered without an origin remote (discover.local_repos): nothing to clone from
+		if ws.Repo == "" {
+			fmt.Fprintf(out, "    %s\n", i18n.T("local_not_cloned"))
+			return issues, nil
+		}
+
+		repo, opts := cloneSource(ctx.Manifest, ws)
+
+		// Check if directory has files (parent is tracking source)
+		entries, err := os.ReadDir(fullPath)
+		if err == nil && len(entries) > 0 {
+			// Directory exists with files - init git in place
+			fmt.Fprintf(out, "    %s\n", i18n.T("initializing_git"))
+
+			if err := git.InitRepoWithOptions(fullPath, repo, opts, out); err != nil {
+				fmt.Fprintf(out, "    %s\n", i18n.T("failed_initialize", err))
+				return issues + 1, nil
 			}
 
-			// Clone the repository
-			if err := git.Clone(ws.Repo, fullPath, ws.Branch); err != nil {
-				fmt.Printf("    %s\n", i18n.T("clone_failed", err))
-				issues++
-				continue
+			// Pin to locked commit, keeping the source files tracked by parent
+			if locked != nil {
+				fmt.Fprintf(out, "    %s\n", i18n.T("checking_out_locked", shortCommit(locked.Commit)))
+				if err := git.CheckoutCommit(fullPath, locked.Commit, true, out); err != nil {
+					fmt.Fprintf(out, "    %s\n", i18n.T("locked_checkout_failed", err))
+					issues++
+				}
 			}
 
 			// Add to .gitignore
-			if err := git.AddToGitignore(ctx.RepoRoot, ws.Path); err != nil {
-				fmt.Printf("    %s\n", i18n.T("failed_update_gitignore", err))
+			syncSharedMu.Lock()
+			err = git.AddToGitignore(repoRoot, ws.Path)
+			syncSharedMu.Unlock()
+			if err != nil {
+				fmt.Fprintf(out, "    %s\n", i18n.T("failed_update_gitignore", err))
 			}
 
-			fmt.Printf("    %s\n", i18n.T("cloned_successfully"))
-			continue
+			fmt.Fprintf(out, "    %s\n", i18n.T("initialized_git"))
+			return issues, nil
 		}
 
-		// Verify and fix .gitignore entry
-		if !hasGitignoreEntry(ctx.RepoRoot, ws.Path) {
-			fmt.Printf("    %s\n", i18n.T("adding_to_gitignore"))
-			if err := git.AddToGitignore(ctx.RepoRoot, ws.Path); err != nil {
-				fmt.Printf("    %s\n", i18n.T("hooks_failed", err))
+		// Directory empty or doesn't exist - clone normally
+		fmt.Fprintf(out, "    %s\n", i18n.T("cloning_from", repo))
+
+		// Create parent directory if needed
+		parentDir := filepath.Dir(fullPath)
+		if err := os.MkdirAll(parentDir, 0755); err != nil {
+			fmt.Fprintf(out, "    %s\n", i18n.T("failed_create_dir", err))
+			return issues + 1, nil
+		}
+
+		// Clone the repository
+		if err := git.CloneWithOptions(repo, fullPath, opts, out); err != nil {
+			fmt.Fprintf(out, "    %s\n", i18n.T("clone_failed", err))
+			return issues + 1, nil
+		}
+
+		// Pin to locked commit
+		if locked != nil {
+			fmt.Fprintf(out, "    %s\n", i18n.T("checking_out_locked", shortCommit(locked.Commit)))
+			if err := git.CheckoutCommit(fullPath, locked.Commit, false, out); err != nil {
+				fmt.Fprintf(out, "    %s\n", i18n.T("locked_checkout_failed", err))
 				issues++
-			} else {
-				fmt.Printf("    %s\n", i18n.T("added_to_gitignore"))
 			}
 		}
 
-		// Process keep files for this workspace
-		keepFiles := ws.Keep
-		if len(keepFiles) > 0 {
-			fmt.Printf("    %s\n", i18n.T("processing_keep_files", len(keepFiles)))
-			processKeepFiles(ctx.RepoRoot, fullPath, keepFiles, &issues)
+		// Add to .gitignore
+		syncSharedMu.Lock()
+		err = git.AddToGitignore(repoRoot, ws.Path)
+		syncSharedMu.Unlock()
+		if err != nil {
+			fmt.Fprintf(out, "    %s\n", i18n.T("failed_update_gitignore", err))
 		}
 
-		// Install/update post-commit hook in workspace
-		if !hooks.IsWorkspaceHookInstalled(fullPath) {
-			fmt.Printf("    %s\n", i18n.T("installing_hook"))
-			if err := hooks.InstallWorkspaceHook(fullPath); err != nil {
-				fmt.Printf("    %s\n", i18n.T("hook_failed", err))
-			} else {
-				fmt.Printf("    %s\n", i18n.T("hook_installed"))
-			}
+		fmt.Fprintf(out, "    %s\n", i18n.T("cloned_successfully"))
+		return issues, nil
+	}
+
+	// Compare the workspace with its locked commit. Existing workspaces are
+	// never moved: only fresh clones are pinned, --locked refuses drift.
+	var driftErr error
+	if locked != nil {
+		head, err := git.GetCurrentCommit(fullPath)
+		switch {
+		case syncLocked && (err != nil || head != locked.Commit):
+			fmt.Fprintf(out, "    %s\n", i18n.T("lock_drift", shortCommit(head), shortCommit(locked.Commit)))
+			driftErr = fmt.Errorf("%s: at %s, locked at %s", ws.Path, shortCommit(head), shortCommit(locked.Commit))
+		case err == nil && head != locked.Commit:
+			fmt.Fprintf(out, "    %s\n", i18n.T("lock_drift_note", shortCommit(head), shortCommit(locked.Commit)))
 		}
 	}
 
-	// Save manifest if any commits were updated
-	if err := ctx.SaveManifest(); err != nil {
-		return fmt.Errorf("failed to save manifest: %w", err)
+	// Compare origin with the manifest repo
+	if origin, drifted := originDrift(ctx.Manifest, ws, fullPath); drifted {
+		if !reconcileOrigin(ctx, ws, fullPath, origin, out) {
+			issues++
+		}
 	}
 
-	// 6. Check if archiving should run (24 hours check)
-	multireposDir := filepath.Join(ctx.RepoRoot, ".multirepos")
-	if backup.ShouldRunArchive(multireposDir) {
-		backupDir := filepath.Join(multireposDir, "backup")
-		if err := backup.ArchiveOldBackups(backupDir); err != nil {
-			fmt.Printf("\n⚠️  Archive failed: %v\n", err)
-			// Don't fail the entire sync if archiving fails
+	// Verify and fix .gitignore entry
+	syncSharedMu.Lock()
+	if !hasGitignoreEntry(repoRoot, ws.Path) {
+		fmt.Fprintf(out, "    %s\n", i18n.T("adding_to_gitignore"))
+		if err := git.AddToGitignore(repoRoot, ws.Path); err != nil {
+			fmt.Fprintf(out, "    %s\n", i18n.T("hooks_failed", err))
+			issues++
 		} else {
-			// Update check time only on success
-			if err := backup.UpdateArchiveCheck(multireposDir); err != nil {
-				fmt.Printf("\n⚠️  Failed to update archive check time: %v\n", err)
+			fmt.Fprintf(out, "    %s\n", i18n.T("added_to_gitignore"))
+		}
+	}
+	syncSharedMu.Unlock()
+
+	// Apply changes to the sparse checkout directories
+	if msg, err := updateSparseCheckout(fullPath, ws); err != nil {
+		fmt.Fprintf(out, "    %s\n", i18n.T("sparse_failed", err))
+		issues++
+	} else if msg != "" {
+		fmt.Fprintf(out, "    %s\n", msg)
+	}
+
+	// Process keep files for this workspace
+	keepFiles := ws.Keep
+	if len(keepFiles) > 0 {
+		fmt.Fprintf(out, "    %s\n", i18n.T("processing_keep_files", len(keepFiles)))
+		processKeepFiles(out, repoRoot, fullPath, keepFiles, &issues)
+	}
+
+	// Install/update post-commit hook in workspace
+	if !hooks.IsWorkspaceHookInstalled(fullPath) {
+		fmt.Fprintf(out, "    %s\n", i18n.T("installing_hook"))
+		if err := hooks.InstallWorkspaceHook(fullPath); err != nil {
+			fmt.Fprintf(out, "    %s\n", i18n.T("hook_failed", err))
+		} else {
+			fmt.Fprintf(out, "    %s\n", i18n.T("hook_installed"))
+		}
+	}
+
+	return issues, driftErr
+}
+
+// reconcileOrigin handles a workspace whose origin differs from its manifest
+// repo: with --update-remotes origin is set to the repo, with --adopt-remotes
+// the repo is set to origin, otherwise the mismatch is reported. Returns true
+// if origin and manifest agree afterwards.
+func reconcileOrigin(ctx *common.WorkspaceContext, ws *manifest.WorkspaceEntry, fullPath, origin string, out io.Writer) bool {
+	switch {
+	case syncUpdateRemotes:
+		expected := expectedOrigin(ctx.Manifest, ws)
+		if err := git.SetRemoteURL(fullPath, expected); err != nil {
+			fmt.Fprintf(out, "    %s\n", i18n.T("remote_update_failed", err))
+			return false
+		}
+		fmt.Fprintf(out, "    %s\n", i18n.T("remote_updated", expected))
+		return true
+
+	case syncAdoptRemotes && origin != "":
+		syncSharedMu.Lock()
+		_, err := manifest.Update(ctx.RepoRoot, func(m *manifest.Manifest) error {
+			entry := m.Find(ws.Path)
+			if entry == nil {
+				return fmt.Errorf("%s is no longer in the manifest", ws.Path)
 			}
+			entry.Repo = origin
+			return nil
+		})
+		syncSharedMu.Unlock()
+		if err != nil {
+			fmt.Fprintf(out, "    %s\n", i18n.T("remote_adopt_failed", err))
+			return false
 		}
+		ws.Repo = origin
+		fmt.Fprintf(out, "    %s\n", i18n.T("remote_adopted", origin))
+		return true
 	}
 
-	// Summary
-	fmt.Println()
-	if issues > 0 {
-		fmt.Println(i18n.T("completed_issues", issues))
+	if origin == "" {
+		fmt.Fprintf(out, "    %s\n", i18n.T("origin_missing", ws.Repo))
 	} else {
-		fmt.Println(i18n.T("all_success"))
+		fmt.Fprintf(out, "    %s\n", i18n.T("origin_drift", origin, ws.Repo))
 	}
+	fmt.Fprintf(out, "      %s\n", i18n.T("remote_drift_hint"))
+	return false
+}
 
-	return nil
+// cloneSource returns the URL to clone a workspace from and its clone options,
+// including the fallback URLs to try if that fails
+func cloneSource(m *manifest.Manifest, ws *manifest.WorkspaceEntry) (string, git.CloneOptions) {
+	opts := git.CloneOptions{
+		Branch: ws.Branch,
+		Depth:  ws.Depth,
+		Filter: ws.Filter,
+		Sparse: ws.Sparse,
+	}
+
+	urls := remoteURLs(m, ws)
+	if len(urls) == 0 {
+		return ws.Repo, opts
+	}
+	opts.Fallbacks = urls[1:]
+	return urls[0], opts
+}
+
+// updateSparseCheckout makes the sparse checkout of an existing workspace match
+// its sparse directories in the manifest. Returns a message describing the
+// change, or "" if there was nothing to change.
+func updateSparseCheckout(fullPath string, ws *manifest.WorkspaceEntry) (string, error) {
+	current, err := git.SparseCheckoutDirs(fullPath)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case len(ws.Sparse) > 0 && strings.Join(current, "\n") != strings.Join(git.NormalizeSparseDirs(ws.Sparse), "\n"):
+		if err := git.SetSparseCheckout(fullPath, ws.Sparse); err != nil {
+			return "", err
+		}
+		return i18n.T("sparse_updated", strings.Join(ws.Sparse, ", ")), nil
+	case len(ws.Sparse) == 0 && current != nil:
+		if err := git.DisableSparseCheckout(fullPath); err != nil {
+			return "", err
+		}
+		return i18n.T("sparse_disabled"), nil
+	}
+	return "", nil
+}
+
+// shortCommit abbreviates a commit hash for display
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
 }
 
 func hasGitignoreEntry(repoRoot, path string) bool {
@@ -244,89 +563,143 @@ func hasGitignoreEntry(repoRoot, path string) bool {
 	return false
 }
 
-// scanForWorkspaces recursively scans directories for git repositories
-func scanForWorkspaces(repoRoot string) ([]manifest.WorkspaceEntry, error) {
-	var workspaces []manifest.WorkspaceEntry
-
-	// Walk the directory tree
-	err := filepath.Walk(repoRoot, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil // Skip errors
-		}
-
-		// Skip parent's .git directory
-		if path == filepath.Join(repoRoot, ".git") {
-			return filepath.SkipDir
-		}
-
-		// Check if this is a .git directory
-		if !info.IsDir() || info.Name() != ".git" {
-			return nil
-		}
+// scanForWorkspaces scans the project for git repositories that are not
+// registered in its manifest, as configured by the manifest's discover section.
+// Registered workspaces are not descended into. Found repositories are
+// inspected with at most jobs in parallel.
+func scanForWorkspaces(ctx *common.WorkspaceContext, jobs int) ([]manifest.WorkspaceEntry, error) {
+	repoRoot := ctx.RepoRoot
+	cfg := ctx.Manifest.GetDiscover()
 
-		// Get the repository path (parent of .git)
-		workspacePath := filepath.Dir(path)
-
-		// Skip if it's the parent repo itself
-		if workspacePath == repoRoot {
-			return filepath.SkipDir
-		}
+	repos, err := discover.Scan(repoRoot, discoverOptions(ctx, jobs))
+	if err != nil {
+		return nil, err
+	}
 
-		// Get relative path from parent
-		relPath, err := filepath.Rel(repoRoot, workspacePath)
-		if err != nil {
-			return nil
+	// Inspect in parallel; output is printed in path order
+	found := make([]manifest.WorkspaceEntry, len(repos))
+	byPath := make(map[string]discover.Repo, len(repos))
+	for i, repo := range repos {
+		found[i].Path = repo.Path
+		byPath[repo.Path] = repo
+	}
+	errs := ctx.RunWorkspacesParallel(found, jobs, os.Stdout, func(ws *manifest.WorkspaceEntry, fullPath string, out io.Writer) error {
+		repo := byPath[ws.Path]
+		switch repo.Kind {
+		case discover.KindWorktree:
+			main := discover.MainRepo(repo.GitDir)
+			if rel, err := filepath.Rel(repoRoot, main); err == nil && !strings.HasPrefix(rel, "..") {
+				main = filepath.ToSlash(rel)
+			}
+			fmt.Fprintf(out, "  %s\n", i18n.T("skipped_worktree", ws.Path, main))
+			return errSkipped
+		case discover.KindSubmodule:
+			fmt.Fprintf(out, "  %s\n", i18n.T("skipped_submodule", ws.Path))
+			return errSkipped
+		case discover.KindGitFile:
+			fmt.Fprintf(out, "  %s\n", i18n.T("skipped_gitfile", ws.Path))
+			return errSkipped
 		}
 
-		// Extract git info
-		repo, err := git.GetRemoteURL(workspacePath)
+		entry, err := inspectWorkspace(repoRoot, fullPath, cfg.LocalRepos)
 		if err != nil {
-			fmt.Println(i18n.T("failed_get_remote", relPath, err))
-			return filepath.SkipDir
+			fmt.Fprintf(out, "  %s\n", i18n.T("no_remote_skipped", ws.Path))
+			return err
 		}
 
-		// Detect modified files for auto-keep
-		var keepFiles []string
-		// Get skip-worktree files (these are the keep files)
-		skipFiles, err := git.ListSkipWorktree(workspacePath)
-		if err == nil && len(skipFiles) > 0 {
-			keepFiles = skipFiles
-		} else {
-			// Fallback: detect modified files for first-time setup (no keep files yet, so no transaction needed)
+		// No skip-worktree files: on first-time setup, modified files become keep files
+		if len(entry.Keep) == 0 {
 			// Transaction is safe even with empty keepFiles (it will just execute workFunc directly)
 			var modifiedFiles []string
-			git.WithSkipWorktreeTransaction(workspacePath, []string{}, func() error {
+			git.WithSkipWorktreeTransaction(fullPath, []string{}, func() error {
 				var err error
-				modifiedFiles, err = git.GetModifiedFiles(workspacePath)
+				modifiedFiles, err = git.GetModifiedFiles(fullPath)
 				return err
 			})
-			if len(modifiedFiles) > 0 {
-				// Clean up file list
-				for _, file := range modifiedFiles {
-					if strings.TrimSpace(file) != "" {
-						keepFiles = append(keepFiles, file)
-					}
+			// Clean up file list
+			for _, file := range modifiedFiles {
+				if strings.TrimSpace(file) != "" {
+					entry.Keep = append(entry.Keep, file)
 				}
 			}
 		}
 
-		fmt.Printf("  %s\n", i18n.T("found_sub", relPath))
+		fmt.Fprintf(out, "  %s\n", i18n.T("found_sub", ws.Path))
+		*ws = entry
+		return nil
+	})
 
-		workspaces = append(workspaces, manifest.WorkspaceEntry{
-			Path: relPath,
-			Repo: repo,
-			Keep: keepFiles,
-		})
+	var workspaces []manifest.WorkspaceEntry
+	for i, ws := range found {
+		if errs[i] == nil {
+			workspaces = append(workspaces, ws)
+		}
+	}
+	return workspaces, nil
+}
 
-		// Skip descending into this workspace's subdirectories
-		return filepath.SkipDir
-	})
+// discoverOptions returns the scan options set by the manifest's discover
+// section. Unless include_ignored is set, directories ignored by .gitignore
+// are skipped.
+func discoverOptions(ctx *common.WorkspaceContext, jobs int) discover.Options {
+	cfg := ctx.Manifest.GetDiscover()
+	opts := discover.Options{
+		MaxDepth:       cfg.MaxDepth,
+		Exclude:        cfg.Exclude,
+		FollowSymlinks: cfg.FollowSymlinks,
+		Jobs:           jobs,
+		Skip: func(rel string) bool {
+			// .multirepos holds workspaces trashed by remove
+			return rel == ".multirepos" || ctx.Manifest.Exists(rel)
+		},
+	}
+	if !cfg.IncludeIgnored {
+		dirs, err := git.ListIgnoredDirs(ctx.RepoRoot)
+		if err != nil {
+			fmt.Printf("  %s\n", i18n.T("ignored_dirs_failed", err))
+		}
+		opts.Ignored = make(map[string]bool, len(dirs))
+		for _, dir := range dirs {
+			opts.Ignored[dir] = true
+		}
+	}
+	return opts
+}
+
+// errSkipped marks a found repository that is not registered
+var errSkipped = errors.New("skipped")
+
+// inspectWorkspace builds the manifest entry of an existing repository from
+// its .git: origin URL, current branch (unless it is origin's default branch)
+// and skip-worktree files as keep files. A repository without an origin remote
+// is an error, unless allowLocal is set; its repo is left empty then.
+func inspectWorkspace(repoRoot, workspacePath string, allowLocal bool) (manifest.WorkspaceEntry, error) {
+	relPath, err := filepath.Rel(repoRoot, workspacePath)
+	if err != nil {
+		return manifest.WorkspaceEntry{}, err
+	}
+
+	repo, err := git.GetRemoteURL(workspacePath)
+	if err != nil && !allowLocal {
+		return manifest.WorkspaceEntry{}, err
+	}
+	ws := manifest.WorkspaceEntry{Path: filepath.ToSlash(relPath), Repo: repo}
+
+	if branch, err := git.GetCurrentBranch(workspacePath); err == nil && branch != "HEAD" {
+		if defaultBranch, err := git.GetRemoteDefaultBranch(workspacePath); err != nil || branch != defaultBranch {
+			ws.Branch = branch
+		}
+	}
 
-	return workspaces, err
+	// Skip-worktree files are the keep files
+	if skipFiles, err := git.ListSkipWorktree(workspacePath); err == nil && len(skipFiles) > 0 {
+		ws.Keep = skipFiles
+	}
+	return ws, nil
 }
 
 // processKeepFiles handles backup, patch creation, and skip-worktree for keep files
-func processKeepFiles(repoRoot, workspacePath string, keepFiles []string, issues *int) {
+func processKeepFiles(out io.Writer, repoRoot, workspacePath string, keepFiles []string, issues *int) {
 	backupDir := filepath.Join(repoRoot, ".multirepos", "backup")
 	patchBaseDir := filepath.Join(repoRoot, ".multirepos", "patches")
 
@@ -378,39 +751,18 @@ func processKeepFiles(repoRoot, workspacePath string, keepFiles []string, issues
 
 		// 3b. Auto-populate Keep list if empty and there are modified files
 		if len(keepFiles) == 0 && len(modifiedFiles) > 0 {
-			// Load manifest to update it
-			m, loadErr := manifest.Load(repoRoot)
-			if loadErr != nil {
-				return fmt.Errorf("failed to load manifest: %w", loadErr)
-			}
-
-			// Update the keep list in manifest
-			if relPath == "" || relPath == "." {
-				// Mother repo
-				m.Keep = modifiedFiles
-			} else {
-				// Workspace entry
-				for i := range m.Workspaces {
-					if m.Workspaces[i].Path == relPath {
-						m.Workspaces[i].Keep = modifiedFiles
-						break
-					}
-				}
-			}
-
-			// Save manifest
-			if saveErr := manifest.Save(repoRoot, m); saveErr != nil {
-				return fmt.Errorf("failed to save manifest: %w", saveErr)
+			if saveErr := saveKeepList(repoRoot, relPath, modifiedFiles); saveErr != nil {
+				return saveErr
 			}
 
 			// Update keepFiles for this run (will be re-applied by defer)
 			keepFiles = modifiedFiles
 
-			fmt.Printf("\n✓ Found %d modified files and added to keep list:\n", len(modifiedFiles))
+			fmt.Fprintf(out, "\n✓ Found %d modified files and added to keep list:\n", len(modifiedFiles))
 			for _, f := range modifiedFiles {
-				fmt.Printf("  - %s\n", f)
+				fmt.Fprintf(out, "  - %s\n", f)
 			}
-			fmt.Println("\nEdit .git.multirepos to keep only the files you need")
+			fmt.Fprintln(out, "\nEdit .git.multirepos to keep only the files you need")
 		}
 
 		// 3c. Process ALL modified files (backup + patch for all)
@@ -424,7 +776,7 @@ func processKeepFiles(repoRoot, workspacePath string, keepFiles []string, issues
 
 			// Backup original file to backup/modified/
 			if backupErr := backup.CreateFileBackup(filePath, backupDir, repoRoot); backupErr != nil {
-				fmt.Printf("        Failed to backup %s: %v\n", file, backupErr)
+				fmt.Fprintf(out, "        Failed to backup %s: %v\n", file, backupErr)
 				*issues++
 				continue
 			}
@@ -432,14 +784,14 @@ func processKeepFiles(repoRoot, workspacePath string, keepFiles []string, issues
 			// Create patch (git diff HEAD file)
 			patchPath := filepath.Join(patchBaseDir, relPath, file+".patch")
 			if patchErr := patch.Create(workspacePath, file, patchPath); patchErr != nil {
-				fmt.Printf("        Failed to create patch for %s: %v\n", file, patchErr)
+				fmt.Fprintf(out, "        Failed to create patch for %s: %v\n", file, patchErr)
 				*issues++
 				continue
 			}
 
 			// Backup patch to backup/patched/
 			if patchBackupErr := backup.CreatePatchBackup(patchPath, backupDir); patchBackupErr != nil {
-				fmt.Printf("        Failed to backup patch for %s: %v\n", file, patchBackupErr)
+				fmt.Fprintf(out, "        Failed to backup patch for %s: %v\n", file, patchBackupErr)
 				*issues++
 				continue
 			}
@@ -448,7 +800,7 @@ func processKeepFiles(repoRoot, workspacePath string, keepFiles []string, issues
 		return nil
 	})
 	if err != nil {
-		fmt.Printf("        Failed to process keep files: %v\n", err)
+		fmt.Fprintf(out, "        Failed to process keep files: %v\n", err)
 		*issues++
 		return
 	}
@@ -457,6 +809,25 @@ func processKeepFiles(repoRoot, workspacePath string, keepFiles []string, issues
 
 	// Summary message
 	if len(modifiedFiles) > 0 {
-		fmt.Printf("        ✓ Processed %d modified files (%d with skip-worktree)\n", len(modifiedFiles), len(keepFiles))
+		fmt.Fprintf(out, "        ✓ Processed %d modified files (%d with skip-worktree)\n", len(modifiedFiles), len(keepFiles))
 	}
 }
+
+// saveKeepList records files as the keep list of the mother repo (relPath "")
+// or of the workspace at relPath, reloading the manifest from disk first
+func saveKeepList(repoRoot, relPath string, files []string) error {
+	syncSharedMu.Lock()
+	defer syncSharedMu.Unlock()
+
+	_, err := manifest.Update(repoRoot, func(m *manifest.Manifest) error {
+		if relPath == "" || relPath == "." {
+			// Mother repo
+			m.Keep = files
+		} else if ws := m.Find(relPath); ws != nil {
+			// Workspace entry
+			ws.Keep = files
+		}
+		return nil
+	})
+	return err
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/yejune/git-multirepo/internal/git"
+	"github.com/yejune/git-multirepo/internal/interactive"
+	"github.com/yejune/git-multirepo/internal/journal"
+	"github.com/yejune/git-multirepo/internal/manifest"
+)
+
+var (
+	undoList  bool
+	undoForce bool
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo [id]",
+	Short: "Undo the last manifest-changing command",
+	Long: `Restore the state from before a command that changed the manifest.
+
+sync, pull, lock, clone, add, remove, move, reset, branch --update-manifest,
+doctor --fix, import-submodules and export-submodules save .git.multirepos,
+.git.multirepos.lock, .gitignore, .gitmodules, the keep file conflict state
+and the skip-worktree flags of every workspace to .multirepos/journal/ before
+running. Workspaces deleted by remove are moved there too, instead of being
+deleted outright; workspaces relocated by move are moved back. Commands that
+change none of these leave no entry; the last 20 entries are kept, plus every
+entry holding workspaces deleted by remove.
+
+undo restores the most recent entry, or the one given by id, and removes it
+from the journal. Files are restored as they were before that command, so
+later changes to them are lost. Workspaces cloned by the command are left in
+place, and the index and git directories are not touched.
+
+Examples:
+  git multirepo undo --list
+  git multirepo undo
+  git multirepo undo 20260115-093000`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runUndo,
+}
+
+func init() {
+	undoCmd.Flags().BoolVarP(&undoList, "list", "l", false, "List journal entries")
+	undoCmd.Flags().BoolVarP(&undoForce, "force", "f", false, "Skip confirmation")
+	undoCmd.MarkFlagsMutuallyExclusive("list", "force")
+	rootCmd.AddCommand(undoCmd)
+}
+
+// beginJournal saves the state before command changes it, for 'git multirepo undo'.
+// Failing to do so doesn't stop the command; the returned entry is nil then.
+func beginJournal(repoRoot, command string, m *manifest.Manifest) *journal.Entry {
+	var workspaces []string
+	for _, ws := range m.Workspaces {
+		workspaces = append(workspaces, ws.Path)
+	}
+	entry, err := journal.Begin(repoRoot, command, workspaces)
+	if err != nil {
+		fmt.Printf("⚠ Failed to record undo journal: %v\n", err)
+		return nil
+	}
+	return entry
+}
+
+func runUndo(cmd *cobra.Command, args []string) error {
+	repoRoot, err := git.GetRepoRoot()
+	if err != nil {
+		return fmt.Errorf("not in a git repository: %w", err)
+	}
+
+	if undoList {
+		if len(args) > 0 {
+			return fmt.Errorf("--list takes no arguments")
+		}
+		return listJournal(repoRoot)
+	}
+
+	var entry *journal.Entry
+	if len(args) > 0 {
+		if entry, err = journal.Find(repoRoot, args[0]); err != nil {
+			return err
+		}
+	} else {
+		entries, err := journal.List(repoRoot)
+		if err != nil {
+			return fmt.Errorf("failed to read journal: %w", err)
+		}
+		if len(entries) == 0 {
+			fmt.Println("Nothing to undo.")
+			return nil
+		}
+		entry = entries[0]
+	}
+
+	if !undoForce {
+		confirmed, err := interactive.ConfirmYN(fmt.Sprintf("Undo '%s' from %s? [y/N] ", entry.Command, entry.Time.Format("2006-01-02 15:04:05")))
+		if err != nil {
+			return fmt.Errorf("failed to read confirmation: %w", err)
+		}
+		if !confirmed {
+			fmt.Println("Cancelled.")
+			return nil
+		}
+	}
+
+	moves := append([]journal.Move(nil), entry.Moves...)
+	trashed := append([]string(nil), entry.Trashed...)
+	existed := make(map[string]bool)
+	for _, f := range entry.Files {
+		if _, err := os.Stat(filepath.Join(repoRoot, f.Path)); err == nil {
+			existed[f.Path] = true
+		}
+	}
+	if err := entry.Undo(); err != nil {
+		return fmt.Errorf("failed to undo %s: %w", entry.ID, err)
+	}
+
+	for i := len(moves) - 1; i >= 0; i-- {
+		fmt.Printf("  ✓ Moved %s back to %s\n", moves[i].To, moves[i].From)
+	}
+	for _, path := range trashed {
+		fmt.Printf("  ✓ Restored workspace %s\n", path)
+	}
+	for _, f := range entry.Files {
+		if !f.Missing {
+			fmt.Printf("  ✓ Restored %s\n", f.Path)
+		} else if existed[f.Path] {
+			fmt.Printf("  ✓ Removed %s (did not exist)\n", f.Path)
+		}
+	}
+	fmt.Printf("  ✓ Restored skip-worktree flags of %d repository(ies)\n", len(entry.SkipWorktree))
+	fmt.Printf("\n✓ Undid '%s' (%s)\n", entry.Command, entry.ID)
+	return nil
+}
+
+// listJournal prints the journal entries, newest first
+func listJournal(repoRoot string) error {
+	entries, err := journal.List(repoRoot)
+	if err != nil {
+		return fmt.Errorf("failed to read journal: %w", err)
+	}
+	if len(entries) == 0 {
+		fmt.Println("Nothing to undo.")
+		return nil
+	}
+
+	for _, e := range entries {
+		line := fmt.Sprintf("  %-20s %s  %s", e.ID, e.Time.Format("2006-01-02 15:04:05"), e.Command)
+		if len(e.Trashed) > 0 {
+			line += fmt.Sprintf(" (%d workspace(s) in trash)", len(e.Trashed))
+		}
+		fmt.Println(line)
+	}
+	fmt.Printf("\n%d entry(ies); 'git multirepo undo' undoes the first\n", len(entries))
+	return nil
+}
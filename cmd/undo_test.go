@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yejune/git-multirepo/internal/git"
+	"github.com/yejune/git-multirepo/internal/journal"
+	"github.com/yejune/git-multirepo/internal/manifest"
+)
+
+func TestRunUndo_Remove(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	writeTestManifest(t, dir, manifest.WorkspaceEntry{Path: "lib", Repo: setupRemoteRepo(t)})
+	captureOutput(func() {
+		if err := runSync(syncCmd, nil); err != nil {
+			t.Fatalf("sync failed: %v", err)
+		}
+	})
+	os.WriteFile(filepath.Join(dir, "lib", "local.txt"), []byte("untracked work"), 0644)
+
+	// A sync that changes nothing leaves no entry
+	entries, _ := journal.List(dir)
+	before := len(entries)
+	captureOutput(func() { runSync(syncCmd, nil) })
+	if entries, _ := journal.List(dir); len(entries) != before {
+		t.Errorf("sync without changes should not add an entry (%d -> %d)", before, len(entries))
+	}
+
+	removeForce = true
+	defer func() { removeForce = false }()
+	output := captureOutput(func() {
+		if err := runRemove(removeCmd, []string{"lib"}); err != nil {
+			t.Fatalf("remove failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "'git multirepo undo' restores them") {
+		t.Errorf("remove should mention undo, got: %s", output)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "lib")); !os.IsNotExist(err) {
+		t.Fatal("workspace should be removed")
+	}
+
+	output = captureOutput(func() {
+		undoList = true
+		defer func() { undoList = false }()
+		if err := runUndo(undoCmd, nil); err != nil {
+			t.Fatalf("undo --list failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "remove lib (1 workspace(s) in trash)") {
+		t.Errorf("undo --list should show the remove, got: %s", output)
+	}
+
+	undoForce = true
+	defer func() { undoForce = false }()
+	output = captureOutput(func() {
+		if err := runUndo(undoCmd, nil); err != nil {
+			t.Fatalf("undo failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "✓ Restored workspace lib") || !strings.Contains(output, "✓ Undid 'remove lib'") {
+		t.Errorf("undo should report the restore, got: %s", output)
+	}
+
+	m, _ := manifest.Load(dir)
+	if !m.Exists("lib") {
+		t.Error("workspace should be back in the manifest")
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "lib", "local.txt")); err != nil || string(data) != "untracked work" {
+		t.Error("workspace files should be restored, including untracked ones")
+	}
+	if !git.IsRepo(filepath.Join(dir, "lib")) {
+		t.Error("restored workspace should still be a repository")
+	}
+}
+
+func TestRunUndo_Reset(t *testing.T) {
+	dir, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	os.WriteFile(filepath.Join(dir, manifest.FileName), []byte("keep:\n  - README.md\n"), 0644)
+	captureOutput(func() {
+		if err := runSync(syncCmd, nil); err != nil {
+			t.Fatalf("sync failed: %v", err)
+		}
+	})
+	if files, _ := git.ListSkipWorktree(dir); len(files) != 1 {
+		t.Fatalf("sync should skip README.md, got %v", files)
+	}
+
+	captureOutput(func() {
+		if err := runReset(resetCmd, nil); err != nil {
+			t.Fatalf("reset failed: %v", err)
+		}
+	})
+	if files, _ := git.ListSkipWorktree(dir); len(files) != 0 {
+		t.Fatalf("reset should unskip README.md, got %v", files)
+	}
+
+	undoForce = true
+	defer func() { undoForce = false }()
+	captureOutput(func() {
+		if err := runUndo(undoCmd, nil); err != nil {
+			t.Fatalf("undo failed: %v", err)
+		}
+	})
+
+	m, _ := manifest.Load(dir)
+	if len(m.Keep) != 1 || m.Keep[0] != "README.md" {
+		t.Errorf("keep list should be restored, got %v", m.Keep)
+	}
+	if files, _ := git.ListSkipWorktree(dir); len(files) != 1 || files[0] != "README.md" {
+		t.Errorf("skip-worktree should be restored, got %v", files)
+	}
+
+	// Undo pops the journal: the next one undoes the first sync
+	entries, _ := journal.List(dir)
+	if len(entries) != 1 || entries[0].Command != "sync" {
+		t.Errorf("remaining entries = %v", entries)
+	}
+}
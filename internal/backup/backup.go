@@ -136,35 +136,6 @@ func CreateFileBackup(filePath, backupDir, repoRoot string) error {
 	return copyFile(filePath, backupPath)
 }
 
-// Cleanup removes backups older than specified days
-func Cleanup(backupDir string, days int) error {
-	if days <= 0 {
-		return fmt.Errorf("days must be positive")
-	}
-
-	cutoffTime := time.Now().AddDate(0, 0, -days)
-
-	return filepath.Walk(backupDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// Skip directories
-		if info.IsDir() {
-			return nil
-		}
-
-		// Check if file is older than cutoff
-		if info.ModTime().Before(cutoffTime) {
-			if err := os.Remove(path); err != nil {
-				return fmt.Errorf("failed to remove old backup %s: %w", path, err)
-			}
-		}
-
-		return nil
-	})
-}
-
 // copyFile copies a file from src to dst
 func copyFile(src, dst string) error {
 	sourceFile, err := os.Open(src)
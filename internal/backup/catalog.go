@@ -0,0 +1,242 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Backup kinds, matching the directory names under the backup directory
+const (
+	KindModified = "modified"
+	KindPatched  = "patched"
+)
+
+// timestampLayout is the timestamp format inserted into backup file names
+const timestampLayout = "20060102_150405"
+
+// backupNamePattern splits "name.YYYYMMDD_HHMMSS.ext" into name, timestamp and extension
+var backupNamePattern = regexp.MustCompile(`^(.*)\.(\d{8}_\d{6})(\.[^.]*)?$`)
+
+// Entry is one backup copy, either a live file or a member of a monthly archive
+type Entry struct {
+	Kind    string    // KindModified or KindPatched
+	Path    string    // Original path: relative to the repo root (modified) or to .multirepos/patches (patched)
+	Time    time.Time // Time the backup was taken
+	File    string    // Live backup file, empty if archived
+	Archive string    // tar.gz containing the backup, empty if live
+	Member  string    // Name of the backup inside Archive
+}
+
+// Timestamp returns the backup timestamp as written in the file name
+func (e Entry) Timestamp() string {
+	return e.Time.Format(timestampLayout)
+}
+
+// Ref returns the "path@timestamp" reference used to select this backup
+func (e Entry) Ref() string {
+	return e.Path + "@" + e.Timestamp()
+}
+
+// Archived returns true if the backup lives in a tar.gz archive
+func (e Entry) Archived() bool {
+	return e.Archive != ""
+}
+
+// parseBackupName parses a backup path relative to its kind directory
+// (YYYY/MM/DD/sub-path/name.TIMESTAMP.ext) into the original path and time
+func parseBackupName(relPath string) (string, time.Time, bool) {
+	relPath = filepath.ToSlash(relPath)
+	parts := strings.SplitN(relPath, "/", 4)
+	if len(parts) < 4 {
+		return "", time.Time{}, false
+	}
+
+	dir, base := filepath.Split(parts[3])
+	m := backupNamePattern.FindStringSubmatch(base)
+	if m == nil {
+		return "", time.Time{}, false
+	}
+
+	t, err := time.ParseInLocation(timestampLayout, m[2], time.Local)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+
+	return filepath.ToSlash(dir) + m[1] + m[3], t, true
+}
+
+// List returns every backup under backupDir, live and archived, newest first
+func List(backupDir string) ([]Entry, error) {
+	var entries []Entry
+
+	for _, kind := range []string{KindModified, KindPatched} {
+		kindDir := filepath.Join(backupDir, kind)
+		if _, err := os.Stat(kindDir); os.IsNotExist(err) {
+			continue
+		}
+
+		err := filepath.Walk(kindDir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+
+			relPath, err := filepath.Rel(kindDir, path)
+			if err != nil {
+				return err
+			}
+			original, t, ok := parseBackupName(relPath)
+			if !ok {
+				return nil // Not a backup file
+			}
+
+			entries = append(entries, Entry{Kind: kind, Path: original, Time: t, File: path})
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s backups: %w", kind, err)
+		}
+	}
+
+	archives, err := filepath.Glob(filepath.Join(backupDir, "archived", "*.tar.gz"))
+	if err != nil {
+		return nil, err
+	}
+	for _, archive := range archives {
+		archived, err := listArchive(archive)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", filepath.Base(archive), err)
+		}
+		entries = append(entries, archived...)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Time.Equal(entries[j].Time) {
+			return entries[i].Time.After(entries[j].Time)
+		}
+		return entries[i].Path < entries[j].Path
+	})
+
+	return entries, nil
+}
+
+// archiveKind returns the backup kind from an archive name (YYYY-MM-{kind}.tar.gz)
+func archiveKind(archive string) string {
+	name := strings.TrimSuffix(filepath.Base(archive), ".tar.gz")
+	if strings.HasSuffix(name, "-"+KindPatched) {
+		return KindPatched
+	}
+	return KindModified
+}
+
+// listArchive returns the backups stored in a monthly archive
+func listArchive(archive string) ([]Entry, error) {
+	kind := archiveKind(archive)
+
+	var entries []Entry
+	err := walkArchive(archive, func(header *tar.Header, r io.Reader) (bool, error) {
+		if header.Typeflag != tar.TypeReg {
+			return false, nil
+		}
+		original, t, ok := parseBackupName(header.Name)
+		if ok {
+			entries = append(entries, Entry{Kind: kind, Path: original, Time: t, Archive: archive, Member: header.Name})
+		}
+		return false, nil
+	})
+	return entries, err
+}
+
+// walkArchive calls fn for each entry of a tar.gz archive until fn returns true
+func walkArchive(archive string, fn func(header *tar.Header, r io.Reader) (bool, error)) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gzipReader, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		done, err := fn(header, tarReader)
+		if err != nil || done {
+			return err
+		}
+	}
+}
+
+// Read returns the content of a backup, extracting it from its archive if needed
+func Read(e Entry) ([]byte, error) {
+	if !e.Archived() {
+		return os.ReadFile(e.File)
+	}
+
+	var content []byte
+	found := false
+	err := walkArchive(e.Archive, func(header *tar.Header, r io.Reader) (bool, error) {
+		if header.Name != e.Member {
+			return false, nil
+		}
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return true, err
+		}
+		content, found = data, true
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("%s not found in %s", e.Member, filepath.Base(e.Archive))
+	}
+	return content, nil
+}
+
+// Find returns the backup selected by ref: "path@timestamp" for an exact backup,
+// or "path" for the most recent backup of that path
+func Find(entries []Entry, ref string) (*Entry, error) {
+	path, timestamp := ref, ""
+	if i := strings.LastIndex(ref, "@"); i >= 0 {
+		path, timestamp = ref[:i], ref[i+1:]
+	}
+	path = filepath.ToSlash(path)
+
+	// entries are newest first, so the first match is the latest
+	for i := range entries {
+		if entries[i].Path != path {
+			continue
+		}
+		if timestamp == "" || entries[i].Timestamp() == timestamp {
+			return &entries[i], nil
+		}
+	}
+
+	if timestamp != "" {
+		return nil, fmt.Errorf("no backup of %s at %s", path, timestamp)
+	}
+	return nil, fmt.Errorf("no backup of %s", path)
+}
@@ -0,0 +1,127 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeBackup creates a live backup file under backupDir/kind
+func writeBackup(t *testing.T, backupDir, kind, relPath, content string) {
+	t.Helper()
+	path := filepath.Join(backupDir, kind, relPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseBackupName(t *testing.T) {
+	tests := []struct {
+		relPath  string
+		want     string
+		wantTime string
+		ok       bool
+	}{
+		{"2026/01/09/apps/api/config.20260109_143022.json", "apps/api/config.json", "20260109_143022", true},
+		{"2026/01/09/apps/api/.20260109_143022.env", "apps/api/.env", "20260109_143022", true},
+		{"2026/01/09/Makefile.20260109_143022", "Makefile", "20260109_143022", true},
+		{"2026/01/09/apps/api/config.json.20260109_143022.patch", "apps/api/config.json.patch", "20260109_143022", true},
+		{"2026/01/09/apps/api/config.json", "", "", false},
+		{"config.20260109_143022.json", "", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ts, ok := parseBackupName(tt.relPath)
+		if ok != tt.ok {
+			t.Errorf("%s: expected ok=%v, got %v", tt.relPath, tt.ok, ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if got != tt.want || ts.Format(timestampLayout) != tt.wantTime {
+			t.Errorf("%s: expected %s@%s, got %s@%s", tt.relPath, tt.want, tt.wantTime, got, ts.Format(timestampLayout))
+		}
+	}
+}
+
+func TestList_LiveAndArchived(t *testing.T) {
+	backupDir := t.TempDir()
+
+	writeBackup(t, backupDir, KindModified, "2026/01/09/apps/api/config.20260109_143022.json", "new")
+	writeBackup(t, backupDir, KindPatched, "2026/01/09/apps/api/config.json.20260109_143022.patch", "patch")
+
+	// Older month, archived the same way sync does it
+	writeBackup(t, backupDir, KindModified, "2025/12/01/apps/api/config.20251201_090000.json", "old")
+	os.MkdirAll(filepath.Join(backupDir, "archived"), 0755)
+	archive := filepath.Join(backupDir, "archived", "2025-12-modified.tar.gz")
+	if err := createTarGz(filepath.Join(backupDir, KindModified), archive, "2025", "12"); err != nil {
+		t.Fatalf("createTarGz failed: %v", err)
+	}
+	os.RemoveAll(filepath.Join(backupDir, KindModified, "2025"))
+
+	entries, err := List(backupDir)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 backups, got %+v", entries)
+	}
+
+	// Newest first; backups taken at the same time are ordered by path
+	if entries[0].Path != "apps/api/config.json" || entries[0].Kind != KindModified {
+		t.Errorf("unexpected first entry: %+v", entries[0])
+	}
+	if entries[1].Kind != KindPatched {
+		t.Errorf("expected patch backup second, got %+v", entries[1])
+	}
+	old := entries[2]
+	if !old.Archived() || old.Ref() != "apps/api/config.json@20251201_090000" {
+		t.Errorf("expected archived backup last, got %+v", old)
+	}
+
+	content, err := Read(old)
+	if err != nil {
+		t.Fatalf("Read archived failed: %v", err)
+	}
+	if string(content) != "old" {
+		t.Errorf("expected archived content 'old', got %q", content)
+	}
+}
+
+func TestFind(t *testing.T) {
+	backupDir := t.TempDir()
+	writeBackup(t, backupDir, KindModified, "2026/01/09/config.20260109_100000.json", "first")
+	writeBackup(t, backupDir, KindModified, "2026/01/09/config.20260109_110000.json", "second")
+
+	entries, err := List(backupDir)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+
+	latest, err := Find(entries, "config.json")
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if latest.Timestamp() != "20260109_110000" {
+		t.Errorf("expected latest backup, got %s", latest.Timestamp())
+	}
+
+	exact, err := Find(entries, "config.json@20260109_100000")
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if content, _ := Read(*exact); string(content) != "first" {
+		t.Errorf("expected first backup, got %q", content)
+	}
+
+	if _, err := Find(entries, "config.json@20990101_000000"); err == nil {
+		t.Error("expected error for unknown timestamp")
+	}
+	if _, err := Find(entries, "missing.json"); err == nil {
+		t.Error("expected error for unknown path")
+	}
+}
@@ -0,0 +1,226 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yejune/git-multirepo/internal/common"
+)
+
+// Retention limits how much backup history is kept. Zero values mean unlimited.
+type Retention struct {
+	KeepDays   int   // Live backups (modified/, patched/) older than this are removed
+	KeepMonths int   // Archives of months older than this are removed
+	MaxSize    int64 // Oldest backups are removed until the backup directory fits (bytes)
+}
+
+// IsZero returns true if no limit is set
+func (r Retention) IsZero() bool {
+	return r.KeepDays <= 0 && r.KeepMonths <= 0 && r.MaxSize <= 0
+}
+
+// PrunedItem is a backup file or archive selected for removal
+type PrunedItem struct {
+	Path   string // Absolute path of the file
+	Size   int64
+	Reason string // "age", "archive age" or "size"
+}
+
+// PruneResult lists what Prune removed (or would remove with dry run)
+type PruneResult struct {
+	Items []PrunedItem
+	Freed int64 // Total bytes of Items
+	Total int64 // Size of the backup directory before pruning
+}
+
+// pruneCandidate is a file that may be pruned, with the time it represents
+type pruneCandidate struct {
+	path string
+	size int64
+	time time.Time
+}
+
+// ParseSize parses a size like "500MB", "2G", "1.5GB" or "1024" (bytes)
+func ParseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if s == "" {
+		return 0, nil
+	}
+
+	units := []struct {
+		suffix string
+		factor float64
+	}{
+		{"TB", 1 << 40}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10},
+		{"T", 1 << 40}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10},
+		{"B", 1},
+	}
+
+	factor := 1.0
+	for _, u := range units {
+		if strings.HasSuffix(s, u.suffix) {
+			factor = u.factor
+			s = strings.TrimSpace(strings.TrimSuffix(s, u.suffix))
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q (use e.g. 500MB or 2GB)", s)
+	}
+	return int64(n * factor), nil
+}
+
+// FormatSize formats bytes for display, e.g. "1.5 MB"
+func FormatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGT"[exp])
+}
+
+// Prune removes backups exceeding the retention limits, oldest first.
+// With dryRun, nothing is removed and the result lists what would be.
+func Prune(backupDir string, r Retention, dryRun bool) (*PruneResult, error) {
+	return prune(backupDir, r, dryRun, time.Now())
+}
+
+func prune(backupDir string, r Retention, dryRun bool, now time.Time) (*PruneResult, error) {
+	live, err := liveCandidates(backupDir)
+	if err != nil {
+		return nil, err
+	}
+	archives, err := archiveCandidates(backupDir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PruneResult{}
+	for _, c := range append(live, archives...) {
+		result.Total += c.size
+	}
+
+	selected := map[string]bool{}
+	add := func(c pruneCandidate, reason string) {
+		if selected[c.path] {
+			return
+		}
+		selected[c.path] = true
+		result.Items = append(result.Items, PrunedItem{Path: c.path, Size: c.size, Reason: reason})
+		result.Freed += c.size
+	}
+
+	if r.KeepDays > 0 {
+		cutoff := now.AddDate(0, 0, -r.KeepDays)
+		for _, c := range live {
+			if c.time.Before(cutoff) {
+				add(c, "age")
+			}
+		}
+	}
+
+	if r.KeepMonths > 0 {
+		// Keep the current month plus the previous KeepMonths-1 months
+		firstKept := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, -(r.KeepMonths - 1), 0)
+		for _, c := range archives {
+			if c.time.Before(firstKept) {
+				add(c, "archive age")
+			}
+		}
+	}
+
+	if r.MaxSize > 0 && result.Total-result.Freed > r.MaxSize {
+		all := append(live, archives...)
+		sort.SliceStable(all, func(i, j int) bool { return all[i].time.Before(all[j].time) })
+		for _, c := range all {
+			if result.Total-result.Freed <= r.MaxSize {
+				break
+			}
+			add(c, "size")
+		}
+	}
+
+	if dryRun {
+		return result, nil
+	}
+
+	for _, item := range result.Items {
+		if err := os.Remove(item.Path); err != nil && !os.IsNotExist(err) {
+			return result, fmt.Errorf("failed to remove %s: %w", item.Path, err)
+		}
+		common.RemoveEmptyParents(filepath.Dir(item.Path), backupDir)
+	}
+
+	return result, nil
+}
+
+// liveCandidates returns live backup files with the time parsed from their names
+func liveCandidates(backupDir string) ([]pruneCandidate, error) {
+	var candidates []pruneCandidate
+
+	for _, kind := range []string{KindModified, KindPatched} {
+		kindDir := filepath.Join(backupDir, kind)
+		if _, err := os.Stat(kindDir); os.IsNotExist(err) {
+			continue
+		}
+
+		err := filepath.Walk(kindDir, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			relPath, err := filepath.Rel(kindDir, path)
+			if err != nil {
+				return err
+			}
+			if _, t, ok := parseBackupName(relPath); ok {
+				candidates = append(candidates, pruneCandidate{path: path, size: info.Size(), time: t})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s backups: %w", kind, err)
+		}
+	}
+
+	return candidates, nil
+}
+
+// archiveCandidates returns monthly archives with the first day of their month
+func archiveCandidates(backupDir string) ([]pruneCandidate, error) {
+	archives, err := filepath.Glob(filepath.Join(backupDir, "archived", "*.tar.gz"))
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []pruneCandidate
+	for _, archive := range archives {
+		// Archive names start with YYYY-MM
+		name := filepath.Base(archive)
+		if len(name) < 7 {
+			continue
+		}
+		month, err := time.ParseInLocation("2006-01", name[:7], time.Local)
+		if err != nil {
+			continue
+		}
+		info, err := os.Stat(archive)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, pruneCandidate{path: archive, size: info.Size(), time: month})
+	}
+
+	return candidates, nil
+}
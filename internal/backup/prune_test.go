@@ -0,0 +1,137 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+		ok   bool
+	}{
+		{"", 0, true},
+		{"1024", 1024, true},
+		{"10KB", 10 << 10, true},
+		{"500MB", 500 << 20, true},
+		{"500mb", 500 << 20, true},
+		{"2G", 2 << 30, true},
+		{"1.5GB", 3 << 29, true},
+		{"lots", 0, false},
+		{"-1MB", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSize(tt.in)
+		if (err == nil) != tt.ok {
+			t.Errorf("ParseSize(%q): unexpected error %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+// setupPruneBackups creates live backups and archives around 2026-03-15
+func setupPruneBackups(t *testing.T) string {
+	t.Helper()
+	backupDir := t.TempDir()
+
+	writeBackup(t, backupDir, KindModified, "2026/03/14/app/config.20260314_100000.json", "recent")
+	writeBackup(t, backupDir, KindModified, "2026/03/01/app/config.20260301_100000.json", "older")
+	writeBackup(t, backupDir, KindPatched, "2026/02/20/app/config.json.20260220_100000.patch", "oldest")
+
+	os.MkdirAll(filepath.Join(backupDir, "archived"), 0755)
+	for _, name := range []string{"2025-06-modified.tar.gz", "2026-01-modified.tar.gz"} {
+		os.WriteFile(filepath.Join(backupDir, "archived", name), []byte("archive"), 0644)
+	}
+	return backupDir
+}
+
+// prunedNames returns the base names of pruned items
+func prunedNames(result *PruneResult) string {
+	var names []string
+	for _, item := range result.Items {
+		names = append(names, filepath.Base(item.Path))
+	}
+	return strings.Join(names, ",")
+}
+
+func TestPrune(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name      string
+		retention Retention
+		want      string
+	}{
+		{"no limits", Retention{}, ""},
+		{"keep days", Retention{KeepDays: 7}, "config.20260301_100000.json,config.json.20260220_100000.patch"},
+		{"keep months", Retention{KeepMonths: 3}, "2025-06-modified.tar.gz"},
+		// 6+5+6+7+7 = 31 bytes in total
+		{"max size removes oldest", Retention{MaxSize: 20}, "2025-06-modified.tar.gz,2026-01-modified.tar.gz"},
+		{"combined", Retention{KeepDays: 7, MaxSize: 10}, "config.20260301_100000.json,config.json.20260220_100000.patch,2025-06-modified.tar.gz,2026-01-modified.tar.gz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backupDir := setupPruneBackups(t)
+
+			result, err := prune(backupDir, tt.retention, false, now)
+			if err != nil {
+				t.Fatalf("prune failed: %v", err)
+			}
+			if got := prunedNames(result); got != tt.want {
+				t.Errorf("expected %q pruned, got %q", tt.want, got)
+			}
+			for _, item := range result.Items {
+				if _, err := os.Stat(item.Path); !os.IsNotExist(err) {
+					t.Errorf("%s should be removed", item.Path)
+				}
+			}
+		})
+	}
+}
+
+func TestPrune_DryRunAndEmptyDirs(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.Local)
+	backupDir := setupPruneBackups(t)
+
+	result, err := prune(backupDir, Retention{KeepDays: 7}, true, now)
+	if err != nil {
+		t.Fatalf("prune failed: %v", err)
+	}
+	if len(result.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(result.Items))
+	}
+	for _, item := range result.Items {
+		if _, err := os.Stat(item.Path); err != nil {
+			t.Errorf("dry run should not remove %s", item.Path)
+		}
+	}
+
+	// Keep only the current month of archives as well
+	if _, err := prune(backupDir, Retention{KeepDays: 7, KeepMonths: 1}, false, now); err != nil {
+		t.Fatalf("prune failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(backupDir, KindPatched)); !os.IsNotExist(err) {
+		t.Error("empty backup directories should be removed")
+	}
+	if _, err := os.Stat(filepath.Join(backupDir, KindModified, "2026", "03", "14")); err != nil {
+		t.Error("directories with remaining backups should be kept")
+	}
+
+	// Remaining backups are still listed
+	entries, err := List(backupDir)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Path != "app/config.json" {
+		t.Errorf("expected only the recent backup to remain, got %+v", entries)
+	}
+}
@@ -0,0 +1,105 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/yejune/git-multirepo/internal/common"
+)
+
+// Relocate moves the live backups of files under oldPath to newPath, for a
+// workspace that moved. oldPath and newPath are relative to the repository root,
+// as recorded by CreateFileBackup and CreatePatchBackup. Archived backups keep
+// their original path. Returns the number of backup files moved.
+func Relocate(backupDir, oldPath, newPath string) (int, error) {
+	moved := 0
+	for _, kind := range []string{KindModified, KindPatched} {
+		days, err := filepath.Glob(filepath.Join(backupDir, kind, "*", "*", "*"))
+		if err != nil {
+			return moved, err
+		}
+		for _, day := range days {
+			n, err := MoveTree(filepath.Join(day, oldPath), filepath.Join(day, newPath))
+			moved += n
+			if err != nil {
+				return moved, err
+			}
+			common.RemoveEmptyParents(filepath.Dir(filepath.Join(day, oldPath)), day)
+		}
+	}
+	return moved, nil
+}
+
+// MoveTree moves the files under src to the same relative paths under dst,
+// merging with what dst already contains, and removes the emptied directories
+// of src. A file that exists at its destination is left in src.
+// A missing src is not an error. Returns the number of files moved.
+func MoveTree(src, dst string) (int, error) {
+	info, err := os.Lstat(src)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !info.IsDir() {
+		return 0, nil
+	}
+
+	// Fast path: nothing to merge with
+	if _, err := os.Lstat(dst); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return 0, err
+		}
+		n := countFiles(src)
+		if err := os.Rename(src, dst); err != nil {
+			return 0, err
+		}
+		return n, nil
+	}
+
+	moved := 0
+	var dirs []string
+	err = filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			dirs = append(dirs, path)
+			return nil
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if _, err := os.Lstat(target); err == nil {
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := os.Rename(path, target); err != nil {
+			return err
+		}
+		moved++
+		return nil
+	})
+
+	// Deepest first; only empty directories are removed
+	for i := len(dirs) - 1; i >= 0; i-- {
+		os.Remove(dirs[i])
+	}
+	return moved, err
+}
+
+func countFiles(dir string) int {
+	n := 0
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			n++
+		}
+		return nil
+	})
+	return n
+}
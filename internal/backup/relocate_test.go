@@ -0,0 +1,54 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRelocate(t *testing.T) {
+	backupDir := t.TempDir()
+	files := []string{
+		"modified/2026/01/09/packages/lib/config.20260109_143022.yml",
+		"modified/2026/01/10/packages/lib/sub/.env.20260110_090000",
+		"patched/2026/01/10/packages/lib/config.yml.20260110_090000.patch",
+		"modified/2026/01/10/packages/lib2/config.20260110_090000.yml",
+		// Already at the destination: merged
+		"modified/2026/01/10/libs/core/old.20260110_080000.txt",
+	}
+	for _, f := range files {
+		path := filepath.Join(backupDir, filepath.FromSlash(f))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(f), 0644)
+	}
+
+	moved, err := Relocate(backupDir, "packages/lib", "libs/core")
+	if err != nil {
+		t.Fatalf("Relocate failed: %v", err)
+	}
+	if moved != 3 {
+		t.Errorf("expected 3 backups moved, got %d", moved)
+	}
+
+	for _, f := range []string{
+		"modified/2026/01/09/libs/core/config.20260109_143022.yml",
+		"modified/2026/01/10/libs/core/sub/.env.20260110_090000",
+		"modified/2026/01/10/libs/core/old.20260110_080000.txt",
+		"patched/2026/01/10/libs/core/config.yml.20260110_090000.patch",
+		"modified/2026/01/10/packages/lib2/config.20260110_090000.yml",
+	} {
+		if _, err := os.Stat(filepath.Join(backupDir, filepath.FromSlash(f))); err != nil {
+			t.Errorf("expected %s", f)
+		}
+	}
+	for _, dir := range []string{"modified/2026/01/09/packages", "modified/2026/01/10/packages/lib", "patched/2026/01/10/packages"} {
+		if _, err := os.Stat(filepath.Join(backupDir, filepath.FromSlash(dir))); !os.IsNotExist(err) {
+			t.Errorf("emptied directory %s should be removed", dir)
+		}
+	}
+
+	entries, err := List(backupDir)
+	if err != nil || len(entries) != 5 {
+		t.Fatalf("List() = %d entries, %v", len(entries), err)
+	}
+}
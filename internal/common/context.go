@@ -21,20 +21,34 @@ func LoadWorkspaceContext() (*WorkspaceContext, error) {
 		return nil, fmt.Errorf("not in a git repository: %w", err)
 	}
 
+	ctx, err := LoadWorkspaceContextAt(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	i18n.SetLanguage(ctx.Manifest.GetLanguage())
+
+	return ctx, nil
+}
+
+// LoadWorkspaceContextAt loads the manifest of the repository at repoRoot,
+// e.g. a workspace with its own manifest. The language setting is not applied.
+func LoadWorkspaceContextAt(repoRoot string) (*WorkspaceContext, error) {
 	m, err := manifest.Load(repoRoot)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load manifest: %w", err)
 	}
 
-	i18n.SetLanguage(m.GetLanguage())
-
 	return &WorkspaceContext{
 		RepoRoot: repoRoot,
 		Manifest: m,
 	}, nil
 }
 
-// SaveManifest saves the current manifest to disk
+// SaveManifest saves the current manifest to disk under the manifest lock.
+// Prefer manifest.Update for read-modify-write so concurrent changes are kept.
 func (ctx *WorkspaceContext) SaveManifest() error {
-	return manifest.Save(ctx.RepoRoot, ctx.Manifest)
+	return manifest.WithLock(ctx.RepoRoot, func() error {
+		return manifest.Save(ctx.RepoRoot, ctx.Manifest)
+	})
 }
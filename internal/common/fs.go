@@ -0,0 +1,18 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RemoveEmptyParents removes dir and its parents as long as they are empty,
+// stopping at root, which is never removed
+func RemoveEmptyParents(dir, root string) {
+	for dir != root && strings.HasPrefix(dir, root+string(filepath.Separator)) {
+		if err := os.Remove(dir); err != nil {
+			return // Not empty
+		}
+		dir = filepath.Dir(dir)
+	}
+}
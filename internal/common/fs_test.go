@@ -0,0 +1,34 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRemoveEmptyParents(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "a", "b", "c"), 0755)
+	os.WriteFile(filepath.Join(root, "a", "keep.txt"), nil, 0644)
+
+	RemoveEmptyParents(filepath.Join(root, "a", "b", "c"), root)
+	if _, err := os.Stat(filepath.Join(root, "a", "b")); !os.IsNotExist(err) {
+		t.Error("empty directories should be removed")
+	}
+	if _, err := os.Stat(filepath.Join(root, "a")); err != nil {
+		t.Error("a directory that is not empty should be kept")
+	}
+
+	// Outside of root nothing is removed, nor root itself
+	other := filepath.Join(root+"-other", "x")
+	os.MkdirAll(other, 0755)
+	defer os.RemoveAll(root + "-other")
+	RemoveEmptyParents(other, root)
+	RemoveEmptyParents(root, root)
+	if _, err := os.Stat(other); err != nil {
+		t.Error("directories outside root should be kept")
+	}
+	if _, err := os.Stat(root); err != nil {
+		t.Error("root should be kept")
+	}
+}
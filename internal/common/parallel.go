@@ -0,0 +1,92 @@
+package common
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+	"runtime"
+
+	"github.com/yejune/git-multirepo/internal/manifest"
+)
+
+// BufferedWorkspaceHandler processes a workspace entry and writes its output to out
+type BufferedWorkspaceHandler func(ws *manifest.WorkspaceEntry, fullPath string, out io.Writer) error
+
+// DefaultJobs returns the default number of concurrent workers
+func DefaultJobs() int {
+	return runtime.NumCPU()
+}
+
+// RunWorkspacesParallel runs handler for each workspace using at most jobs concurrent workers.
+// Each handler writes to its own buffer; buffers are flushed to w in the order of
+// workspaces as soon as all preceding workspaces have finished, so output never interleaves.
+// Returns one error per workspace (nil on success), in the same order as workspaces.
+func (ctx *WorkspaceContext) RunWorkspacesParallel(workspaces []manifest.WorkspaceEntry, jobs int, w io.Writer, handler BufferedWorkspaceHandler) []error {
+	errs := make([]error, len(workspaces))
+	if len(workspaces) == 0 {
+		return errs
+	}
+
+	if jobs <= 0 {
+		jobs = DefaultJobs()
+	}
+	if jobs > len(workspaces) {
+		jobs = len(workspaces)
+	}
+
+	type result struct {
+		index int
+		buf   *bytes.Buffer
+		err   error
+	}
+
+	indexes := make(chan int)
+	results := make(chan result)
+
+	// Start workers
+	for i := 0; i < jobs; i++ {
+		go func() {
+			for idx := range indexes {
+				ws := &workspaces[idx]
+				buf := &bytes.Buffer{}
+				err := handler(ws, filepath.Join(ctx.RepoRoot, ws.Path), buf)
+				results <- result{index: idx, buf: buf, err: err}
+			}
+		}()
+	}
+
+	// Feed work
+	go func() {
+		for i := range workspaces {
+			indexes <- i
+		}
+		close(indexes)
+	}()
+
+	// Collect results and flush in order
+	pending := make(map[int]*bytes.Buffer)
+	next := 0
+	for received := 0; received < len(workspaces); received++ {
+		r := <-results
+		errs[r.index] = r.err
+		pending[r.index] = r.buf
+
+		for {
+			buf, ok := pending[next]
+			if !ok {
+				break
+			}
+			w.Write(buf.Bytes())
+			delete(pending, next)
+			next++
+		}
+	}
+
+	return errs
+}
+
+// ForEachWorkspaceParallel runs handler for all workspaces in the manifest concurrently.
+// See RunWorkspacesParallel for ordering guarantees.
+func (ctx *WorkspaceContext) ForEachWorkspaceParallel(jobs int, w io.Writer, handler BufferedWorkspaceHandler) []error {
+	return ctx.RunWorkspacesParallel(ctx.Manifest.Workspaces, jobs, w, handler)
+}
@@ -0,0 +1,116 @@
+package common
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yejune/git-multirepo/internal/manifest"
+)
+
+func newTestContext(paths ...string) *WorkspaceContext {
+	m := &manifest.Manifest{}
+	for _, p := range paths {
+		m.Add(p, "https://example.com/"+p+".git")
+	}
+	return &WorkspaceContext{RepoRoot: "/repo", Manifest: m}
+}
+
+func TestRunWorkspacesParallel_OutputInManifestOrder(t *testing.T) {
+	ctx := newTestContext("a", "b", "c", "d", "e")
+
+	var buf bytes.Buffer
+	errs := ctx.ForEachWorkspaceParallel(5, &buf, func(ws *manifest.WorkspaceEntry, fullPath string, out io.Writer) error {
+		// Earlier workspaces finish last
+		delay := map[string]time.Duration{"a": 40, "b": 30, "c": 20, "d": 10, "e": 0}[ws.Path]
+		time.Sleep(delay * time.Millisecond)
+		fmt.Fprintf(out, "%s:start\n", ws.Path)
+		fmt.Fprintf(out, "%s:end\n", ws.Path)
+		return nil
+	})
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("workspace %d: unexpected error: %v", i, err)
+		}
+	}
+
+	expected := "a:start\na:end\nb:start\nb:end\nc:start\nc:end\nd:start\nd:end\ne:start\ne:end\n"
+	if buf.String() != expected {
+		t.Errorf("output not in manifest order:\n%s", buf.String())
+	}
+}
+
+func TestRunWorkspacesParallel_RespectsJobLimit(t *testing.T) {
+	ctx := newTestContext("a", "b", "c", "d", "e", "f")
+
+	var running, maxRunning int32
+	ctx.ForEachWorkspaceParallel(2, io.Discard, func(ws *manifest.WorkspaceEntry, fullPath string, out io.Writer) error {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return nil
+	})
+
+	if maxRunning > 2 {
+		t.Errorf("expected at most 2 concurrent workers, got %d", maxRunning)
+	}
+}
+
+func TestRunWorkspacesParallel_CollectsErrors(t *testing.T) {
+	ctx := newTestContext("ok", "fail", "ok2")
+
+	errs := ctx.ForEachWorkspaceParallel(0, io.Discard, func(ws *manifest.WorkspaceEntry, fullPath string, out io.Writer) error {
+		if ws.Path == "fail" {
+			return errors.New("boom")
+		}
+		return nil
+	})
+
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(errs))
+	}
+	if errs[0] != nil || errs[2] != nil {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	if errs[1] == nil || !strings.Contains(errs[1].Error(), "boom") {
+		t.Errorf("expected error for 'fail', got %v", errs[1])
+	}
+}
+
+func TestRunWorkspacesParallel_FullPath(t *testing.T) {
+	ctx := newTestContext("packages/lib")
+
+	var got string
+	ctx.ForEachWorkspaceParallel(1, io.Discard, func(ws *manifest.WorkspaceEntry, fullPath string, out io.Writer) error {
+		got = fullPath
+		return nil
+	})
+
+	if got != "/repo/packages/lib" {
+		t.Errorf("expected full path /repo/packages/lib, got %s", got)
+	}
+}
+
+func TestRunWorkspacesParallel_Empty(t *testing.T) {
+	ctx := newTestContext()
+
+	errs := ctx.ForEachWorkspaceParallel(4, io.Discard, func(ws *manifest.WorkspaceEntry, fullPath string, out io.Writer) error {
+		t.Error("handler should not be called")
+		return nil
+	})
+	if len(errs) != 0 {
+		t.Errorf("expected no results, got %d", len(errs))
+	}
+}
@@ -1,7 +1,6 @@
 package common
 
 import (
-	"fmt"
 	"path/filepath"
 
 	"github.com/yejune/git-multirepo/internal/manifest"
@@ -37,18 +36,12 @@ func (ctx *WorkspaceContext) ForEachWorkspaceWithContinue(handler WorkspaceHandl
 
 // FilterWorkspaces returns workspaces filtered by command-line arguments
 // If no args provided, returns all workspaces
-// If args provided, returns only the matching workspace by path
+// If args provided, each arg may be an exact path, a parent directory or a glob pattern
 func (ctx *WorkspaceContext) FilterWorkspaces(args []string) ([]manifest.WorkspaceEntry, error) {
-	if len(args) == 0 {
-		return ctx.Manifest.Workspaces, nil
-	}
-
-	targetPath := args[0]
-	for _, ws := range ctx.Manifest.Workspaces {
-		if ws.Path == targetPath {
-			return []manifest.WorkspaceEntry{ws}, nil
-		}
-	}
+	return ctx.SelectWorkspaces(Selector{Patterns: args})
+}
 
-	return nil, fmt.Errorf("workspace not found: %s", targetPath)
+// SelectWorkspaces returns the workspaces matching sel, in manifest order
+func (ctx *WorkspaceContext) SelectWorkspaces(sel Selector) ([]manifest.WorkspaceEntry, error) {
+	return sel.Select(ctx.Manifest.Workspaces)
 }
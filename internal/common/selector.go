@@ -0,0 +1,110 @@
+package common
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/yejune/git-multirepo/internal/i18n"
+	"github.com/yejune/git-multirepo/internal/manifest"
+)
+
+// Selector selects a subset of workspaces by path and group.
+// A workspace is selected if it matches any of Patterns (or Patterns is empty)
+// and belongs to any of Groups (or Groups is empty).
+type Selector struct {
+	// Patterns are exact paths, parent directories or glob patterns (e.g. "packages/*")
+	Patterns []string
+	// Groups are group names from the workspace "groups" field
+	Groups []string
+}
+
+// IsEmpty returns true if the selector selects every workspace
+func (s Selector) IsEmpty() bool {
+	return len(s.Patterns) == 0 && len(s.Groups) == 0
+}
+
+// Matches checks if a workspace is selected
+func (s Selector) Matches(ws manifest.WorkspaceEntry) bool {
+	if len(s.Patterns) > 0 {
+		matched := false
+		for _, pattern := range s.Patterns {
+			if MatchPath(pattern, ws.Path) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if len(s.Groups) > 0 {
+		for _, group := range s.Groups {
+			if ws.InGroup(group) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// MatchPath checks if a workspace path equals pattern, is inside the directory
+// pattern, or matches pattern as a glob
+func MatchPath(pattern, path string) bool {
+	pattern = strings.TrimSuffix(filepath.ToSlash(pattern), "/")
+	if pattern == path || strings.HasPrefix(path, pattern+"/") {
+		return true
+	}
+	matched, _ := filepath.Match(pattern, path)
+	return matched
+}
+
+// Select returns workspaces matching the selector, in manifest order.
+// Returns an error if a pattern or group matches no workspace at all.
+func (s Selector) Select(workspaces []manifest.WorkspaceEntry) ([]manifest.WorkspaceEntry, error) {
+	if s.IsEmpty() {
+		return workspaces, nil
+	}
+
+	for _, pattern := range s.Patterns {
+		found := false
+		for _, ws := range workspaces {
+			if MatchPath(pattern, ws.Path) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%s", i18n.T("sub_not_found", pattern))
+		}
+	}
+
+	for _, group := range s.Groups {
+		found := false
+		for _, ws := range workspaces {
+			if ws.InGroup(group) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%s", i18n.T("no_subs_in_group", group))
+		}
+	}
+
+	var selected []manifest.WorkspaceEntry
+	for _, ws := range workspaces {
+		if s.Matches(ws) {
+			selected = append(selected, ws)
+		}
+	}
+
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("%s", i18n.T("no_subs_selected"))
+	}
+
+	return selected, nil
+}
@@ -0,0 +1,117 @@
+package common
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yejune/git-multirepo/internal/manifest"
+)
+
+func testSelectorWorkspaces() []manifest.WorkspaceEntry {
+	return []manifest.WorkspaceEntry{
+		{Path: "apps/web", Groups: []string{"frontend"}},
+		{Path: "apps/api", Groups: []string{"backend"}},
+		{Path: "packages/lib", Groups: []string{"backend", "shared"}},
+		{Path: "packages/utils"},
+	}
+}
+
+func paths(workspaces []manifest.WorkspaceEntry) string {
+	var p []string
+	for _, ws := range workspaces {
+		p = append(p, ws.Path)
+	}
+	return strings.Join(p, ",")
+}
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"apps/web", "apps/web", true},
+		{"apps", "apps/web", true},
+		{"apps/", "apps/web", true},
+		{"app", "apps/web", false},
+		{"apps/*", "apps/web", true},
+		{"*/lib", "packages/lib", true},
+		{"packages/l*", "packages/utils", false},
+		{"apps/web/", "apps/web", true},
+	}
+
+	for _, tt := range tests {
+		if got := MatchPath(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("MatchPath(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestSelector_Select(t *testing.T) {
+	workspaces := testSelectorWorkspaces()
+
+	tests := []struct {
+		name     string
+		selector Selector
+		want     string
+	}{
+		{"empty selects all", Selector{}, "apps/web,apps/api,packages/lib,packages/utils"},
+		{"exact path", Selector{Patterns: []string{"apps/api"}}, "apps/api"},
+		{"multiple paths keep manifest order", Selector{Patterns: []string{"packages/utils", "apps/web"}}, "apps/web,packages/utils"},
+		{"directory prefix", Selector{Patterns: []string{"packages"}}, "packages/lib,packages/utils"},
+		{"glob", Selector{Patterns: []string{"*/lib"}}, "packages/lib"},
+		{"group", Selector{Groups: []string{"backend"}}, "apps/api,packages/lib"},
+		{"multiple groups", Selector{Groups: []string{"frontend", "shared"}}, "apps/web,packages/lib"},
+		{"path and group", Selector{Patterns: []string{"packages/*"}, Groups: []string{"backend"}}, "packages/lib"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, err := tt.selector.Select(workspaces)
+			if err != nil {
+				t.Fatalf("Select failed: %v", err)
+			}
+			if got := paths(selected); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSelector_SelectErrors(t *testing.T) {
+	workspaces := testSelectorWorkspaces()
+
+	tests := []struct {
+		name     string
+		selector Selector
+		want     string
+	}{
+		{"unknown path", Selector{Patterns: []string{"apps/missing"}}, "apps/missing"},
+		{"unknown group", Selector{Groups: []string{"infra"}}, "infra"},
+		{"no overlap", Selector{Patterns: []string{"apps/web"}, Groups: []string{"backend"}}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.selector.Select(workspaces)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error should mention %q, got: %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestSelectWorkspaces_UsesManifest(t *testing.T) {
+	ctx := newTestContext("apps/web", "packages/lib")
+
+	selected, err := ctx.SelectWorkspaces(Selector{Patterns: []string{"packages/*"}})
+	if err != nil {
+		t.Fatalf("SelectWorkspaces failed: %v", err)
+	}
+	if got := paths(selected); got != "packages/lib" {
+		t.Errorf("expected packages/lib, got %s", got)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
@@ -108,3 +109,119 @@ func NormalizeRepoName(name string) (string, error) {
 
 	return name, nil
 }
+
+// BackupSettings holds backup retention values from config (zero or empty if not set)
+type BackupSettings struct {
+	KeepDays   int    // backup.keepDays
+	KeepMonths int    // backup.keepMonths
+	MaxSize    string // backup.maxSize, e.g. "500MB"
+}
+
+// GetBackupSettings reads backup.keepDays, backup.keepMonths and backup.maxSize
+// from config (all optional)
+func GetBackupSettings() (BackupSettings, error) {
+	var settings BackupSettings
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return settings, err
+	}
+
+	configPath := filepath.Join(home, ".git.multirepo")
+
+	get := func(key string) string {
+		out, err := exec.Command("git", "config", "-f", configPath, "--get", key).Output()
+		if err != nil {
+			return "" // Not set - no error
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	for key, target := range map[string]*int{
+		"backup.keepDays":   &settings.KeepDays,
+		"backup.keepMonths": &settings.KeepMonths,
+	} {
+		value := get(key)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return settings, fmt.Errorf("invalid %s in ~/.git.multirepo: %q", key, value)
+		}
+		*target = n
+	}
+
+	settings.MaxSize = get("backup.maxSize")
+
+	return settings, nil
+}
+
+// CacheSettings holds the mirror cache settings from config
+type CacheSettings struct {
+	Disabled bool   // cache.enabled = false
+	Dir      string // cache.dir, "" for the default location
+}
+
+// GetCacheSettings reads cache.enabled and cache.dir from config (both optional)
+func GetCacheSettings() (CacheSettings, error) {
+	var settings CacheSettings
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return settings, err
+	}
+
+	configPath := filepath.Join(home, ".git.multirepo")
+
+	// --type lets git parse booleans (yes/off/1...) and expand ~/ in paths
+	get := func(key, typ string) (string, error) {
+		out, err := exec.Command("git", "config", "-f", configPath, "--type="+typ, "--get", key).Output()
+		if err != nil {
+			if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+				return "", nil // Not set - no error
+			}
+			return "", fmt.Errorf("invalid %s in ~/.git.multirepo", key)
+		}
+		return strings.TrimSpace(string(out)), nil
+	}
+
+	enabled, err := get("cache.enabled", "bool")
+	if err != nil {
+		return settings, err
+	}
+	settings.Disabled = enabled == "false"
+
+	settings.Dir, err = get("cache.dir", "path")
+	return settings, err
+}
+
+// GetURLRewrites reads url.<base>.insteadOf rules from config (optional)
+// Returns: {"git@github.com:": "https://github.com/"}, mapping each prefix to its replacement
+func GetURLRewrites() (map[string]string, error) {
+	rewrites := make(map[string]string)
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return rewrites, err
+	}
+
+	configPath := filepath.Join(home, ".git.multirepo")
+
+	out, err := exec.Command("git", "config", "-f", configPath,
+		"--get-regexp", `^url\..*\.insteadof$`).Output()
+	if err != nil {
+		return rewrites, nil // Not set - no error
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		key, insteadOf, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		base := strings.TrimSuffix(strings.TrimPrefix(key, "url."), ".insteadof")
+		rewrites[insteadOf] = base
+	}
+
+	return rewrites, nil
+}
@@ -371,3 +371,116 @@ func TestNormalizeRepoName_PrefixBeforeSuffix(t *testing.T) {
 		}
 	})
 }
+
+// ============================================================================
+// Test Cases: GetBackupSettings
+// ============================================================================
+
+func TestGetBackupSettings(t *testing.T) {
+	t.Run("all settings", func(t *testing.T) {
+		configPath, cleanup := setupTempConfig(t, "https://github.com/test-org", "", "")
+		defer cleanup()
+
+		for key, value := range map[string]string{
+			"backup.keepDays":   "30",
+			"backup.keepMonths": "6",
+			"backup.maxSize":    "500MB",
+		} {
+			if err := exec.Command("git", "config", "-f", configPath, key, value).Run(); err != nil {
+				t.Fatalf("Failed to set %s: %v", key, err)
+			}
+		}
+
+		settings, err := GetBackupSettings()
+		if err != nil {
+			t.Fatalf("GetBackupSettings() unexpected error: %v", err)
+		}
+		want := BackupSettings{KeepDays: 30, KeepMonths: 6, MaxSize: "500MB"}
+		if settings != want {
+			t.Errorf("GetBackupSettings() = %+v, want %+v", settings, want)
+		}
+	})
+
+	t.Run("not set", func(t *testing.T) {
+		_, cleanup := setupTempConfig(t, "https://github.com/test-org", "", "")
+		defer cleanup()
+
+		settings, err := GetBackupSettings()
+		if err != nil {
+			t.Fatalf("GetBackupSettings() unexpected error: %v", err)
+		}
+		if settings != (BackupSettings{}) {
+			t.Errorf("GetBackupSettings() = %+v, want zero value", settings)
+		}
+	})
+
+	t.Run("invalid number", func(t *testing.T) {
+		configPath, cleanup := setupTempConfig(t, "https://github.com/test-org", "", "")
+		defer cleanup()
+
+		exec.Command("git", "config", "-f", configPath, "backup.keepDays", "month").Run()
+
+		if _, err := GetBackupSettings(); err == nil {
+			t.Error("GetBackupSettings() expected error for invalid keepDays")
+		}
+	})
+}
+
+func TestGetCacheSettings(t *testing.T) {
+	t.Run("disabled with custom dir", func(t *testing.T) {
+		configPath, cleanup := setupTempConfig(t, "https://github.com/test-org", "", "")
+		defer cleanup()
+
+		exec.Command("git", "config", "-f", configPath, "cache.enabled", "off").Run()
+		exec.Command("git", "config", "-f", configPath, "cache.dir", "~/mirrors").Run()
+
+		settings, err := GetCacheSettings()
+		if err != nil {
+			t.Fatalf("GetCacheSettings() unexpected error: %v", err)
+		}
+		want := CacheSettings{Disabled: true, Dir: filepath.Join(filepath.Dir(configPath), "mirrors")}
+		if settings != want {
+			t.Errorf("GetCacheSettings() = %+v, want %+v", settings, want)
+		}
+	})
+
+	t.Run("not set", func(t *testing.T) {
+		_, cleanup := setupTempConfig(t, "", "", "")
+		defer cleanup()
+
+		settings, err := GetCacheSettings()
+		if err != nil {
+			t.Fatalf("GetCacheSettings() unexpected error: %v", err)
+		}
+		if settings != (CacheSettings{}) {
+			t.Errorf("GetCacheSettings() = %+v, want zero value", settings)
+		}
+	})
+
+	t.Run("invalid boolean", func(t *testing.T) {
+		configPath, cleanup := setupTempConfig(t, "https://github.com/test-org", "", "")
+		defer cleanup()
+
+		exec.Command("git", "config", "-f", configPath, "cache.enabled", "maybe").Run()
+
+		if _, err := GetCacheSettings(); err == nil {
+			t.Error("GetCacheSettings() expected error for invalid cache.enabled")
+		}
+	})
+}
+
+func TestGetURLRewrites(t *testing.T) {
+	configPath, cleanup := setupTempConfig(t, "https://github.com/test-org", "", "")
+	defer cleanup()
+
+	exec.Command("git", "config", "-f", configPath, "url.https://proxy.corp/GitHub/.insteadOf", "https://github.com/").Run()
+	exec.Command("git", "config", "-f", configPath, "--add", "url.https://github.com/.insteadOf", "git@github.com:").Run()
+
+	rewrites, err := GetURLRewrites()
+	if err != nil {
+		t.Fatalf("GetURLRewrites() unexpected error: %v", err)
+	}
+	if len(rewrites) != 2 || rewrites["https://github.com/"] != "https://proxy.corp/GitHub/" || rewrites["git@github.com:"] != "https://github.com/" {
+		t.Errorf("GetURLRewrites() = %v", rewrites)
+	}
+}
@@ -0,0 +1,317 @@
+// Package discover finds git repositories in a directory tree
+package discover
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Kind tells how a found repository keeps its git data
+type Kind int
+
+const (
+	// KindRepo is a repository with its own .git directory
+	KindRepo Kind = iota
+	// KindWorktree is a linked worktree of another repository (.git file)
+	KindWorktree
+	// KindSubmodule is a submodule whose git directory lives in its superproject (.git file)
+	KindSubmodule
+	// KindGitFile is a repository whose .git file points to a separate git directory
+	KindGitFile
+)
+
+// Repo is a repository found by Scan
+type Repo struct {
+	Path   string // Relative to the scanned root, with forward slashes
+	Kind   Kind
+	GitDir string // Where the .git file points to (not set for KindRepo)
+}
+
+// Options control Scan. The zero value scans the whole tree without
+// following symlinks.
+type Options struct {
+	MaxDepth       int                   // Directory levels below the root to look at, 0 for unlimited
+	Exclude        []string              // Directories to skip, see Excluded
+	Ignored        map[string]bool       // Directories to skip, relative with forward slashes (e.g. from .gitignore)
+	Skip           func(rel string) bool // Directories not to descend into, e.g. known repositories
+	FollowSymlinks bool                  // Descend into symlinked directories outside the root
+	Jobs           int                   // Directories read concurrently, 0 for the number of CPUs
+}
+
+// Scan walks the tree below root and returns the repositories in it, sorted by
+// path. The root's own .git is not reported, and repositories are not descended
+// into. Directories that cannot be read are skipped.
+func Scan(root string, opts Options) ([]Repo, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := opts.Jobs
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+	s := &scanner{
+		opts:    opts,
+		visited: make(map[string]bool),
+	}
+	s.cond = sync.NewCond(&s.queueMu)
+	if opts.FollowSymlinks {
+		if real, err := filepath.EvalSymlinks(root); err == nil {
+			s.realRoot = real
+		}
+	}
+
+	s.descend(root, "", 0, entries)
+	var wg sync.WaitGroup
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.work()
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(s.repos, func(i, j int) bool { return s.repos[i].Path < s.repos[j].Path })
+	return s.repos, nil
+}
+
+// Excluded reports whether the directory rel (relative, with forward slashes)
+// matches one of patterns. Patterns are gitignore-style globs: one without a
+// slash matches a directory of that name at any depth, one with a slash (or a
+// leading "/") matches the path from the root; a leading "**/" matches at any
+// depth. A trailing "/" is ignored.
+func Excluded(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(filepath.ToSlash(strings.TrimSpace(pattern)), "/")
+		anywhere := strings.HasPrefix(pattern, "**/")
+		pattern = strings.TrimPrefix(pattern, "**/")
+		if pattern == "" {
+			continue
+		}
+
+		switch {
+		case !strings.Contains(pattern, "/"):
+			if matched, _ := path.Match(pattern, path.Base(rel)); matched {
+				return true
+			}
+		case anywhere:
+			parts := strings.Split(rel, "/")
+			for i := range parts {
+				if matched, _ := path.Match(pattern, strings.Join(parts[i:], "/")); matched {
+					return true
+				}
+			}
+		default:
+			if matched, _ := path.Match(strings.TrimPrefix(pattern, "/"), rel); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type scanner struct {
+	realRoot string // root with symlinks resolved, when following symlinks
+	opts     Options
+
+	queueMu sync.Mutex
+	cond    *sync.Cond // Signals queued tasks, or that none are left
+	queue   []task
+	pending int // Tasks queued or being visited
+
+	mu      sync.Mutex
+	repos   []Repo
+	visited map[string]bool // Symlink targets already descended into
+}
+
+// task is a directory waiting to be visited
+type task struct {
+	dir, rel string
+	depth    int
+}
+
+// push queues a directory to visit
+func (s *scanner) push(t task) {
+	s.queueMu.Lock()
+	s.queue = append(s.queue, t)
+	s.pending++
+	s.queueMu.Unlock()
+	s.cond.Signal()
+}
+
+// work visits queued directories until every directory has been visited.
+// Visiting a directory may queue its subdirectories.
+func (s *scanner) work() {
+	for {
+		s.queueMu.Lock()
+		for len(s.queue) == 0 && s.pending > 0 {
+			s.cond.Wait()
+		}
+		if s.pending == 0 {
+			s.queueMu.Unlock()
+			return
+		}
+		// Last in, first out: the scan goes depth first and the queue stays short
+		t := s.queue[len(s.queue)-1]
+		s.queue = s.queue[:len(s.queue)-1]
+		s.queueMu.Unlock()
+
+		s.visit(t.dir, t.rel, t.depth)
+
+		s.queueMu.Lock()
+		s.pending--
+		done := s.pending == 0
+		s.queueMu.Unlock()
+		if done {
+			s.cond.Broadcast()
+		}
+	}
+}
+
+// visit reads the directory rel and records it if it is a repository, or
+// descends into it otherwise
+func (s *scanner) visit(dir, rel string, depth int) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	for _, e := range entries {
+		if e.Name() == ".git" {
+			s.record(dir, rel)
+			return
+		}
+	}
+	s.descend(dir, rel, depth, entries)
+}
+
+// descend queues the subdirectories of dir that are not skipped
+func (s *scanner) descend(dir, rel string, depth int, entries []os.DirEntry) {
+	if s.opts.MaxDepth > 0 && depth >= s.opts.MaxDepth {
+		return
+	}
+
+	for _, e := range entries {
+		name := e.Name()
+		if name == ".git" {
+			continue
+		}
+		childRel := name
+		if rel != "" {
+			childRel = rel + "/" + name
+		}
+		child := filepath.Join(dir, name)
+
+		if e.Type()&os.ModeSymlink != 0 {
+			if !s.followSymlink(child) {
+				continue
+			}
+		} else if !e.IsDir() {
+			continue
+		}
+
+		if s.opts.Ignored[childRel] || Excluded(s.opts.Exclude, childRel) {
+			continue
+		}
+		if s.opts.Skip != nil && s.opts.Skip(childRel) {
+			continue
+		}
+
+		s.push(task{dir: child, rel: childRel, depth: depth + 1})
+	}
+}
+
+// followSymlink reports whether to descend into the symlink at p: only if
+// symlinks are followed, it points to a directory outside the root (one
+// inside is scanned at its own path) and that wasn't visited before
+func (s *scanner) followSymlink(p string) bool {
+	if !s.opts.FollowSymlinks || s.realRoot == "" {
+		return false
+	}
+	target, err := filepath.EvalSymlinks(p)
+	if err != nil {
+		return false
+	}
+	if info, err := os.Stat(target); err != nil || !info.IsDir() {
+		return false
+	}
+	if target == s.realRoot || strings.HasPrefix(target, s.realRoot+string(filepath.Separator)) {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.visited[target] {
+		return false
+	}
+	s.visited[target] = true
+	return true
+}
+
+// record adds the repository at dir
+func (s *scanner) record(dir, rel string) {
+	repo := Repo{Path: rel, Kind: KindRepo}
+
+	gitPath := filepath.Join(dir, ".git")
+	if info, err := os.Stat(gitPath); err != nil {
+		return
+	} else if !info.IsDir() {
+		repo.GitDir, repo.Kind = readGitFile(gitPath)
+		if repo.GitDir == "" {
+			return // Not a git file
+		}
+	}
+
+	s.mu.Lock()
+	s.repos = append(s.repos, repo)
+	s.mu.Unlock()
+}
+
+// readGitFile returns the git directory a .git file points to ("" if it
+// isn't a git file) and what kind of repository that makes
+func readGitFile(gitPath string) (string, Kind) {
+	data, err := os.ReadFile(gitPath)
+	if err != nil {
+		return "", KindGitFile
+	}
+	line := strings.TrimSpace(strings.SplitN(string(data), "\n", 2)[0])
+	if !strings.HasPrefix(line, "gitdir:") {
+		return "", KindGitFile
+	}
+	gitDir := strings.TrimSpace(strings.TrimPrefix(line, "gitdir:"))
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(gitPath), gitDir)
+	}
+	gitDir = filepath.Clean(gitDir)
+
+	slashed := filepath.ToSlash(gitDir)
+	switch {
+	case strings.Contains(slashed, "/worktrees/"):
+		return gitDir, KindWorktree
+	case strings.Contains(slashed, "/.git/modules/"):
+		return gitDir, KindSubmodule
+	}
+	return gitDir, KindGitFile
+}
+
+// MainRepo returns the repository a worktree's git directory belongs to:
+// the directory containing its .git ("" if gitDir isn't one of a worktree)
+func MainRepo(gitDir string) string {
+	slashed := filepath.ToSlash(gitDir)
+	i := strings.LastIndex(slashed, "/worktrees/")
+	if i < 0 {
+		return ""
+	}
+	common := filepath.FromSlash(slashed[:i])
+	if filepath.Base(common) == ".git" {
+		return filepath.Dir(common)
+	}
+	return common // Bare repository
+}
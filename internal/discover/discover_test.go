@@ -0,0 +1,166 @@
+package discover
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// makeTree creates the directories of dirs below root
+func makeTree(t *testing.T, root string, dirs ...string) {
+	t.Helper()
+	for _, dir := range dirs {
+		if err := os.MkdirAll(filepath.Join(root, filepath.FromSlash(dir)), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func paths(repos []Repo) []string {
+	var result []string
+	for _, repo := range repos {
+		result = append(result, repo.Path)
+	}
+	return result
+}
+
+func TestScan(t *testing.T) {
+	root := t.TempDir()
+	makeTree(t, root,
+		".git",
+		"apps/web/.git",
+		"apps/web/vendor/nested/.git", // Inside a repository: not reported
+		"libs/a/b/c/.git",
+		"node_modules/pkg/.git",
+		"build/out/.git",
+		"docs",
+	)
+
+	t.Run("whole tree", func(t *testing.T) {
+		repos, err := Scan(root, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{"apps/web", "build/out", "libs/a/b/c", "node_modules/pkg"}
+		if !reflect.DeepEqual(paths(repos), want) {
+			t.Errorf("got %v, want %v", paths(repos), want)
+		}
+		if repos[0].Kind != KindRepo {
+			t.Errorf("expected a plain repository, got %v", repos[0].Kind)
+		}
+	})
+
+	t.Run("max depth", func(t *testing.T) {
+		repos, _ := Scan(root, Options{MaxDepth: 2, Jobs: 1})
+		want := []string{"apps/web", "build/out", "node_modules/pkg"}
+		if !reflect.DeepEqual(paths(repos), want) {
+			t.Errorf("got %v, want %v", paths(repos), want)
+		}
+	})
+
+	t.Run("exclude, ignored and skip", func(t *testing.T) {
+		repos, _ := Scan(root, Options{
+			Exclude: []string{"node_modules"},
+			Ignored: map[string]bool{"build": true},
+			Skip:    func(rel string) bool { return rel == "apps/web" },
+		})
+		want := []string{"libs/a/b/c"}
+		if !reflect.DeepEqual(paths(repos), want) {
+			t.Errorf("got %v, want %v", paths(repos), want)
+		}
+	})
+
+	t.Run("missing root", func(t *testing.T) {
+		if _, err := Scan(filepath.Join(root, "missing"), Options{}); err == nil {
+			t.Error("expected error for a missing root")
+		}
+	})
+}
+
+func TestScan_GitFiles(t *testing.T) {
+	root := t.TempDir()
+	makeTree(t, root, "main/.git/worktrees/wt", "main/.git/modules/sub", "wt", "main/sub", "sep", "notgit")
+	os.WriteFile(filepath.Join(root, "wt", ".git"), []byte("gitdir: "+filepath.Join(root, "main", ".git", "worktrees", "wt")+"\n"), 0644)
+	os.WriteFile(filepath.Join(root, "sep", ".git"), []byte("gitdir: /srv/git/sep.git\n"), 0644)
+	os.WriteFile(filepath.Join(root, "notgit", ".git"), []byte("something else\n"), 0644)
+
+	repos, err := Scan(root, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// main/sub is inside the main repository and not reported
+	kinds := map[string]Kind{}
+	for _, repo := range repos {
+		kinds[repo.Path] = repo.Kind
+	}
+	want := map[string]Kind{"main": KindRepo, "wt": KindWorktree, "sep": KindGitFile}
+	if !reflect.DeepEqual(kinds, want) {
+		t.Errorf("got %v, want %v", kinds, want)
+	}
+
+	// A submodule's .git file points into its superproject's .git/modules
+	sub := filepath.Join(root, "super", "sub")
+	makeTree(t, root, "super/sub")
+	os.WriteFile(filepath.Join(sub, ".git"), []byte("gitdir: ../.git/modules/sub\n"), 0644)
+	repos, _ = Scan(filepath.Join(root, "super"), Options{})
+	if len(repos) != 1 || repos[0].Kind != KindSubmodule {
+		t.Errorf("expected a submodule, got %+v", repos)
+	}
+
+	if got := MainRepo(filepath.Join(root, "main", ".git", "worktrees", "wt")); got != filepath.Join(root, "main") {
+		t.Errorf("MainRepo = %q, want %q", got, filepath.Join(root, "main"))
+	}
+	if got := MainRepo("/srv/git/sep.git"); got != "" {
+		t.Errorf("MainRepo of a non-worktree = %q, want empty", got)
+	}
+}
+
+func TestScan_Symlinks(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	makeTree(t, root, "real/repo/.git")
+	makeTree(t, outside, "ext/.git")
+	if err := os.Symlink(filepath.Join(root, "real"), filepath.Join(root, "inner")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	os.Symlink(outside, filepath.Join(root, "outer"))
+	os.Symlink(root, filepath.Join(outside, "loop")) // Cycle back into the root
+
+	repos, _ := Scan(root, Options{})
+	if want := []string{"real/repo"}; !reflect.DeepEqual(paths(repos), want) {
+		t.Errorf("without following symlinks: got %v, want %v", paths(repos), want)
+	}
+
+	repos, _ = Scan(root, Options{FollowSymlinks: true})
+	if want := []string{"outer/ext", "real/repo"}; !reflect.DeepEqual(paths(repos), want) {
+		t.Errorf("following symlinks: got %v, want %v", paths(repos), want)
+	}
+}
+
+func TestExcluded(t *testing.T) {
+	tests := []struct {
+		pattern string
+		rel     string
+		want    bool
+	}{
+		{"node_modules", "node_modules", true},
+		{"node_modules", "web/node_modules", true},
+		{"node_modules/", "web/node_modules", true},
+		{"node_*", "web/node_modules", true},
+		{"vendor", "vendors", false},
+		{"web/dist", "web/dist", true},
+		{"web/dist", "apps/web/dist", false},
+		{"/web", "web", true},
+		{"/web", "apps/web", false},
+		{"**/dist", "apps/web/dist", true},
+		{"**/web/dist", "apps/web/dist", true},
+		{"**/web/dist", "apps/web/dist2", false},
+		{"", "web", false},
+	}
+	for _, tt := range tests {
+		if got := Excluded([]string{tt.pattern}, tt.rel); got != tt.want {
+			t.Errorf("Excluded(%q, %q) = %v, want %v", tt.pattern, tt.rel, got, tt.want)
+		}
+	}
+}
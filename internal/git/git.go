@@ -3,29 +3,120 @@ package git
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 )
 
+// CloneOptions limits what a clone downloads and checks out
+type CloneOptions struct {
+	Branch    string
+	Depth     int      // Shallow clone with this many commits, 0 for full history
+	Filter    string   // Partial clone filter, e.g. "blob:none"
+	Sparse    []string // Directories to check out (sparse checkout, cone mode)
+	Fallbacks []string // URLs tried in order if cloning from the repository fails
+}
+
+// args returns the git clone arguments for the options
+func (o CloneOptions) args() []string {
+	var args []string
+	if o.Branch != "" {
+		args = append(args, "-b", o.Branch)
+	}
+	if o.Depth > 0 {
+		args = append(args, "--depth", fmt.Sprint(o.Depth))
+	}
+	if o.Filter != "" {
+		args = append(args, "--filter="+o.Filter)
+	}
+	return args
+}
+
 // Clone clones a repository to the specified path
 func Clone(repo, path, branch string) error {
-	args := []string{"clone"}
-	if branch != "" {
-		args = append(args, "-b", branch)
+	return cloneTo(repo, path, CloneOptions{Branch: branch}, os.Stdout, os.Stderr)
+}
+
+// CloneWithOutput clones a repository, writing git's output to out
+// Used by parallel workers so that output can be buffered per workspace
+func CloneWithOutput(repo, path, branch string, out io.Writer) error {
+	return cloneTo(repo, path, CloneOptions{Branch: branch}, out, out)
+}
+
+// CloneWithOptions clones a repository as a shallow, partial and/or sparse
+// clone, writing git's output to out
+func CloneWithOptions(repo, path string, opts CloneOptions, out io.Writer) error {
+	return cloneTo(repo, path, opts, out, out)
+}
+
+func cloneTo(repo, path string, opts CloneOptions, stdout, stderr io.Writer) error {
+	args := append([]string{"clone"}, opts.args()...)
+	if len(opts.Sparse) > 0 {
+		// Check out top-level files only until the directories are set
+		args = append(args, "--sparse")
 	}
-	args = append(args, repo, path)
 
-	cmd := exec.Command("git", args...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	if err := cloneFrom(repo, path, args, opts, stdout, stderr, stderr); err != nil {
+		return err
+	}
+
+	if len(opts.Sparse) > 0 {
+		return SetSparseCheckout(path, opts.Sparse)
+	}
+	return nil
+}
+
+// cloneFrom runs git with the clone arguments args for repo, then for each
+// fallback URL in turn until one succeeds, writing git's output to stdout and
+// stderr and notes about fallbacks to log. After cloning from a fallback,
+// origin is set back to repo so that fetch and push use the repository itself.
+func cloneFrom(repo, dst string, args []string, opts CloneOptions, stdout, stderr, log io.Writer) error {
+	urls := append([]string{repo}, opts.Fallbacks...)
+
+	var err error
+	for i, url := range urls {
+		if i > 0 && log != nil {
+			fmt.Fprintf(log, "Cloning from %s failed, trying %s\n", urls[i-1], url)
+		}
+
+		cloneArgs := append(append([]string(nil), args...), referenceArgs(url, opts)...)
+		cmd := exec.Command("git", append(cloneArgs, url, dst)...)
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+		if err = cmd.Run(); err != nil {
+			continue
+		}
+
+		if i > 0 {
+			if err := exec.Command("git", "-C", dst, "remote", "set-url", "origin", repo).Run(); err != nil {
+				return fmt.Errorf("failed to set origin: %w", err)
+			}
+		}
+		return nil
+	}
+	return err
 }
 
 // InitRepo initializes a git repository in an existing directory with source files
 // This is used when source files are already tracked by parent but .git is missing
 func InitRepo(path, repo, branch string) error {
+	return initRepoTo(path, repo, CloneOptions{Branch: branch}, os.Stdout, os.Stderr)
+}
+
+// InitRepoWithOutput is like InitRepo but writes git's output to out
+func InitRepoWithOutput(path, repo, branch string, out io.Writer) error {
+	return initRepoTo(path, repo, CloneOptions{Branch: branch}, out, out)
+}
+
+// InitRepoWithOptions is like InitRepoWithOutput for a shallow, partial and/or
+// sparse clone. With sparse directories, clean files outside them are removed.
+func InitRepoWithOptions(path, repo string, opts CloneOptions, out io.Writer) error {
+	return initRepoTo(path, repo, opts, out, out)
+}
+
+func initRepoTo(path, repo string, opts CloneOptions, stdout, stderr io.Writer) error {
 	// Create a temporary directory for bare clone
 	tempDir, err := os.MkdirTemp("", "git-multirepo-*")
 	if err != nil {
@@ -35,14 +126,8 @@ func InitRepo(path, repo, branch string) error {
 
 	// Clone as bare to temp location (only .git contents)
 	tempGit := filepath.Join(tempDir, "temp.git")
-	args := []string{"clone", "--bare"}
-	if branch != "" {
-		args = append(args, "-b", branch)
-	}
-	args = append(args, repo, tempGit)
-
-	cmd := exec.Command("git", args...)
-	if err := cmd.Run(); err != nil {
+	args := append([]string{"clone", "--bare"}, opts.args()...)
+	if err := cloneFrom(repo, tempGit, args, opts, nil, nil, stderr); err != nil {
 		return fmt.Errorf("failed to clone: %w", err)
 	}
 
@@ -53,23 +138,31 @@ func InitRepo(path, repo, branch string) error {
 	}
 
 	// Convert from bare to normal repository
-	cmd = exec.Command("git", "-C", path, "config", "--bool", "core.bare", "false")
+	cmd := exec.Command("git", "-C", path, "config", "--bool", "core.bare", "false")
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to configure: %w", err)
 	}
 
 	// Reset index to match HEAD (don't touch working tree files)
 	cmd = exec.Command("git", "-C", path, "reset", "--mixed", "HEAD")
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to reset: %w", err)
 	}
 
+	if len(opts.Sparse) > 0 {
+		if err := SetSparseCheckout(path, opts.Sparse); err != nil {
+			return fmt.Errorf("failed to set sparse checkout: %w", err)
+		}
+	}
+
 	return nil
 }
 
-// Pull pulls the latest changes in the specified directory
+// Pull pulls the latest changes in the specified directory.
+// Shallow and partial clones stay shallow and partial: only new commits
+// (and for partial clones, only the objects they need) are downloaded.
 func Pull(path string) error {
 	cmd := exec.Command("git", "-C", path, "pull")
 	cmd.Stdout = os.Stdout
@@ -85,6 +178,39 @@ func Push(path string) error {
 	return cmd.Run()
 }
 
+// PushBranch pushes branch to the remote branch it tracks, writing git's
+// output to out. With setUpstream, the branch is pushed to origin under its own
+// name with -u to set its upstream.
+func PushBranch(path, branch string, setUpstream bool, out io.Writer) error {
+	args := []string{"-C", path, "push"}
+	if setUpstream {
+		args = append(args, "-u", "origin", branch)
+	} else {
+		remote, merge, err := GetUpstream(path, branch)
+		if err != nil {
+			return err
+		}
+		args = append(args, remote, "refs/heads/"+branch+":"+merge)
+	}
+
+	cmd := exec.Command("git", args...)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	return cmd.Run()
+}
+
+// CountAhead returns the number of commits in HEAD that are not in ref
+func CountAhead(path, ref string) (int, error) {
+	cmd := exec.Command("git", "-C", path, "rev-list", "--count", ref+"..HEAD")
+	out, err := cmd.Output()
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	_, err = fmt.Sscanf(strings.TrimSpace(string(out)), "%d", &count)
+	return count, err
+}
+
 // IsRepo checks if the given path is a git repository
 func IsRepo(path string) bool {
 	gitDir := filepath.Join(path, ".git")
@@ -300,6 +426,108 @@ func GetCurrentBranch(path string) (string, error) {
 	return strings.TrimSpace(string(out)), nil
 }
 
+// GetRemoteDefaultBranch returns the branch origin/HEAD points to (e.g. "main")
+func GetRemoteDefaultBranch(path string) (string, error) {
+	cmd := exec.Command("git", "-C", path, "symbolic-ref", "--short", "refs/remotes/origin/HEAD")
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(strings.TrimSpace(string(out)), "origin/"), nil
+}
+
+// ListIgnoredDirs returns the directories of the repository at path that match
+// a .gitignore pattern themselves, relative with forward slashes. Directories
+// that are only ignored because of their contents are not included.
+func ListIgnoredDirs(path string) ([]string, error) {
+	cmd := exec.Command("git", "-C", path, "status", "--porcelain", "-z", "--ignored=matching", "--untracked-files=normal")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var dirs []string
+	for _, entry := range strings.Split(string(out), "\x00") {
+		if strings.HasPrefix(entry, "!! ") && strings.HasSuffix(entry, "/") {
+			dirs = append(dirs, strings.TrimSuffix(strings.TrimPrefix(entry, "!! "), "/"))
+		}
+	}
+	return dirs, nil
+}
+
+// BranchExists checks if a local branch exists
+func BranchExists(path, branch string) bool {
+	return exec.Command("git", "-C", path, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch).Run() == nil
+}
+
+// CreateBranch creates a branch at startPoint (HEAD if empty) and checks it out
+func CreateBranch(path, branch, startPoint string) error {
+	args := []string{"-C", path, "checkout", "-b", branch}
+	if startPoint != "" {
+		args = append(args, startPoint)
+	}
+	if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// CheckoutBranch checks out an existing branch.
+// A branch that only exists on origin is created to track it.
+func CheckoutBranch(path, branch string) error {
+	if out, err := exec.Command("git", "-C", path, "checkout", branch).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// DeleteBranch deletes a local branch; force deletes it even if unmerged
+func DeleteBranch(path, branch string, force bool) error {
+	flag := "-d"
+	if force {
+		flag = "-D"
+	}
+	if out, err := exec.Command("git", "-C", path, "branch", flag, branch).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// DiscardFileChanges restores a tracked file to its HEAD version
+func DiscardFileChanges(path, file string) error {
+	if out, err := exec.Command("git", "-C", path, "checkout", "HEAD", "--", file).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// GetTrackingBranch returns the upstream branch of HEAD (e.g. "origin/main")
+// Returns an error if no upstream is configured
+func GetTrackingBranch(path string) (string, error) {
+	cmd := exec.Command("git", "-C", path, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{u}")
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GetUpstream returns the remote and the ref on it (e.g. "refs/heads/main")
+// that branch tracks. Returns an error if no upstream is configured.
+func GetUpstream(path, branch string) (remote, merge string, err error) {
+	out, err := exec.Command("git", "-C", path, "config", "--get", "branch."+branch+".remote").Output()
+	if err != nil {
+		return "", "", fmt.Errorf("no upstream configured for %s", branch)
+	}
+	remote = strings.TrimSpace(string(out))
+
+	out, err = exec.Command("git", "-C", path, "config", "--get", "branch."+branch+".merge").Output()
+	if err != nil {
+		return "", "", fmt.Errorf("no upstream configured for %s", branch)
+	}
+	return remote, strings.TrimSpace(string(out)), nil
+}
+
 // GetCurrentCommit returns the current HEAD commit hash
 func GetCurrentCommit(path string) (string, error) {
 	cmd := exec.Command("git", "-C", path, "rev-parse", "HEAD")
@@ -310,6 +538,47 @@ func GetCurrentCommit(path string) (string, error) {
 	return strings.TrimSpace(string(out)), nil
 }
 
+// GetExactTag returns the tag pointing exactly at HEAD
+// Returns an error if HEAD is not tagged
+func GetExactTag(path string) (string, error) {
+	cmd := exec.Command("git", "-C", path, "describe", "--tags", "--exact-match", "HEAD")
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// HasCommit checks if the commit object exists in the repository
+func HasCommit(path, commit string) bool {
+	cmd := exec.Command("git", "-C", path, "cat-file", "-e", commit+"^{commit}")
+	return cmd.Run() == nil
+}
+
+// CheckoutCommit moves the current branch to commit, fetching it from origin if it
+// is not available locally. With keepWorkTree, only the index is reset so files
+// already present in the working tree are left untouched.
+func CheckoutCommit(path, commit string, keepWorkTree bool, out io.Writer) error {
+	if !HasCommit(path, commit) {
+		cmd := exec.Command("git", "-C", path, "fetch", "origin", commit)
+		cmd.Stdout = out
+		cmd.Stderr = out
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("commit %s not found in origin: %w", commit, err)
+		}
+	}
+
+	mode := "--hard"
+	if keepWorkTree {
+		mode = "--mixed"
+	}
+
+	cmd := exec.Command("git", "-C", path, "reset", "-q", mode, commit)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	return cmd.Run()
+}
+
 // HasUnpushedCommits checks if there are commits not pushed to remote
 func HasUnpushedCommits(path string) (bool, error) {
 	// Get current branch
@@ -348,6 +617,25 @@ func GetRemoteURL(path string) (string, error) {
 	return strings.TrimSpace(string(out)), nil
 }
 
+// SetRemoteURL points the origin remote at url, adding origin if it is missing
+func SetRemoteURL(path, url string) error {
+	if _, err := GetRemoteURL(path); err != nil {
+		if out, err := exec.Command("git", "-C", path, "remote", "add", "origin", url).CombinedOutput(); err != nil {
+			return fmt.Errorf("git remote add failed: %s", strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+	if out, err := exec.Command("git", "-C", path, "remote", "set-url", "origin", url).CombinedOutput(); err != nil {
+		return fmt.Errorf("git remote set-url failed: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// IsTracked checks if a file is in the index
+func IsTracked(repoPath, file string) bool {
+	return exec.Command("git", "-C", repoPath, "ls-files", "--error-unmatch", "--", file).Run() == nil
+}
+
 // ApplySkipWorktree applies skip-worktree to files
 func ApplySkipWorktree(repoPath string, files []string) error {
 	if len(files) == 0 {
@@ -401,7 +689,9 @@ func UnapplySkipWorktree(repoPath string, files []string) error {
 	return nil
 }
 
-// ListSkipWorktree lists all files with skip-worktree set
+// ListSkipWorktree lists all files with skip-worktree set.
+// In a sparse checkout, files left out by it (absent from the working tree)
+// also have skip-worktree set; they are not listed.
 func ListSkipWorktree(repoPath string) ([]string, error) {
 	cmd := exec.Command("git", "-C", repoPath, "ls-files", "-v")
 	out, err := cmd.Output()
@@ -409,12 +699,19 @@ func ListSkipWorktree(repoPath string) ([]string, error) {
 		return nil, err
 	}
 
+	sparse := IsSparse(repoPath)
 	var files []string
 	lines := strings.Split(string(out), "\n")
 	for _, line := range lines {
 		if len(line) > 2 && line[0] == 'S' {
 			// Format: "S filename"
-			files = append(files, strings.TrimSpace(line[2:]))
+			file := strings.TrimSpace(line[2:])
+			if sparse {
+				if _, err := os.Lstat(filepath.Join(repoPath, file)); err != nil {
+					continue
+				}
+			}
+			files = append(files, file)
 		}
 	}
 
@@ -445,6 +742,35 @@ func CountChangedFiles(path string) (int, error) {
 	return len(lines), nil
 }
 
+// CommitFiles stages the given files (including deletions and new files) and
+// commits only those files, leaving other staged changes untouched
+func CommitFiles(path, message string, files []string) error {
+	if len(files) == 0 {
+		return fmt.Errorf("no files to commit")
+	}
+
+	args := append([]string{"-C", path, "add", "-A", "--"}, files...)
+	if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("git add failed: %s", strings.TrimSpace(string(out)))
+	}
+
+	args = append([]string{"-C", path, "commit", "-m", message, "--"}, files...)
+	if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("git commit failed: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// HasChangesIn checks if any of the given paths differ from HEAD
+func HasChangesIn(path string, files []string) (bool, error) {
+	args := append([]string{"-C", path, "status", "--porcelain", "--"}, files...)
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return false, err
+	}
+	return len(strings.TrimSpace(string(out))) > 0, nil
+}
+
 // Stash stashes all local changes
 func Stash(path string) error {
 	cmd := exec.Command("git", "-C", path, "stash", "push", "-m", "git-multirepo auto-stash")
@@ -587,6 +913,24 @@ func GetFileDiff(path, file, branch string) (string, error) {
 	return string(out), nil
 }
 
+// ShowFile returns the content of a file at a revision.
+// Returns an empty content if the file does not exist at that revision, and
+// an error if the revision itself does not exist (e.g. a branch not fetched).
+func ShowFile(path, rev, file string) ([]byte, error) {
+	cmd := exec.Command("git", "-C", path, "rev-parse", "--verify", "-q", rev+"^{commit}")
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("revision %s not found", rev)
+	}
+
+	cmd = exec.Command("git", "-C", path, "cat-file", "-e", rev+":"+file)
+	if err := cmd.Run(); err != nil {
+		return []byte{}, nil
+	}
+
+	cmd = exec.Command("git", "-C", path, "show", rev+":"+file)
+	return cmd.Output()
+}
+
 // ResetFile resets a file to match remote version
 func ResetFile(path, file, branch string) error {
 	cmd := exec.Command("git", "-C", path, "checkout", "origin/"+branch, "--", file)
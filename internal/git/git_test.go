@@ -4,6 +4,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
 )
@@ -290,6 +292,69 @@ func TestGetRemoteURL(t *testing.T) {
 	})
 }
 
+func TestSetRemoteURL(t *testing.T) {
+	dir := setupTestRepo(t)
+
+	// Adds origin when missing
+	if err := SetRemoteURL(dir, "https://github.com/test/first.git"); err != nil {
+		t.Fatalf("SetRemoteURL failed: %v", err)
+	}
+	if url, _ := GetRemoteURL(dir); url != "https://github.com/test/first.git" {
+		t.Errorf("GetRemoteURL() = %q after add", url)
+	}
+
+	// Replaces an existing origin
+	if err := SetRemoteURL(dir, "https://github.com/test/second.git"); err != nil {
+		t.Fatalf("SetRemoteURL failed: %v", err)
+	}
+	if url, _ := GetRemoteURL(dir); url != "https://github.com/test/second.git" {
+		t.Errorf("GetRemoteURL() = %q after set-url", url)
+	}
+}
+
+func TestIsTracked(t *testing.T) {
+	dir := setupTestRepoWithCommit(t)
+	os.WriteFile(filepath.Join(dir, "untracked.txt"), []byte("x"), 0644)
+
+	files, _ := exec.Command("git", "-C", dir, "ls-files").Output()
+	tracked := strings.Fields(string(files))[0]
+
+	if !IsTracked(dir, tracked) {
+		t.Errorf("%s should be tracked", tracked)
+	}
+	if IsTracked(dir, "untracked.txt") {
+		t.Error("untracked.txt should not be tracked")
+	}
+}
+
+func TestCommitFiles(t *testing.T) {
+	dir := setupTestRepoWithCommit(t)
+	os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Changed"), 0644)
+	os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new"), 0644)
+	os.WriteFile(filepath.Join(dir, "other.txt"), []byte("other"), 0644)
+
+	if err := CommitFiles(dir, "Add new file", []string{"new.txt"}); err != nil {
+		t.Fatalf("CommitFiles failed: %v", err)
+	}
+
+	out, _ := exec.Command("git", "-C", dir, "show", "--name-only", "--format=%s", "HEAD").Output()
+	if got := strings.TrimSpace(string(out)); got != "Add new file\n\nnew.txt" {
+		t.Errorf("unexpected commit: %q", got)
+	}
+
+	changed, err := HasChangesIn(dir, []string{"README.md"})
+	if err != nil || !changed {
+		t.Errorf("README.md should still be modified (changed=%v, err=%v)", changed, err)
+	}
+	if changed, _ := HasChangesIn(dir, []string{"new.txt"}); changed {
+		t.Error("new.txt should be committed")
+	}
+
+	if err := CommitFiles(dir, "empty", nil); err == nil {
+		t.Error("CommitFiles should fail without files")
+	}
+}
+
 func TestClone(t *testing.T) {
 	t.Run("clone local repo", func(t *testing.T) {
 		// Create source repo
@@ -581,6 +646,47 @@ func TestHasChanges_ErrorCases(t *testing.T) {
 	})
 }
 
+func TestGetRemoteDefaultBranch(t *testing.T) {
+	remote := setupTestRepoWithCommit(t)
+	want, _ := GetCurrentBranch(remote)
+
+	dst := filepath.Join(t.TempDir(), "clone")
+	if err := exec.Command("git", "clone", "-q", remote, dst).Run(); err != nil {
+		t.Fatalf("git clone failed: %v", err)
+	}
+	if got, err := GetRemoteDefaultBranch(dst); err != nil || got != want {
+		t.Errorf("GetRemoteDefaultBranch() = %q, %v, want %q", got, err, want)
+	}
+
+	if _, err := GetRemoteDefaultBranch(remote); err == nil {
+		t.Error("should error without origin/HEAD")
+	}
+}
+
+func TestListIgnoredDirs(t *testing.T) {
+	dir := setupTestRepo(t)
+	os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("node_modules/\nbuild\n*.log\n"), 0644)
+	for _, sub := range []string{"node_modules/pkg", "web/build", "logs"} {
+		os.MkdirAll(filepath.Join(dir, sub), 0755)
+	}
+	os.WriteFile(filepath.Join(dir, "node_modules", "pkg", "index.js"), nil, 0644)
+	os.WriteFile(filepath.Join(dir, "web", "build", "out.js"), nil, 0644)
+	os.WriteFile(filepath.Join(dir, "logs", "a.log"), nil, 0644) // Ignored only for its contents
+
+	dirs, err := ListIgnoredDirs(dir)
+	if err != nil {
+		t.Fatalf("ListIgnoredDirs() error = %v", err)
+	}
+	sort.Strings(dirs)
+	if want := []string{"node_modules", "web/build"}; !reflect.DeepEqual(dirs, want) {
+		t.Errorf("ListIgnoredDirs() = %v, want %v", dirs, want)
+	}
+
+	if _, err := ListIgnoredDirs(t.TempDir()); err == nil {
+		t.Error("should error outside a repository")
+	}
+}
+
 func TestGetCurrentBranch_ErrorCases(t *testing.T) {
 	t.Run("error on non-existent path", func(t *testing.T) {
 		_, err := GetCurrentBranch("/non/existent/path")
@@ -606,3 +712,35 @@ func TestGetCurrentBranch_ErrorCases(t *testing.T) {
 		}
 	})
 }
+
+func TestInitRepoWithOptions_Fallback(t *testing.T) {
+	remote := setupTestRepoWithCommit(t)
+	missing := filepath.Join(t.TempDir(), "missing.git")
+	dst := t.TempDir()
+	os.WriteFile(filepath.Join(dst, "README.md"), []byte("# Test"), 0644)
+
+	var out strings.Builder
+	if err := InitRepoWithOptions(dst, missing, CloneOptions{Fallbacks: []string{remote}}, &out); err != nil {
+		t.Fatalf("InitRepoWithOptions failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "trying "+remote) {
+		t.Errorf("fallback should be reported, got: %s", out.String())
+	}
+	if origin, _ := GetRemoteURL(dst); origin != missing {
+		t.Errorf("origin = %s, want %s", origin, missing)
+	}
+}
+
+func TestShowFile(t *testing.T) {
+	dir := setupTestRepoWithCommit(t)
+
+	if content, err := ShowFile(dir, "HEAD", "README.md"); err != nil || string(content) != "# Test" {
+		t.Errorf("ShowFile = %q, %v; want the committed content", content, err)
+	}
+	if content, err := ShowFile(dir, "HEAD", "missing.txt"); err != nil || len(content) != 0 {
+		t.Errorf("a file missing at an existing revision should be empty, got %q, %v", content, err)
+	}
+	if _, err := ShowFile(dir, "origin/nope", "README.md"); err == nil {
+		t.Error("a revision that does not exist should be an error")
+	}
+}
@@ -0,0 +1,252 @@
+package git
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MirrorRoot is the directory of the shared object cache. Each remote has a
+// bare mirror in <MirrorRoot>/<host>/<path>.git, which clones borrow objects
+// from. Empty disables the cache.
+var MirrorRoot = defaultMirrorRoot()
+
+// mirrorLocks serializes updates of the same mirror by parallel workers
+var mirrorLocks sync.Map
+
+// Mirror is a repository in the mirror cache
+type Mirror struct {
+	Name    string    // <host>/<path>.git, relative to MirrorRoot
+	Path    string    // Absolute path of the bare mirror
+	Size    int64     // Disk usage in bytes
+	Updated time.Time // Last fetch
+}
+
+func defaultMirrorRoot() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "git-multirepo", "mirrors")
+}
+
+// RemoteKey identifies the repository a remote URL points to, independent of
+// the protocol, or returns "" for local paths
+// https://github.com/user/repo.git -> github.com/user/repo
+// git@github.com:user/repo.git     -> github.com/user/repo
+// ssh://git@github.com/user/repo   -> github.com/user/repo
+func RemoteKey(repo string) string {
+	var host, path string
+	if strings.Contains(repo, "://") {
+		u, err := url.Parse(repo)
+		if err != nil {
+			return ""
+		}
+		host, path = u.Hostname(), u.Path
+		if host == "" {
+			host = u.Scheme // file:///srv/repo.git
+		}
+	} else if i := strings.Index(repo, ":"); i > 1 && !strings.Contains(repo[:i], "/") {
+		// scp-like syntax: [user@]host:path
+		host, path = repo[:i], repo[i+1:]
+		if at := strings.LastIndex(host, "@"); at >= 0 {
+			host = host[at+1:]
+		}
+	} else {
+		return ""
+	}
+
+	var parts []string
+	for _, part := range strings.Split(strings.TrimSuffix(strings.Trim(path, "/"), ".git"), "/") {
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			return ""
+		}
+		parts = append(parts, strings.ReplaceAll(part, ":", "_"))
+	}
+	if host == "" || len(parts) == 0 {
+		return ""
+	}
+
+	return strings.ToLower(host) + "/" + strings.Join(parts, "/")
+}
+
+// MirrorPath returns the mirror location for a remote URL, or "" if the cache
+// is disabled or the remote is a local path
+// https://github.com/user/repo.git -> <MirrorRoot>/github.com/user/repo.git
+func MirrorPath(repo string) string {
+	key := RemoteKey(repo)
+	if MirrorRoot == "" || key == "" {
+		return ""
+	}
+	return filepath.Join(MirrorRoot, filepath.FromSlash(key)+".git")
+}
+
+// UpdateMirror creates the mirror of repo or fetches into it, writing git's
+// output to out
+func UpdateMirror(repo string, out io.Writer) error {
+	dir := MirrorPath(repo)
+	if dir == "" {
+		return fmt.Errorf("%s cannot be cached", repo)
+	}
+
+	lock, _ := mirrorLocks.LoadOrStore(dir, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	if isMirror(dir) {
+		cmd := exec.Command("git", "-C", dir, "fetch", "--prune", "origin")
+		cmd.Stdout = out
+		cmd.Stderr = out
+		return cmd.Run()
+	}
+
+	// Clone next to the final location and rename, so that clones never
+	// reference a half-written mirror
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return err
+	}
+	tempDir, err := os.MkdirTemp(filepath.Dir(dir), ".tmp-")
+	if err != nil {
+		return err
+	}
+
+	tempGit := filepath.Join(tempDir, "mirror.git")
+	cmd := exec.Command("git", "clone", "--mirror", repo, tempGit)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	if err = cmd.Run(); err == nil {
+		err = os.Rename(tempGit, dir)
+	}
+	RemoveMirror(tempDir)
+	return err
+}
+
+// RemoveMirror removes a mirror and the directories above it that become empty
+func RemoveMirror(path string) error {
+	if err := os.RemoveAll(path); err != nil {
+		return err
+	}
+	for dir := filepath.Dir(path); dir != MirrorRoot && strings.HasPrefix(dir, MirrorRoot); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// referenceArgs returns the git clone arguments borrowing objects from the
+// mirror of repo, which is updated first. Shallow and partial clones don't
+// use the cache: the mirror always holds the full history.
+func referenceArgs(repo string, opts CloneOptions) []string {
+	if opts.Depth > 0 || opts.Filter != "" {
+		return nil
+	}
+	mirror := MirrorPath(repo)
+	if mirror == "" {
+		return nil
+	}
+
+	// Without a usable mirror the clone downloads everything itself
+	if err := UpdateMirror(repo, io.Discard); err != nil {
+		return nil
+	}
+
+	// --dissociate copies the borrowed objects, so the clone keeps working
+	// when the cache is cleared
+	return []string{"--reference-if-able", mirror, "--dissociate"}
+}
+
+// ListMirrors returns the mirrors in the cache, sorted by name
+func ListMirrors() ([]Mirror, error) {
+	var mirrors []Mirror
+	if MirrorRoot == "" {
+		return mirrors, nil
+	}
+
+	err := filepath.WalkDir(MirrorRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == MirrorRoot {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !d.IsDir() || path == MirrorRoot {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".tmp-") {
+			return filepath.SkipDir
+		}
+		if !strings.HasSuffix(d.Name(), ".git") || !isMirror(path) {
+			return nil
+		}
+
+		name, _ := filepath.Rel(MirrorRoot, path)
+		mirrors = append(mirrors, Mirror{
+			Name:    filepath.ToSlash(name),
+			Path:    path,
+			Size:    dirSize(path),
+			Updated: mirrorUpdated(path),
+		})
+		return filepath.SkipDir
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(mirrors, func(i, j int) bool { return mirrors[i].Name < mirrors[j].Name })
+	return mirrors, nil
+}
+
+// GCMirror packs the objects of a mirror and drops unreachable ones
+func GCMirror(path string, out io.Writer) error {
+	cmd := exec.Command("git", "-C", path, "gc", "--prune=now", "--quiet")
+	cmd.Stdout = out
+	cmd.Stderr = out
+	return cmd.Run()
+}
+
+// isMirror checks if path is a bare repository
+func isMirror(path string) bool {
+	for _, name := range []string{"HEAD", "objects"} {
+		if _, err := os.Stat(filepath.Join(path, name)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// mirrorUpdated returns when the mirror was last fetched: FETCH_HEAD is
+// rewritten by every fetch, HEAD is written when the mirror is created
+func mirrorUpdated(path string) time.Time {
+	for _, name := range []string{"FETCH_HEAD", "HEAD"} {
+		if info, err := os.Stat(filepath.Join(path, name)); err == nil {
+			return info.ModTime()
+		}
+	}
+	return time.Time{}
+}
+
+// dirSize returns the total size of the files under path
+func dirSize(path string) int64 {
+	var size int64
+	filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
@@ -0,0 +1,125 @@
+package git
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMain keeps the mirrors of clones made by tests out of the user's cache
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "git-multirepo-mirrors-")
+	if err == nil {
+		MirrorRoot = dir
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestMirrorPath(t *testing.T) {
+	tests := []struct {
+		repo string
+		want string
+	}{
+		{"https://github.com/user/repo.git", "github.com/user/repo.git"},
+		{"https://GitHub.com/user/repo", "github.com/user/repo.git"},
+		{"ssh://git@example.com:2222/org/team/repo.git", "example.com/org/team/repo.git"},
+		{"git@github.com:user/repo.git", "github.com/user/repo.git"},
+		{"file:///srv/git/repo.git", "file/srv/git/repo.git"},
+		{"/srv/git/repo.git", ""},
+		{"../repo", ""},
+		{`C:\repos\repo`, ""},
+		{"https://example.com/../repo.git", ""},
+		{"https://example.com/", ""},
+	}
+
+	for _, tt := range tests {
+		want := tt.want
+		if want != "" {
+			want = filepath.Join(MirrorRoot, filepath.FromSlash(want))
+		}
+		if got := MirrorPath(tt.repo); got != want {
+			t.Errorf("MirrorPath(%q) = %q, want %q", tt.repo, got, want)
+		}
+	}
+}
+
+func TestCloneUsesMirror(t *testing.T) {
+	remoteDir := setupTestRepoWithCommit(t)
+	remote := "file://" + filepath.ToSlash(remoteDir)
+
+	dst := filepath.Join(t.TempDir(), "repo")
+	if err := CloneWithOutput(remote, dst, "", io.Discard); err != nil {
+		t.Fatalf("Clone failed: %v", err)
+	}
+
+	mirror := MirrorPath(remote)
+	if !isMirror(mirror) {
+		t.Fatal("clone should create the mirror")
+	}
+	// --dissociate: the clone must not depend on the mirror
+	if _, err := os.Stat(filepath.Join(dst, ".git", "objects", "info", "alternates")); !os.IsNotExist(err) {
+		t.Error("clone should not keep a reference to the mirror")
+	}
+
+	// A new commit reaches the mirror with the next clone
+	os.WriteFile(filepath.Join(remoteDir, "new.txt"), []byte("new"), 0644)
+	exec.Command("git", "-C", remoteDir, "add", ".").Run()
+	exec.Command("git", "-C", remoteDir, "commit", "-m", "New commit").Run()
+	head, _ := exec.Command("git", "-C", remoteDir, "rev-parse", "HEAD").Output()
+
+	dst2 := t.TempDir()
+	if err := InitRepoWithOutput(dst2, remote, "", io.Discard); err != nil {
+		t.Fatalf("InitRepo failed: %v", err)
+	}
+	if !HasCommit(mirror, strings.TrimSpace(string(head))) {
+		t.Error("mirror should be fetched before cloning")
+	}
+
+	mirrors, err := ListMirrors()
+	if err != nil || len(mirrors) != 1 || mirrors[0].Path != mirror || mirrors[0].Size == 0 {
+		t.Fatalf("ListMirrors() = %+v, %v", mirrors, err)
+	}
+	if time.Since(mirrors[0].Updated) > time.Hour {
+		t.Errorf("mirror update time = %v", mirrors[0].Updated)
+	}
+
+	if err := GCMirror(mirror, io.Discard); err != nil {
+		t.Errorf("GCMirror failed: %v", err)
+	}
+	if err := RemoveMirror(mirror); err != nil {
+		t.Fatalf("RemoveMirror failed: %v", err)
+	}
+	if entries, _ := os.ReadDir(MirrorRoot); len(entries) != 0 {
+		t.Errorf("empty directories should be removed, got %v", entries)
+	}
+}
+
+func TestCloneWithoutMirror(t *testing.T) {
+	remote := "file://" + filepath.ToSlash(setupTestRepoWithCommit(t))
+
+	// Shallow clones don't populate the cache
+	dst := filepath.Join(t.TempDir(), "repo")
+	if err := CloneWithOptions(remote, dst, CloneOptions{Depth: 1}, io.Discard); err != nil {
+		t.Fatalf("Clone failed: %v", err)
+	}
+	if _, err := os.Stat(MirrorPath(remote)); !os.IsNotExist(err) {
+		t.Error("shallow clone should not create a mirror")
+	}
+
+	// An unusable cache directory doesn't prevent the clone
+	saved := MirrorRoot
+	MirrorRoot = filepath.Join(t.TempDir(), "file-in-the-way")
+	os.WriteFile(MirrorRoot, nil, 0644)
+	defer func() { MirrorRoot = saved }()
+
+	dst = filepath.Join(t.TempDir(), "repo")
+	if err := CloneWithOutput(remote, dst, "", io.Discard); err != nil {
+		t.Fatalf("clone should succeed without a usable cache: %v", err)
+	}
+}
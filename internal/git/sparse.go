@@ -0,0 +1,99 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SetSparseCheckout limits the working tree of the repository at path to the
+// given directories (cone mode). Top-level files are always checked out.
+func SetSparseCheckout(path string, dirs []string) error {
+	args := append([]string{"-C", path, "sparse-checkout", "set", "--cone", "--"}, dirs...)
+	if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// DisableSparseCheckout restores the full working tree
+func DisableSparseCheckout(path string) error {
+	if out, err := exec.Command("git", "-C", path, "sparse-checkout", "disable").CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// IsSparse checks if the repository uses a sparse checkout
+func IsSparse(path string) bool {
+	out, err := exec.Command("git", "-C", path, "config", "--bool", "core.sparseCheckout").Output()
+	return err == nil && strings.TrimSpace(string(out)) == "true"
+}
+
+// SparseCheckoutDirs returns the directories of a sparse checkout, or nil if
+// the repository is not sparse
+func SparseCheckoutDirs(path string) ([]string, error) {
+	if !IsSparse(path) {
+		return nil, nil
+	}
+	out, err := exec.Command("git", "-C", path, "sparse-checkout", "list").Output()
+	if err != nil {
+		return nil, err
+	}
+	dirs := []string{}
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line != "" {
+			dirs = append(dirs, line)
+		}
+	}
+	return dirs, nil
+}
+
+// NormalizeSparseDirs returns dirs as 'git sparse-checkout list' reports them
+// in cone mode: cleaned, without leading or trailing slashes, sorted, and
+// without duplicates or directories inside another listed one
+func NormalizeSparseDirs(dirs []string) []string {
+	var cleaned []string
+	for _, dir := range dirs {
+		dir = strings.Trim(path.Clean(filepath.ToSlash(strings.TrimSpace(dir))), "/")
+		if dir != "" && dir != "." {
+			cleaned = append(cleaned, dir)
+		}
+	}
+	sort.Strings(cleaned)
+
+	normalized := []string{}
+	listed := make(map[string]bool)
+	for _, dir := range cleaned {
+		inside := false
+		for parent := dir; parent != "."; parent = path.Dir(parent) {
+			if listed[parent] {
+				inside = true
+				break
+			}
+		}
+		if !inside {
+			listed[dir] = true
+			normalized = append(normalized, dir)
+		}
+	}
+	return normalized
+}
+
+// IsShallow checks if the repository is a shallow clone
+func IsShallow(path string) bool {
+	out, err := exec.Command("git", "-C", path, "rev-parse", "--is-shallow-repository").Output()
+	return err == nil && strings.TrimSpace(string(out)) == "true"
+}
+
+// PartialCloneFilter returns the filter of a partial clone, or "" for a full clone
+func PartialCloneFilter(path string) string {
+	out, err := exec.Command("git", "-C", path, "config", "remote.origin.partialclonefilter").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,129 @@
+package git
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupMonorepo creates a repo with two commits touching apps/api and apps/web.
+// Returns a file:// URL, since git ignores --depth and --filter for local paths.
+func setupMonorepo(t *testing.T) string {
+	t.Helper()
+	dir := setupTestRepoWithCommit(t)
+	exec.Command("git", "-C", dir, "config", "uploadpack.allowFilter", "true").Run()
+	for _, file := range []string{"apps/api/main.go", "apps/web/index.html"} {
+		os.MkdirAll(filepath.Join(dir, filepath.Dir(file)), 0755)
+		os.WriteFile(filepath.Join(dir, file), []byte(file), 0644)
+		exec.Command("git", "-C", dir, "add", ".").Run()
+		exec.Command("git", "-C", dir, "commit", "-m", "Add "+file).Run()
+	}
+	return "file://" + filepath.ToSlash(dir)
+}
+
+func TestCloneWithOptions(t *testing.T) {
+	remote := setupMonorepo(t)
+	dst := filepath.Join(t.TempDir(), "mono")
+
+	opts := CloneOptions{Depth: 1, Filter: "blob:none", Sparse: []string{"apps/api"}}
+	if err := CloneWithOptions(remote, dst, opts, io.Discard); err != nil {
+		t.Fatalf("CloneWithOptions failed: %v", err)
+	}
+
+	if !IsShallow(dst) {
+		t.Error("clone should be shallow")
+	}
+	if filter := PartialCloneFilter(dst); filter != "blob:none" {
+		t.Errorf("filter = %q, want blob:none", filter)
+	}
+	if dirs, _ := SparseCheckoutDirs(dst); strings.Join(dirs, ",") != "apps/api" {
+		t.Errorf("sparse dirs = %v, want [apps/api]", dirs)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "apps/api/main.go")); err != nil {
+		t.Error("sparse directory should be checked out")
+	}
+	if _, err := os.Stat(filepath.Join(dst, "apps/web")); !os.IsNotExist(err) {
+		t.Error("directories outside the sparse checkout should not be checked out")
+	}
+
+	// Files left out by the sparse checkout are not keep files
+	if skipped, _ := ListSkipWorktree(dst); len(skipped) != 0 {
+		t.Errorf("ListSkipWorktree should ignore sparse files, got %v", skipped)
+	}
+
+	if err := DisableSparseCheckout(dst); err != nil {
+		t.Fatalf("DisableSparseCheckout failed: %v", err)
+	}
+	if dirs, _ := SparseCheckoutDirs(dst); dirs != nil {
+		t.Errorf("sparse dirs after disable = %v, want nil", dirs)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "apps/web/index.html")); err != nil {
+		t.Error("full working tree should be restored")
+	}
+}
+
+func TestInitRepoWithOptions(t *testing.T) {
+	remote := setupMonorepo(t)
+	dst := t.TempDir()
+	for _, file := range []string{"README.md", "apps/api/main.go", "apps/web/index.html"} {
+		os.MkdirAll(filepath.Join(dst, filepath.Dir(file)), 0755)
+		os.WriteFile(filepath.Join(dst, file), []byte(file), 0644)
+	}
+
+	opts := CloneOptions{Depth: 1, Sparse: []string{"apps/api"}}
+	if err := InitRepoWithOptions(dst, remote, opts, io.Discard); err != nil {
+		t.Fatalf("InitRepoWithOptions failed: %v", err)
+	}
+
+	if !IsShallow(dst) {
+		t.Error("repository should be shallow")
+	}
+	if _, err := os.Stat(filepath.Join(dst, "apps/api/main.go")); err != nil {
+		t.Error("files in the sparse checkout should be kept")
+	}
+	if _, err := os.Stat(filepath.Join(dst, "apps/web")); !os.IsNotExist(err) {
+		t.Error("clean files outside the sparse checkout should be removed")
+	}
+}
+
+func TestPull_KeepsShallowClone(t *testing.T) {
+	remote := setupMonorepo(t)
+	dst := filepath.Join(t.TempDir(), "mono")
+	CloneWithOptions(remote, dst, CloneOptions{Depth: 1}, io.Discard)
+
+	src := strings.TrimPrefix(remote, "file://")
+	os.WriteFile(filepath.Join(src, "new.txt"), []byte("new"), 0644)
+	exec.Command("git", "-C", src, "add", ".").Run()
+	exec.Command("git", "-C", src, "commit", "-m", "New commit").Run()
+
+	if err := Pull(dst); err != nil {
+		t.Fatalf("Pull failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "new.txt")); err != nil {
+		t.Error("pull should bring in new.txt")
+	}
+	// Only the new commit is added to the shallow history
+	if out, _ := exec.Command("git", "-C", dst, "rev-list", "--count", "HEAD").Output(); strings.TrimSpace(string(out)) != "2" {
+		t.Errorf("history should stay shallow, got %s commits", strings.TrimSpace(string(out)))
+	}
+}
+
+func TestNormalizeSparseDirs(t *testing.T) {
+	tests := []struct {
+		dirs []string
+		want string
+	}{
+		{[]string{"b/c/", "a"}, "a,b/c"},
+		{[]string{"./d", "/e/"}, "d,e"},
+		{[]string{"a", "a-b", "a/x", "a"}, "a,a-b"},
+		{[]string{"", "."}, ""},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(NormalizeSparseDirs(tt.dirs), ","); got != tt.want {
+			t.Errorf("NormalizeSparseDirs(%q) = %q, want %q", tt.dirs, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Submodule is an entry of .gitmodules
+type Submodule struct {
+	Name    string
+	Path    string
+	URL     string // As written, possibly relative to the superproject's remote
+	Branch  string
+	Shallow bool
+}
+
+// ListSubmodules returns the submodules in .gitmodules of the repository at
+// repoRoot, in file order. Returns nil if there is no .gitmodules.
+func ListSubmodules(repoRoot string) ([]Submodule, error) {
+	gitmodules := filepath.Join(repoRoot, ".gitmodules")
+	if _, err := os.Stat(gitmodules); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	// -z: "key\nvalue\x00"; names may contain dots, the variable is the last part
+	out, err := exec.Command("git", "config", "-f", gitmodules, "-z", "--get-regexp", `^submodule\.`).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return nil, nil // No submodule entries
+		}
+		return nil, err
+	}
+
+	var subs []Submodule
+	index := map[string]int{}
+	for _, entry := range strings.Split(string(out), "\x00") {
+		key, value, _ := strings.Cut(entry, "\n")
+		key = strings.TrimPrefix(key, "submodule.")
+		dot := strings.LastIndex(key, ".")
+		if dot <= 0 {
+			continue
+		}
+		name, variable := key[:dot], key[dot+1:]
+
+		i, ok := index[name]
+		if !ok {
+			i = len(subs)
+			index[name] = i
+			subs = append(subs, Submodule{Name: name})
+		}
+		switch variable {
+		case "path":
+			subs[i].Path = value
+		case "url":
+			subs[i].URL = value
+		case "branch":
+			subs[i].Branch = value
+		case "shallow":
+			subs[i].Shallow = value == "true"
+		}
+	}
+	return subs, nil
+}
+
+// SubmoduleURL returns the URL of a submodule with a relative URL resolved
+// against the superproject's remote, as 'git submodule init' does
+func SubmoduleURL(repoRoot string, sub Submodule) (string, error) {
+	if out, err := exec.Command("git", "-C", repoRoot, "submodule", "init", "-q", "--", sub.Path).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	out, err := exec.Command("git", "-C", repoRoot, "config", "--get", "submodule."+sub.Name+".url").Output()
+	if err != nil {
+		return "", fmt.Errorf("no URL for submodule %s", sub.Name)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GetGitlink returns the commit the index of the repository at repoRoot
+// records for the submodule at path
+func GetGitlink(repoRoot, path string) (string, error) {
+	out, err := exec.Command("git", "-C", repoRoot, "ls-files", "-s", "--", path).Output()
+	if err != nil {
+		return "", err
+	}
+	// "160000 <commit> <stage>\t<path>"
+	fields := strings.Fields(string(out))
+	if len(fields) < 2 || fields[0] != "160000" {
+		return "", fmt.Errorf("%s is not a submodule in the index", path)
+	}
+	return fields[1], nil
+}
+
+// EmbedGitDir turns the submodule checked out at path into a standalone
+// repository: its git directory moves from the superproject's .git/modules
+// to path/.git, and core.worktree is unset
+func EmbedGitDir(path string) error {
+	dotGit := filepath.Join(path, ".git")
+	info, err := os.Lstat(dotGit)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		out, err := exec.Command("git", "-C", path, "rev-parse", "--absolute-git-dir").Output()
+		if err != nil {
+			return fmt.Errorf("failed to find git directory: %w", err)
+		}
+		gitDir := strings.TrimSpace(string(out))
+		if err := os.Remove(dotGit); err != nil {
+			return err
+		}
+		if err := os.Rename(gitDir, dotGit); err != nil {
+			os.WriteFile(dotGit, []byte("gitdir: "+gitDir+"\n"), 0644)
+			return err
+		}
+	}
+
+	// Edited as a file: the stale core.worktree would fail repository setup.
+	// Exit code 5: it was not set.
+	cmd := exec.Command("git", "config", "-f", filepath.Join(dotGit, "config"), "--unset", "core.worktree")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 5 {
+			return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+		}
+	}
+	return nil
+}
+
+// RemoveSubmodule removes a submodule from .gitmodules (deleting the file
+// when no submodule is left) and .git/config, and removes its gitlink from the
+// index. The changes are staged; the working tree of the submodule is kept.
+func RemoveSubmodule(repoRoot string, sub Submodule) error {
+	if out, err := exec.Command("git", "-C", repoRoot, "rm", "-q", "--cached", "--ignore-unmatch", "--", sub.Path).CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+
+	gitmodules := filepath.Join(repoRoot, ".gitmodules")
+	if out, err := exec.Command("git", "config", "-f", gitmodules, "--remove-section", "submodule."+sub.Name).CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	// Only present once the submodule was initialized
+	exec.Command("git", "-C", repoRoot, "config", "--remove-section", "submodule."+sub.Name).Run()
+
+	remaining, err := ListSubmodules(repoRoot)
+	if err != nil {
+		return err
+	}
+	if len(remaining) == 0 {
+		if out, err := exec.Command("git", "-C", repoRoot, "rm", "-q", "-f", "--cached", "--ignore-unmatch", "--", ".gitmodules").CombinedOutput(); err != nil {
+			return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+		}
+		return os.Remove(gitmodules)
+	}
+	return stageGitmodules(repoRoot)
+}
+
+// AddSubmodule records the repository checked out at sub.Path as a submodule
+// at commit: it is added to .gitmodules and .git/config, and the files the
+// index tracks below sub.Path are replaced by its gitlink. The changes are
+// staged; the repository keeps its .git directory.
+func AddSubmodule(repoRoot string, sub Submodule, commit string) error {
+	gitmodules := filepath.Join(repoRoot, ".gitmodules")
+	settings := [][2]string{{"path", sub.Path}, {"url", sub.URL}}
+	if sub.Branch != "" {
+		settings = append(settings, [2]string{"branch", sub.Branch})
+	}
+	if sub.Shallow {
+		settings = append(settings, [2]string{"shallow", "true"})
+	}
+	for _, s := range settings {
+		if out, err := exec.Command("git", "config", "-f", gitmodules, "submodule."+sub.Name+"."+s[0], s[1]).CombinedOutput(); err != nil {
+			return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+		}
+	}
+
+	commands := [][]string{
+		{"rm", "-r", "-q", "--cached", "--ignore-unmatch", "--", sub.Path},
+		{"update-index", "--add", "--cacheinfo", "160000," + commit + "," + sub.Path},
+	}
+	for _, args := range commands {
+		if out, err := exec.Command("git", append([]string{"-C", repoRoot}, args...)...).CombinedOutput(); err != nil {
+			return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+		}
+	}
+	if err := stageGitmodules(repoRoot); err != nil {
+		return err
+	}
+
+	if out, err := exec.Command("git", "-C", repoRoot, "submodule", "init", "-q", "--", sub.Path).CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// IsOnRemote reports whether commit is reachable from a remote-tracking
+// branch of the repository at path, i.e. whether others can fetch it
+func IsOnRemote(path, commit string) bool {
+	out, err := exec.Command("git", "-C", path, "branch", "-r", "--contains", commit).Output()
+	return err == nil && strings.TrimSpace(string(out)) != ""
+}
+
+func stageGitmodules(repoRoot string) error {
+	if out, err := exec.Command("git", "-C", repoRoot, "add", "--", ".gitmodules").CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
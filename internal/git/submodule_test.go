@@ -0,0 +1,50 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestListSubmodules(t *testing.T) {
+	dir := setupTestRepo(t)
+	if subs, err := ListSubmodules(dir); err != nil || subs != nil {
+		t.Errorf("without .gitmodules: got %v, %v", subs, err)
+	}
+
+	os.WriteFile(filepath.Join(dir, ".gitmodules"), []byte(`[submodule "libs/core"]
+	path = libs/core
+	url = ../core.git
+	branch = main
+[submodule "v1.2"]
+	path = vendor/v1.2
+	url = https://example.com/v.git
+	shallow = true
+`), 0644)
+
+	subs, err := ListSubmodules(dir)
+	if err != nil {
+		t.Fatalf("ListSubmodules() error = %v", err)
+	}
+	want := []Submodule{
+		{Name: "libs/core", Path: "libs/core", URL: "../core.git", Branch: "main"},
+		{Name: "v1.2", Path: "vendor/v1.2", URL: "https://example.com/v.git", Shallow: true},
+	}
+	if len(subs) != len(want) || subs[0] != want[0] || subs[1] != want[1] {
+		t.Errorf("ListSubmodules() = %+v, want %+v", subs, want)
+	}
+}
+
+func TestGetGitlink(t *testing.T) {
+	dir := setupTestRepoWithCommit(t)
+	commit, _ := GetCurrentCommit(dir)
+
+	exec.Command("git", "-C", dir, "update-index", "--add", "--cacheinfo", "160000,"+commit+",libs/core").Run()
+	if got, err := GetGitlink(dir, "libs/core"); err != nil || got != commit {
+		t.Errorf("GetGitlink() = %q, %v, want %q", got, err, commit)
+	}
+	if _, err := GetGitlink(dir, "README.md"); err == nil {
+		t.Error("should error for a regular file")
+	}
+}
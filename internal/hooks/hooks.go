@@ -113,6 +113,15 @@ func InstallWorkspaceHook(workspacePath string) error {
 	return os.WriteFile(hookPath, []byte(postCommitHook), 0755)
 }
 
+// UninstallWorkspaceHook removes the post-commit hook of a workspace
+// repository, if it is the one installed by InstallWorkspaceHook
+func UninstallWorkspaceHook(workspacePath string) error {
+	if !IsWorkspaceHookInstalled(workspacePath) {
+		return nil
+	}
+	return os.Remove(filepath.Join(workspacePath, ".git", "hooks", "post-commit"))
+}
+
 // IsWorkspaceHookInstalled checks if the workspace hook is installed
 func IsWorkspaceHookInstalled(workspacePath string) bool {
 	hookPath := filepath.Join(workspacePath, ".git", "hooks", "post-commit")
@@ -214,3 +214,33 @@ func TestIsInstalled(t *testing.T) {
 		}
 	})
 }
+
+func TestUninstallWorkspaceHook(t *testing.T) {
+	t.Run("removes installed hook", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := InstallWorkspaceHook(dir); err != nil {
+			t.Fatal(err)
+		}
+		if err := UninstallWorkspaceHook(dir); err != nil {
+			t.Fatalf("UninstallWorkspaceHook failed: %v", err)
+		}
+		if IsWorkspaceHookInstalled(dir) {
+			t.Error("hook should be removed")
+		}
+	})
+
+	t.Run("keeps custom hook", func(t *testing.T) {
+		dir := t.TempDir()
+		hooksDir := filepath.Join(dir, ".git", "hooks")
+		os.MkdirAll(hooksDir, 0755)
+		hookPath := filepath.Join(hooksDir, "post-commit")
+		os.WriteFile(hookPath, []byte("custom"), 0755)
+
+		if err := UninstallWorkspaceHook(dir); err != nil {
+			t.Fatalf("UninstallWorkspaceHook failed: %v", err)
+		}
+		if _, err := os.Stat(hookPath); err != nil {
+			t.Error("custom hook should be kept")
+		}
+	})
+}
@@ -21,12 +21,16 @@ var messages = map[string]map[string]string{
 		"failed_read_input":   "✗ Failed to read input: %v",
 		"no_subs_registered":  "No repositories registered",
 		"sub_not_found":       "repository not found: %s",
+		"no_subs_in_group":    "no repositories in group: %s",
+		"no_subs_selected":    "no repositories match the selection",
+		"pull_failures":       "pull failed or conflicted in %d repository(ies)",
 
 		// Status command
 		"local_status":        "Local Status:",
 		"files_modified":      "✗ %d file(s) modified:",
 		"files_untracked":     "⚠ %d file(s) untracked:",
 		"files_staged":        "● %d file(s) staged:",
+		"keep_conflicts":      "✗ %d keep file(s) with merge conflicts:",
 		"clean_working_tree":  "✓ Clean working tree",
 		"remote_status":       "Remote Status:",
 		"commits_behind":      "→ %d commit(s) behind origin/%s",
@@ -43,12 +47,17 @@ var messages = map[string]map[string]string{
 		"how_to_resolve":      "How to resolve:",
 		"no_action_needed":    "✓ No action needed",
 		"not_cloned":          "(not cloned)",
+		"shallow_clone":       "shallow",
+		"partial_clone":       "partial: %s",
+		"sparse_checkout":     "sparse: %s",
 		"resolve_commit":      "1. Commit or stash changes:",
 		"resolve_or_gitignore": "# Or add untracked files to .gitignore",
 		"resolve_pull":        "2. Pull updates:",
 		"resolve_push":        "3. Push commits:",
 		"resolve_skip":        "4. (Optional) Update skip files:",
 		"resolve_review":      "# Review and merge changes",
+		"resolve_keep_conflicts": "Resolve the <<<<<<< / >>>>>>> markers in the keep files, then:",
+		"resolve_remote":      "Point origin at the manifest repo, or record origin in the manifest:",
 
 		// Sync command
 		"syncing":              "Syncing configuration...",
@@ -60,6 +69,9 @@ var messages = map[string]map[string]string{
 		"to_add_sub":           "\nTo add a repository, use:",
 		"cmd_git_sub_clone":    "  git multirepo clone <url> <path>",
 		"created_gitsubs":      "\n✓ Created .git.multirepos with %d repository(s)",
+		"discovering":          "\n→ Scanning for unregistered repositories...",
+		"registered_discovered": "\n✓ Registered %d repository(s) in .git.multirepos",
+		"no_unregistered":      "✓ No unregistered repositories found",
 		"applying_ignore":      "\n→ Applying ignore patterns",
 		"applied_patterns":     "✓ Applied %d patterns",
 		"applying_skip_mother": "→ Applying skip-worktree to mother repo",
@@ -92,9 +104,30 @@ var messages = map[string]map[string]string{
 		"completed_issues":     "⚠ Completed with %d issue(s)",
 		"all_success":          "✓ All configurations applied successfully",
 		"found_sub":            "Found repository: %s",
-		"failed_get_remote":    "⚠ %s: failed to get remote URL: %v",
+		"no_remote_skipped":    "○ %s: no origin remote, not registered (set discover.local_repos to register it)",
+		"no_remote_label":      "no remote",
+		"skipped_worktree":     "○ %s: git worktree of %s, not registered",
+		"skipped_submodule":    "○ %s: submodule, not registered (convert with 'git multirepo import-submodules')",
+		"skipped_gitfile":      "○ %s: .git is a file (separate git directory), not registered",
+		"ignored_dirs_failed":  "⚠ Failed to read .gitignore, scanning ignored directories too: %v",
+		"local_not_cloned":     "○ No repo URL (local repository), not cloned",
 		"failed_get_commit":    "⚠ %s: failed to get commit: %v",
 		"failed_scan":          "failed to scan directories: %w",
+		"checking_out_locked":    "→ Checking out locked commit %s",
+		"locked_checkout_failed": "✗ Failed to check out locked commit: %v",
+		"lock_drift":             "✗ At %s but %s is locked (drift)",
+		"lock_drift_note":        "ℹ At %s, %s is locked ('git multirepo lock' records the current commit)",
+		"include_not_found":      "⚠ Include not found: %s",
+		"sparse_updated":         "✓ Sparse checkout: %s",
+		"sparse_disabled":        "✓ Sparse checkout disabled",
+		"sparse_failed":          "⚠ Failed to update sparse checkout: %v",
+		"origin_drift":           "⚠ origin is %s, manifest repo is %s",
+		"origin_missing":         "⚠ No origin remote, manifest repo is %s",
+		"remote_drift_hint":      "→ Use sync --update-remotes (origin ← manifest) or --adopt-remotes (manifest ← origin)",
+		"remote_updated":         "✓ origin set to %s",
+		"remote_update_failed":   "✗ Failed to set origin: %v",
+		"remote_adopted":         "✓ Manifest repo set to %s",
+		"remote_adopt_failed":    "✗ Failed to update manifest repo: %v",
 	},
 	"ko": {
 		// Pull command
@@ -111,12 +144,16 @@ var messages = map[string]map[string]string{
 		"failed_read_input":   "✗ 입력 읽기 실패: %v",
 		"no_subs_registered":  "등록된 repository가 없습니다",
 		"sub_not_found":       "repository를 찾을 수 없음: %s",
+		"no_subs_in_group":    "그룹에 repository가 없음: %s",
+		"no_subs_selected":    "선택과 일치하는 repository가 없음",
+		"pull_failures":       "%d개 repository에서 pull 실패 또는 충돌",
 
 		// Status command
 		"local_status":        "로컬 상태:",
 		"files_modified":      "✗ %d개 파일 수정됨:",
 		"files_untracked":     "⚠ %d개 파일 추적 안 됨:",
 		"files_staged":        "● %d개 파일 스테이징됨:",
+		"keep_conflicts":      "✗ %d개 keep 파일에 병합 충돌:",
 		"clean_working_tree":  "✓ 작업 트리 깨끗함",
 		"remote_status":       "원격 상태:",
 		"commits_behind":      "→ origin/%s보다 %d개 커밋 뒤처짐",
@@ -133,12 +170,17 @@ var messages = map[string]map[string]string{
 		"how_to_resolve":      "해결 방법:",
 		"no_action_needed":    "✓ 조치 필요 없음",
 		"not_cloned":          "(복제되지 않음)",
+		"shallow_clone":       "shallow",
+		"partial_clone":       "partial: %s",
+		"sparse_checkout":     "sparse: %s",
 		"resolve_commit":      "1. 변경사항 커밋 또는 stash:",
 		"resolve_or_gitignore": "# 또는 추적 안 된 파일을 .gitignore에 추가",
 		"resolve_pull":        "2. 업데이트 받기:",
 		"resolve_push":        "3. 커밋 푸시:",
 		"resolve_skip":        "4. (선택) skip 파일 업데이트:",
 		"resolve_review":      "# 변경사항 검토 및 병합",
+		"resolve_keep_conflicts": "keep 파일의 <<<<<<< / >>>>>>> 표시를 해결한 후:",
+		"resolve_remote":      "origin을 manifest repo로 맞추거나, origin을 manifest에 기록:",
 
 		// Sync command
 		"syncing":              "동기화 중...",
@@ -150,6 +192,9 @@ var messages = map[string]map[string]string{
 		"to_add_sub":           "\nrepository를 추가하려면:",
 		"cmd_git_sub_clone":    "  git multirepo clone <url> <path>",
 		"created_gitsubs":      "\n✓ %d개 repository로 .git.multirepos 생성됨",
+		"discovering":          "\n→ 등록되지 않은 repository 검색 중...",
+		"registered_discovered": "\n✓ .git.multirepos에 %d개 repository 등록됨",
+		"no_unregistered":      "✓ 등록되지 않은 repository 없음",
 		"applying_ignore":      "\n→ ignore 패턴 적용 중",
 		"applied_patterns":     "✓ %d개 패턴 적용됨",
 		"applying_skip_mother": "→ 메인 저장소에 skip-worktree 적용 중",
@@ -182,9 +227,30 @@ var messages = map[string]map[string]string{
 		"completed_issues":     "⚠ %d개 문제와 함께 완료됨",
 		"all_success":          "✓ 모든 설정이 성공적으로 적용됨",
 		"found_sub":            "Repository 발견: %s",
-		"failed_get_remote":    "⚠ %s: 원격 URL 가져오기 실패: %v",
+		"no_remote_skipped":    "○ %s: origin 원격 없음, 등록하지 않음 (등록하려면 discover.local_repos 설정)",
+		"no_remote_label":      "원격 없음",
+		"skipped_worktree":     "○ %s: %s의 git worktree, 등록하지 않음",
+		"skipped_submodule":    "○ %s: 서브모듈, 등록하지 않음 ('git multirepo import-submodules'로 변환)",
+		"skipped_gitfile":      "○ %s: .git이 파일임 (별도 git 디렉토리), 등록하지 않음",
+		"ignored_dirs_failed":  "⚠ .gitignore 읽기 실패, 무시된 디렉토리도 검색: %v",
+		"local_not_cloned":     "○ repo URL 없음 (로컬 repository), clone하지 않음",
 		"failed_get_commit":    "⚠ %s: 커밋 가져오기 실패: %v",
 		"failed_scan":          "디렉토리 스캔 실패: %w",
+		"checking_out_locked":    "→ 잠긴 커밋 %s 체크아웃 중",
+		"locked_checkout_failed": "✗ 잠긴 커밋 체크아웃 실패: %v",
+		"lock_drift":             "✗ 현재 %s, 잠긴 커밋은 %s (불일치)",
+		"lock_drift_note":        "ℹ 현재 %s, 잠긴 커밋은 %s ('git multirepo lock'으로 현재 커밋 기록)",
+		"include_not_found":      "⚠ include 파일을 찾을 수 없음: %s",
+		"sparse_updated":         "✓ Sparse checkout: %s",
+		"sparse_disabled":        "✓ Sparse checkout 해제됨",
+		"sparse_failed":          "⚠ Sparse checkout 업데이트 실패: %v",
+		"origin_drift":           "⚠ origin은 %s, manifest repo는 %s",
+		"origin_missing":         "⚠ origin 원격 없음, manifest repo는 %s",
+		"remote_drift_hint":      "→ sync --update-remotes (origin ← manifest) 또는 --adopt-remotes (manifest ← origin) 사용",
+		"remote_updated":         "✓ origin 설정됨: %s",
+		"remote_update_failed":   "✗ origin 설정 실패: %v",
+		"remote_adopted":         "✓ Manifest repo 설정됨: %s",
+		"remote_adopt_failed":    "✗ Manifest repo 업데이트 실패: %v",
 	},
 }
 
@@ -0,0 +1,431 @@
+// Package journal records the state a git-multirepo command is about to change
+// (manifest, lock file, .gitignore, .gitmodules and skip-worktree flags) so that it can be undone.
+package journal
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/yejune/git-multirepo/internal/common"
+	"github.com/yejune/git-multirepo/internal/git"
+	"github.com/yejune/git-multirepo/internal/manifest"
+	"github.com/yejune/git-multirepo/internal/patch"
+	"gopkg.in/yaml.v3"
+)
+
+// Dir is the journal directory, relative to the repository root
+var Dir = filepath.Join(".multirepos", "journal")
+
+// MaxEntries is the number of entries kept; older ones are pruned by Begin
+var MaxEntries = 20
+
+// MotherRepo is the SkipWorktree key of the repository holding the manifest
+const MotherRepo = "."
+
+// idLayout is the timestamp format of entry IDs
+const idLayout = "20060102-150405"
+
+// entryFileName is the metadata file inside each entry directory
+const entryFileName = "entry.yaml"
+
+// trackedFiles are the files, relative to the repository root, saved by each entry
+var trackedFiles = []string{
+	manifest.FileName,
+	manifest.LockFileName,
+	".gitignore",
+	".gitmodules",
+	filepath.Join(".multirepos", patch.ConflictsFileName),
+}
+
+// File is a tracked file as it was before the command
+type File struct {
+	Path    string `yaml:"path"`
+	Missing bool   `yaml:"missing,omitempty"` // The file did not exist; undo removes it
+}
+
+// Move is a directory the command moved, both paths relative to the repository root
+type Move struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+}
+
+// Entry is the state saved before one command.
+// Its directory holds entry.yaml, files/ with the saved files and
+// trash/ with the workspace directories the command deleted.
+type Entry struct {
+	ID           string              `yaml:"id"`
+	Command      string              `yaml:"command"`
+	Time         time.Time           `yaml:"time"`
+	Files        []File              `yaml:"files"`
+	SkipWorktree map[string][]string `yaml:"skip_worktree,omitempty"` // Repository path ("." for the mother repo) -> files
+	Trashed      []string            `yaml:"trash,omitempty"`         // Workspace paths moved to trash/
+	Moves        []Move              `yaml:"moves,omitempty"`
+
+	dir      string
+	repoRoot string
+}
+
+// Begin saves the current state of repoRoot and the given workspaces before command runs.
+// Call Finish when the command is done.
+func Begin(repoRoot, command string, workspaces []string) (*Entry, error) {
+	root := filepath.Join(repoRoot, Dir)
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create journal directory: %w", err)
+	}
+	// Trashed workspaces are repositories; keep them out of the mother repo's index
+	ignore := filepath.Join(root, ".gitignore")
+	if _, err := os.Stat(ignore); os.IsNotExist(err) {
+		os.WriteFile(ignore, []byte("*\n"), 0644)
+	}
+
+	now := time.Now()
+	e := &Entry{ID: now.Format(idLayout), Command: command, Time: now, repoRoot: repoRoot}
+	for n := 2; ; n++ {
+		e.dir = filepath.Join(root, e.ID)
+		if err := os.Mkdir(e.dir, 0755); err == nil {
+			break
+		} else if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to create journal entry: %w", err)
+		}
+		e.ID = fmt.Sprintf("%s-%d", now.Format(idLayout), n)
+	}
+
+	for _, name := range trackedFiles {
+		data, err := os.ReadFile(filepath.Join(repoRoot, name))
+		if os.IsNotExist(err) {
+			e.Files = append(e.Files, File{Path: name, Missing: true})
+			continue
+		}
+		if err == nil {
+			err = writeFile(filepath.Join(e.dir, "files", name), data)
+		}
+		if err != nil {
+			os.RemoveAll(e.dir)
+			return nil, fmt.Errorf("failed to save %s: %w", name, err)
+		}
+		e.Files = append(e.Files, File{Path: name})
+	}
+
+	e.SkipWorktree = skipWorktreeState(repoRoot, workspaces)
+
+	if err := e.save(); err != nil {
+		os.RemoveAll(e.dir)
+		return nil, err
+	}
+	return e, nil
+}
+
+// skipWorktreeState lists the skip-worktree files of the mother repo and
+// every cloned workspace
+func skipWorktreeState(repoRoot string, workspaces []string) map[string][]string {
+	state := make(map[string][]string)
+	for _, path := range append([]string{MotherRepo}, workspaces...) {
+		fullPath := filepath.Join(repoRoot, path)
+		if !git.IsRepo(fullPath) {
+			continue
+		}
+		files, err := git.ListSkipWorktree(fullPath)
+		if err != nil {
+			continue
+		}
+		sort.Strings(files)
+		state[path] = files
+	}
+	return state
+}
+
+// Trash moves the workspace directory at path (relative to the repository root)
+// into the entry instead of deleting it
+func (e *Entry) Trash(path string) error {
+	dst := filepath.Join(e.dir, "trash", path)
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(filepath.Join(e.repoRoot, path), dst); err != nil {
+		return err
+	}
+	e.Trashed = append(e.Trashed, path)
+	return e.save()
+}
+
+// Moved records that the command moved a directory, so that Undo moves it back.
+// It is a no-op on a nil entry.
+func (e *Entry) Moved(from, to string) error {
+	if e == nil {
+		return nil
+	}
+	e.Moves = append(e.Moves, Move{From: from, To: to})
+	return e.save()
+}
+
+// Finish drops the entry if the command changed nothing it records, or
+// prunes old entries to make room for it otherwise.
+// It is a no-op on a nil entry, so callers can ignore a failed Begin.
+func (e *Entry) Finish() error {
+	if e == nil {
+		return nil
+	}
+	if e.changed() {
+		prune(e.repoRoot)
+		return nil
+	}
+	return os.RemoveAll(e.dir)
+}
+
+// changed reports whether the command changed anything the entry records
+func (e *Entry) changed() bool {
+	if len(e.Trashed) > 0 || len(e.Moves) > 0 {
+		return true
+	}
+
+	for _, f := range e.Files {
+		current, err := os.ReadFile(filepath.Join(e.repoRoot, f.Path))
+		if f.Missing != os.IsNotExist(err) {
+			return true
+		}
+		if f.Missing {
+			continue
+		}
+		saved, _ := os.ReadFile(filepath.Join(e.dir, "files", f.Path))
+		if err != nil || !bytes.Equal(current, saved) {
+			return true
+		}
+	}
+
+	var workspaces []string
+	for path := range e.SkipWorktree {
+		if path != MotherRepo {
+			workspaces = append(workspaces, path)
+		}
+	}
+	current := skipWorktreeState(e.repoRoot, workspaces)
+	for path, files := range e.SkipWorktree {
+		if !equalStrings(current[path], files) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Undo restores the state saved in the entry, then removes the entry.
+// Moved directories are moved back and trashed workspaces restored, tracked
+// files are rewritten (or removed if they did not exist) and skip-worktree
+// flags are set as they were.
+func (e *Entry) Undo() error {
+	// Directories are put back one at a time, and the entry saved after each,
+	// so that undo can be retried if a later step fails
+	for len(e.Moves) > 0 {
+		move := e.Moves[len(e.Moves)-1]
+		src := filepath.Join(e.repoRoot, move.To)
+		if _, err := os.Lstat(src); err == nil {
+			if err := restoreDir(src, filepath.Join(e.repoRoot, move.From), move.From); err != nil {
+				return err
+			}
+			common.RemoveEmptyParents(filepath.Dir(src), e.repoRoot)
+		}
+		e.Moves = e.Moves[:len(e.Moves)-1]
+		if err := e.save(); err != nil {
+			return err
+		}
+	}
+
+	for len(e.Trashed) > 0 {
+		path := e.Trashed[0]
+		if err := restoreDir(filepath.Join(e.dir, "trash", path), filepath.Join(e.repoRoot, path), path); err != nil {
+			return err
+		}
+		e.Trashed = e.Trashed[1:]
+		if err := e.save(); err != nil {
+			return err
+		}
+	}
+
+	err := manifest.WithLock(e.repoRoot, func() error {
+		for _, f := range e.Files {
+			target := filepath.Join(e.repoRoot, f.Path)
+			if f.Missing {
+				if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+					return fmt.Errorf("failed to remove %s: %w", f.Path, err)
+				}
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(e.dir, "files", f.Path))
+			if err == nil {
+				err = manifest.WriteFileAtomic(target, data)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to restore %s: %w", f.Path, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for path, files := range e.SkipWorktree {
+		if err := restoreSkipWorktree(filepath.Join(e.repoRoot, path), files); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	return os.RemoveAll(e.dir)
+}
+
+// restoreDir moves src back to dst, which must be missing or an empty directory
+func restoreDir(src, dst, name string) error {
+	if entries, err := os.ReadDir(dst); err == nil {
+		if len(entries) > 0 {
+			return fmt.Errorf("cannot restore %s: directory exists and is not empty", name)
+		}
+		os.Remove(dst)
+	} else if _, serr := os.Lstat(dst); serr == nil || !os.IsNotExist(serr) {
+		return fmt.Errorf("cannot restore %s: path exists", name)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dst); err != nil {
+		return fmt.Errorf("failed to restore %s: %w", name, err)
+	}
+	return nil
+}
+
+// restoreSkipWorktree sets skip-worktree on exactly the given files of the repository
+func restoreSkipWorktree(repoPath string, files []string) error {
+	if !git.IsRepo(repoPath) {
+		return nil
+	}
+	current, err := git.ListSkipWorktree(repoPath)
+	if err != nil {
+		return err
+	}
+
+	want := make(map[string]bool)
+	for _, f := range files {
+		want[f] = true
+	}
+	var unset []string
+	for _, f := range current {
+		if !want[f] {
+			unset = append(unset, f)
+		}
+	}
+
+	if err := git.UnapplySkipWorktree(repoPath, unset); err != nil {
+		return err
+	}
+	return git.ApplySkipWorktree(repoPath, files)
+}
+
+// List returns the journal entries of repoRoot, newest first
+func List(repoRoot string) ([]*Entry, error) {
+	root := filepath.Join(repoRoot, Dir)
+	dirs, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*Entry
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		e, err := load(repoRoot, filepath.Join(root, d.Name()))
+		if err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].Time.Equal(entries[j].Time) {
+			return entries[i].Time.After(entries[j].Time)
+		}
+		return entries[i].ID > entries[j].ID
+	})
+	return entries, nil
+}
+
+// Find returns the entry with the given ID
+func Find(repoRoot, id string) (*Entry, error) {
+	if id == "" || filepath.Base(id) != id {
+		return nil, fmt.Errorf("invalid journal entry: %q", id)
+	}
+	e, err := load(repoRoot, filepath.Join(repoRoot, Dir, id))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("journal entry not found: %s", id)
+	}
+	return e, err
+}
+
+func load(repoRoot, dir string) (*Entry, error) {
+	data, err := os.ReadFile(filepath.Join(dir, entryFileName))
+	if err != nil {
+		return nil, err
+	}
+	var e Entry
+	if err := yaml.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("failed to parse journal entry %s: %w", filepath.Base(dir), err)
+	}
+	e.dir = dir
+	e.repoRoot = repoRoot
+	return &e, nil
+}
+
+func (e *Entry) save() error {
+	data, err := yaml.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(e.dir, entryFileName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write journal entry: %w", err)
+	}
+	return nil
+}
+
+// prune removes the oldest entries beyond MaxEntries. Entries holding
+// trashed workspaces are not counted and never removed: that would delete
+// the workspaces for good.
+func prune(repoRoot string) {
+	entries, err := List(repoRoot)
+	if err != nil {
+		return
+	}
+	kept := 0
+	for _, e := range entries {
+		if len(e.Trashed) > 0 {
+			continue
+		}
+		if kept++; kept > MaxEntries {
+			os.RemoveAll(e.dir)
+		}
+	}
+}
+
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,183 @@
+package journal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/yejune/git-multirepo/internal/git"
+	"github.com/yejune/git-multirepo/internal/manifest"
+)
+
+func setupRepo(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	exec.Command("git", "-C", dir, "init").Run()
+	exec.Command("git", "-C", dir, "config", "user.email", "test@test.com").Run()
+	exec.Command("git", "-C", dir, "config", "user.name", "Test User").Run()
+	os.WriteFile(filepath.Join(dir, "config.yml"), []byte("a: 1"), 0644)
+	exec.Command("git", "-C", dir, "add", ".").Run()
+	exec.Command("git", "-C", dir, "commit", "-m", "Initial commit").Run()
+	return dir
+}
+
+func TestBeginAndUndo(t *testing.T) {
+	dir := setupRepo(t)
+	manifestPath := filepath.Join(dir, manifest.FileName)
+	os.WriteFile(manifestPath, []byte("workspaces:\n  - path: lib\n    repo: https://example.com/lib.git\n"), 0644)
+
+	lib := filepath.Join(dir, "lib")
+	os.MkdirAll(lib, 0755)
+	os.WriteFile(filepath.Join(lib, "main.go"), []byte("package lib"), 0644)
+
+	entry, err := Begin(dir, "remove lib", []string{"lib"})
+	if err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+
+	// The command removes the workspace, adds .gitignore and hides a file
+	os.WriteFile(manifestPath, []byte("workspaces: []\n"), 0644)
+	os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("lib/.git/\n"), 0644)
+	git.ApplySkipWorktree(dir, []string{"config.yml"})
+	if err := entry.Trash("lib"); err != nil {
+		t.Fatalf("Trash failed: %v", err)
+	}
+	if _, err := os.Stat(lib); !os.IsNotExist(err) {
+		t.Fatal("trashed workspace should be moved away")
+	}
+	entry.Finish()
+
+	entries, err := List(dir)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("List() = %v, %v", entries, err)
+	}
+	e := entries[0]
+	if e.Command != "remove lib" || len(e.Trashed) != 1 {
+		t.Errorf("entry = %+v", e)
+	}
+
+	if err := e.Undo(); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(manifestPath)
+	if string(data) != "workspaces:\n  - path: lib\n    repo: https://example.com/lib.git\n" {
+		t.Errorf("manifest not restored: %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".gitignore")); !os.IsNotExist(err) {
+		t.Error(".gitignore did not exist before and should be removed")
+	}
+	if files, _ := git.ListSkipWorktree(dir); len(files) != 0 {
+		t.Errorf("skip-worktree should be cleared, got %v", files)
+	}
+	if _, err := os.Stat(filepath.Join(lib, "main.go")); err != nil {
+		t.Error("trashed workspace should be restored")
+	}
+	if entries, _ := List(dir); len(entries) != 0 {
+		t.Errorf("undone entry should be removed, got %d", len(entries))
+	}
+}
+
+func TestFinishDropsUnchangedEntry(t *testing.T) {
+	dir := setupRepo(t)
+	os.WriteFile(filepath.Join(dir, manifest.FileName), []byte("workspaces: []\n"), 0644)
+
+	entry, err := Begin(dir, "sync", nil)
+	if err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+	if err := entry.Finish(); err != nil {
+		t.Fatalf("Finish failed: %v", err)
+	}
+	if entries, _ := List(dir); len(entries) != 0 {
+		t.Errorf("entry without changes should be dropped, got %d", len(entries))
+	}
+
+	// Skip-worktree changes alone are kept
+	entry, _ = Begin(dir, "sync", nil)
+	git.ApplySkipWorktree(dir, []string{"config.yml"})
+	entry.Finish()
+	if entries, _ := List(dir); len(entries) != 1 {
+		t.Errorf("entry with skip-worktree changes should be kept, got %d", len(entries))
+	}
+
+	// A nil entry (failed Begin) is ignored
+	var none *Entry
+	if err := none.Finish(); err != nil {
+		t.Errorf("Finish on nil entry = %v", err)
+	}
+}
+
+func TestUndoRefusesOccupiedPath(t *testing.T) {
+	dir := setupRepo(t)
+	os.MkdirAll(filepath.Join(dir, "lib"), 0755)
+	os.WriteFile(filepath.Join(dir, "lib", "old.txt"), []byte("old"), 0644)
+
+	entry, _ := Begin(dir, "remove lib", nil)
+	if err := entry.Trash("lib"); err != nil {
+		t.Fatalf("Trash failed: %v", err)
+	}
+
+	os.MkdirAll(filepath.Join(dir, "lib"), 0755)
+	os.WriteFile(filepath.Join(dir, "lib", "new.txt"), []byte("new"), 0644)
+
+	e, err := Find(dir, entry.ID)
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if err := e.Undo(); err == nil {
+		t.Error("Undo should refuse to overwrite an existing directory")
+	}
+	if _, err := Find(dir, entry.ID); err != nil {
+		t.Error("entry should be kept after a failed undo")
+	}
+	if _, err := Find(dir, "../x"); err == nil {
+		t.Error("Find should reject paths")
+	}
+}
+
+func TestPrune(t *testing.T) {
+	dir := setupRepo(t)
+	saved := MaxEntries
+	MaxEntries = 3
+	defer func() { MaxEntries = saved }()
+	manifestPath := filepath.Join(dir, manifest.FileName)
+
+	// The oldest entry holds a removed workspace
+	os.MkdirAll(filepath.Join(dir, "lib"), 0755)
+	entry, err := Begin(dir, "remove lib", nil)
+	if err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+	entry.Trash("lib")
+	entry.Finish()
+	trashID := entry.ID
+
+	var ids []string
+	for i := 0; i < 5; i++ {
+		entry, err := Begin(dir, "sync", nil)
+		if err != nil {
+			t.Fatalf("Begin failed: %v", err)
+		}
+		os.WriteFile(manifestPath, []byte{byte('a' + i)}, 0644)
+		entry.Finish()
+		ids = append(ids, entry.ID)
+	}
+
+	// A command that changes nothing prunes nothing
+	entry, _ = Begin(dir, "sync", nil)
+	entry.Finish()
+
+	entries, _ := List(dir)
+	if len(entries) != 4 {
+		t.Fatalf("expected 3 entries and the trash entry, got %d", len(entries))
+	}
+	if entries[0].ID != ids[4] || entries[2].ID != ids[2] {
+		t.Errorf("oldest entries should be pruned, got %s..%s", entries[0].ID, entries[2].ID)
+	}
+	if entries[3].ID != trashID {
+		t.Errorf("the entry holding a trashed workspace should be kept, got %s", entries[3].ID)
+	}
+}
@@ -0,0 +1,124 @@
+package manifest
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// resolveIncludes merges the workspaces of the files listed in m.Include into
+// m.Workspaces. Include paths are relative to dir, the directory of the file
+// listing them, and may point into another workspace. Included workspaces come
+// first; a local entry with the same path replaces the included one, and a
+// later include replaces an earlier one. Includes that don't exist (yet, e.g.
+// in a workspace that is not cloned) are recorded in m.missing.
+// visiting holds the files being resolved, to detect include cycles.
+func (m *Manifest) resolveIncludes(dir string, visiting map[string]bool) error {
+	var inherited []WorkspaceEntry
+	for _, include := range m.Include {
+		file := include
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return fmt.Errorf("invalid include %s: %w", include, err)
+		}
+		if visiting[abs] {
+			return fmt.Errorf("include cycle: %s", include)
+		}
+
+		data, err := os.ReadFile(abs)
+		if err != nil {
+			if os.IsNotExist(err) {
+				m.missing = append(m.missing, include)
+				continue
+			}
+			return fmt.Errorf("failed to read include %s: %w", include, err)
+		}
+
+		var sub Manifest
+		if err := yaml.Unmarshal(data, &sub); err != nil {
+			return fmt.Errorf("invalid include %s: %w", include, err)
+		}
+
+		visiting[abs] = true
+		err = sub.resolveIncludes(filepath.Dir(abs), visiting)
+		delete(visiting, abs)
+		if err != nil {
+			return fmt.Errorf("%s: %w", include, err)
+		}
+		m.missing = append(m.missing, sub.missing...)
+
+		for _, ws := range sub.Workspaces {
+			ws.source = include
+			replaced := false
+			for i := range inherited {
+				if inherited[i].Path == ws.Path {
+					inherited[i] = ws
+					replaced = true
+				}
+			}
+			if !replaced {
+				inherited = append(inherited, ws)
+			}
+		}
+	}
+
+	var merged []WorkspaceEntry
+	for _, ws := range inherited {
+		if m.Exists(ws.Path) {
+			continue
+		}
+		original := ws.clone()
+		ws.inherited = &original
+		merged = append(merged, ws)
+	}
+	m.Workspaces = append(merged, m.Workspaces...)
+	return nil
+}
+
+// IncludedFrom returns the include the workspace comes from, or "" if it is
+// listed in the manifest itself
+func (ws *WorkspaceEntry) IncludedFrom() string {
+	return ws.source
+}
+
+// MissingIncludes returns the includes that could not be found when loading
+func (m *Manifest) MissingIncludes() []string {
+	return m.missing
+}
+
+// fileContent returns the manifest as written to its own file: included
+// workspaces are left out unless they were changed locally, in which case
+// the local entry overrides the included one.
+func (m *Manifest) fileContent() *Manifest {
+	local := *m
+	local.Workspaces = make([]WorkspaceEntry, 0, len(m.Workspaces))
+	for _, ws := range m.Workspaces {
+		if ws.inherited != nil && sameEntry(ws, *ws.inherited) {
+			continue
+		}
+		local.Workspaces = append(local.Workspaces, ws)
+	}
+	return &local
+}
+
+// clone returns a copy of the entry that shares no slices with it
+func (ws WorkspaceEntry) clone() WorkspaceEntry {
+	ws.Fallbacks = append([]string(nil), ws.Fallbacks...)
+	ws.Keep = append([]string(nil), ws.Keep...)
+	ws.Groups = append([]string(nil), ws.Groups...)
+	ws.Sparse = append([]string(nil), ws.Sparse...)
+	return ws
+}
+
+// sameEntry checks if two entries would be written the same way
+func sameEntry(a, b WorkspaceEntry) bool {
+	da, errA := yaml.Marshal(a)
+	db, errB := yaml.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(da, db)
+}
@@ -0,0 +1,100 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadIncludes(t *testing.T) {
+	dir := writeManifest(t, `include:
+  - shared/base.yml
+workspaces:
+  - path: apps/api
+    repo: local-api
+`)
+	os.MkdirAll(filepath.Join(dir, "shared"), 0755)
+	// Nested includes are relative to the including file
+	os.WriteFile(filepath.Join(dir, "shared", "base.yml"), []byte(`include:
+  - common.yml
+workspaces:
+  - path: libs/core
+    repo: base-core
+  - path: apps/api
+    repo: base-api
+`), 0644)
+	os.WriteFile(filepath.Join(dir, "shared", "common.yml"), []byte(`workspaces:
+  - path: libs/log
+    repo: common-log
+  - path: libs/core
+    repo: common-core
+`), 0644)
+
+	m, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var got []string
+	for _, ws := range m.Workspaces {
+		got = append(got, ws.Path+"="+ws.Repo)
+	}
+	// Included first, later includes and local entries override
+	if strings.Join(got, " ") != "libs/log=common-log libs/core=base-core apps/api=local-api" {
+		t.Errorf("unexpected workspaces: %v", got)
+	}
+	if m.Find("libs/core").IncludedFrom() != "shared/base.yml" || m.Find("apps/api").IncludedFrom() != "" {
+		t.Error("IncludedFrom should report the include of inherited entries only")
+	}
+}
+
+func TestSaveDoesNotCopyIncludedWorkspaces(t *testing.T) {
+	dir := writeManifest(t, "include:\n  - base.yml\nworkspaces:\n  - path: apps/api\n    repo: api\n")
+	os.WriteFile(filepath.Join(dir, "base.yml"), []byte("workspaces:\n  - path: libs/core\n    repo: core\n  - path: libs/log\n    repo: log\n"), 0644)
+
+	m, _ := Load(dir)
+	m.Add("apps/web", "web")
+	m.Find("libs/log").Keep = []string{"config.yml"}
+	if err := Save(dir, m); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	got := readManifest(t, dir)
+	if strings.Contains(got, "libs/core") {
+		t.Errorf("unchanged included workspace should not be written:\n%s", got)
+	}
+	if !strings.Contains(got, "apps/web") || !strings.Contains(got, "path: libs/log") {
+		t.Errorf("new and locally changed workspaces should be written:\n%s", got)
+	}
+
+	loaded, _ := Load(dir)
+	if ws := loaded.Find("libs/log"); ws == nil || ws.IncludedFrom() != "" || len(ws.Keep) != 1 {
+		t.Errorf("changed included workspace should become a local override: %+v", ws)
+	}
+	if !loaded.Exists("libs/core") {
+		t.Error("included workspace should still be loaded")
+	}
+}
+
+func TestLoadIncludeCycleAndMissing(t *testing.T) {
+	dir := writeManifest(t, "include:\n  - a.yml\n")
+	os.WriteFile(filepath.Join(dir, "a.yml"), []byte("include:\n  - b.yml\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "b.yml"), []byte("include:\n  - a.yml\n"), 0644)
+
+	if _, err := Load(dir); err == nil || !strings.Contains(err.Error(), "include cycle") {
+		t.Errorf("expected include cycle error, got %v", err)
+	}
+
+	dir = writeManifest(t, "include:\n  - libs/platform/workspaces.yml\n")
+	m, err := Load(dir)
+	if err != nil {
+		t.Fatalf("missing include should not fail Load: %v", err)
+	}
+	if missing := m.MissingIncludes(); len(missing) != 1 || missing[0] != "libs/platform/workspaces.yml" {
+		t.Errorf("unexpected missing includes: %v", missing)
+	}
+	if problems := m.Validate(); len(problems) != 1 || !strings.Contains(problems[0].Message, "not found") {
+		t.Errorf("Validate should report the missing include, got %v", problems)
+	}
+}
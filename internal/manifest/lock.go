@@ -0,0 +1,103 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LockFileName is the lock file recording the exact commit of each workspace
+const LockFileName = ".git.multirepos.lock"
+
+// LockEntry pins a workspace to an exact commit
+type LockEntry struct {
+	Path   string `yaml:"path"`
+	Commit string `yaml:"commit"`
+	Tag    string `yaml:"tag,omitempty"` // Tag pointing at Commit when the lock was recorded (informational)
+}
+
+// Lock represents the .git.multirepos.lock file structure
+type Lock struct {
+	Workspaces []LockEntry `yaml:"workspaces"`
+}
+
+// LockExists checks if a lock file exists in the given directory
+func LockExists(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, LockFileName))
+	return err == nil
+}
+
+// LoadLock reads the lock file from the given directory
+// Returns an empty lock if the file does not exist
+func LoadLock(dir string) (*Lock, error) {
+	data, err := os.ReadFile(filepath.Join(dir, LockFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Lock{Workspaces: []LockEntry{}}, nil
+		}
+		return nil, err
+	}
+
+	var l Lock
+	if err := yaml.Unmarshal(data, &l); err != nil {
+		return nil, err
+	}
+	if l.Workspaces == nil {
+		l.Workspaces = []LockEntry{}
+	}
+
+	return &l, nil
+}
+
+// SaveLock writes the lock file to the given directory
+func SaveLock(dir string, l *Lock) error {
+	data, err := marshalFunc(l)
+	if err != nil {
+		return err
+	}
+
+	header := []byte("# Generated by 'git multirepo lock'. Do not edit by hand.\n")
+	return WriteFileAtomic(filepath.Join(dir, LockFileName), append(header, data...))
+}
+
+// Find finds a lock entry by workspace path
+func (l *Lock) Find(path string) *LockEntry {
+	for i := range l.Workspaces {
+		if l.Workspaces[i].Path == path {
+			return &l.Workspaces[i]
+		}
+	}
+	return nil
+}
+
+// Set adds or replaces the lock entry for entry.Path
+func (l *Lock) Set(entry LockEntry) {
+	if existing := l.Find(entry.Path); existing != nil {
+		*existing = entry
+		return
+	}
+	l.Workspaces = append(l.Workspaces, entry)
+}
+
+// Rename moves the lock entry of a workspace to its new path
+func (l *Lock) Rename(oldPath, newPath string) bool {
+	entry := l.Find(oldPath)
+	if entry == nil {
+		return false
+	}
+	entry.Path = newPath
+	return true
+}
+
+// SortByManifest orders entries like the workspaces in m and drops entries
+// for workspaces that are no longer in the manifest
+func (l *Lock) SortByManifest(m *Manifest) {
+	sorted := make([]LockEntry, 0, len(l.Workspaces))
+	for _, ws := range m.Workspaces {
+		if entry := l.Find(ws.Path); entry != nil {
+			sorted = append(sorted, *entry)
+		}
+	}
+	l.Workspaces = sorted
+}
@@ -0,0 +1,113 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadLockMissing(t *testing.T) {
+	dir := t.TempDir()
+
+	if LockExists(dir) {
+		t.Error("lock should not exist")
+	}
+
+	l, err := LoadLock(dir)
+	if err != nil {
+		t.Fatalf("LoadLock failed: %v", err)
+	}
+	if l.Workspaces == nil || len(l.Workspaces) != 0 {
+		t.Errorf("expected empty lock, got %+v", l.Workspaces)
+	}
+}
+
+func TestLoadLockInvalidYAML(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, LockFileName), []byte("workspaces: [broken"), 0644)
+
+	if _, err := LoadLock(dir); err == nil {
+		t.Error("LoadLock should fail on invalid YAML")
+	}
+}
+
+func TestSaveAndLoadLock(t *testing.T) {
+	dir := t.TempDir()
+
+	l := &Lock{}
+	l.Set(LockEntry{Path: "packages/a", Commit: "1111111111", Tag: "v1.0.0"})
+	l.Set(LockEntry{Path: "packages/b", Commit: "2222222222"})
+
+	if err := SaveLock(dir, l); err != nil {
+		t.Fatalf("SaveLock failed: %v", err)
+	}
+	if !LockExists(dir) {
+		t.Fatal("lock file should exist")
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, LockFileName))
+	if !strings.HasPrefix(string(data), "# Generated by") {
+		t.Errorf("lock file should start with a header comment, got: %s", data)
+	}
+
+	loaded, err := LoadLock(dir)
+	if err != nil {
+		t.Fatalf("LoadLock failed: %v", err)
+	}
+	if len(loaded.Workspaces) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(loaded.Workspaces))
+	}
+	if a := loaded.Find("packages/a"); a == nil || a.Commit != "1111111111" || a.Tag != "v1.0.0" {
+		t.Errorf("unexpected entry for packages/a: %+v", a)
+	}
+}
+
+func TestLockSetReplaces(t *testing.T) {
+	l := &Lock{}
+	l.Set(LockEntry{Path: "a", Commit: "old"})
+	l.Set(LockEntry{Path: "a", Commit: "new"})
+
+	if len(l.Workspaces) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(l.Workspaces))
+	}
+	if l.Find("a").Commit != "new" {
+		t.Errorf("expected commit to be replaced, got %s", l.Find("a").Commit)
+	}
+	if l.Find("missing") != nil {
+		t.Error("Find should return nil for unknown path")
+	}
+}
+
+func TestLockSortByManifest(t *testing.T) {
+	l := &Lock{}
+	l.Set(LockEntry{Path: "b", Commit: "2"})
+	l.Set(LockEntry{Path: "removed", Commit: "3"})
+	l.Set(LockEntry{Path: "a", Commit: "1"})
+
+	m := &Manifest{}
+	m.Add("a", "repo-a")
+	m.Add("b", "repo-b")
+	m.Add("c", "repo-c")
+
+	l.SortByManifest(m)
+
+	if len(l.Workspaces) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", l.Workspaces)
+	}
+	if l.Workspaces[0].Path != "a" || l.Workspaces[1].Path != "b" {
+		t.Errorf("entries should follow manifest order, got %+v", l.Workspaces)
+	}
+}
+
+func TestLockRename(t *testing.T) {
+	l := &Lock{}
+	l.Set(LockEntry{Path: "a", Commit: "1"})
+
+	if !l.Rename("a", "libs/a") || l.Find("libs/a") == nil || l.Find("a") != nil {
+		t.Errorf("entry should move to the new path, got %+v", l.Workspaces)
+	}
+	if l.Rename("missing", "b") {
+		t.Error("Rename should return false for unknown path")
+	}
+}
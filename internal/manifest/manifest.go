@@ -2,10 +2,8 @@
 package manifest
 
 import (
-	"bytes"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -17,19 +15,63 @@ var marshalFunc = yaml.Marshal
 
 // WorkspaceEntry represents a single workspace entry
 type WorkspaceEntry struct {
-	Path   string   `yaml:"path"`
-	Repo   string   `yaml:"repo"`
-	Branch string   `yaml:"branch,omitempty"`
-	Keep   []string `yaml:"keep,omitempty"`
-	Commit string   `yaml:"commit,omitempty"` // Deprecated: kept for backward compatibility, no longer used
+	Path      string   `yaml:"path"`
+	Repo      string   `yaml:"repo"`
+	Fallbacks []string `yaml:"fallbacks,omitempty"` // Mirror URLs tried in order when cloning from repo fails
+	Branch    string   `yaml:"branch,omitempty"`
+	Depth     int      `yaml:"depth,omitempty"`  // Shallow clone with this many commits
+	Filter    string   `yaml:"filter,omitempty"` // Partial clone filter, e.g. "blob:none"
+	Sparse    []string `yaml:"sparse,omitempty"` // Directories to check out (sparse checkout)
+	Keep      []string `yaml:"keep,omitempty"`
+	Groups    []string `yaml:"groups,omitempty"` // Group names for selecting subsets (--group)
+	Commit    string   `yaml:"commit,omitempty"` // Deprecated: kept for backward compatibility, no longer used
+
+	source    string          // Include the entry comes from, empty if listed locally
+	inherited *WorkspaceEntry // Entry as included, to detect local changes
+}
+
+// InGroup checks if the workspace belongs to the given group
+func (ws *WorkspaceEntry) InGroup(group string) bool {
+	for _, g := range ws.Groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// BackupConfig holds backup retention limits. Zero or empty values mean unlimited.
+type BackupConfig struct {
+	KeepDays   int    `yaml:"keep_days,omitempty"`   // Days to keep live backups
+	KeepMonths int    `yaml:"keep_months,omitempty"` // Months of archives to keep
+	MaxSize    string `yaml:"max_size,omitempty"`    // Total size limit, e.g. "500MB"
+}
+
+// DiscoverConfig controls how sync looks for unregistered repositories
+type DiscoverConfig struct {
+	MaxDepth       int      `yaml:"max_depth,omitempty"`       // Directory levels to descend, 0 for unlimited
+	Exclude        []string `yaml:"exclude,omitempty"`         // Directories to skip (gitignore-style globs)
+	IncludeIgnored bool     `yaml:"include_ignored,omitempty"` // Also scan directories ignored by .gitignore
+	FollowSymlinks bool     `yaml:"follow_symlinks,omitempty"` // Descend into symlinked directories
+	LocalRepos     bool     `yaml:"local_repos,omitempty"`     // Register repositories without an origin remote
 }
 
 // Manifest represents the .git.multirepos file structure
 type Manifest struct {
-	Language   string           `yaml:"language,omitempty"`
-	Keep       []string         `yaml:"keep,omitempty"`   // Mother repo: files to keep
-	Ignore     []string         `yaml:"ignore,omitempty"` // Mother repo: files to ignore (gitignore-style)
-	Workspaces []WorkspaceEntry `yaml:"workspaces,omitempty"`
+	Language string   `yaml:"language,omitempty"`
+	Include  []string `yaml:"include,omitempty"` // Manifests whose workspaces are merged into this one
+	Keep     []string `yaml:"keep,omitempty"`    // Mother repo: files to keep
+	Ignore   []string `yaml:"ignore,omitempty"`  // Mother repo: files to ignore (gitignore-style)
+	// Rewrite maps repository URL prefixes, like git's url.<base>.insteadOf
+	Rewrite []RewriteRule `yaml:"rewrite,omitempty"`
+	// KeepStrategy is the default for pull --keep-strategy (reapply, theirs, ours, fail)
+	KeepStrategy string           `yaml:"keep_strategy,omitempty"`
+	Backup       *BackupConfig    `yaml:"backup,omitempty"` // Backup retention (overrides ~/.git.multirepo)
+	Discover     *DiscoverConfig  `yaml:"discover,omitempty"`
+	Workspaces   []WorkspaceEntry `yaml:"workspaces,omitempty"`
+
+	doc     *document // File as loaded, to preserve comments and formatting on save
+	missing []string  // Includes that were not found
 }
 
 // Load reads the manifest from the given directory
@@ -45,8 +87,13 @@ func Load(dir string) (*Manifest, error) {
 		return nil, err
 	}
 
+	doc, err := parseDocument(data)
+	if err != nil {
+		return nil, err
+	}
+
 	var m Manifest
-	if err := yaml.Unmarshal(data, &m); err != nil {
+	if err := doc.root.Decode(&m); err != nil {
 		return nil, err
 	}
 
@@ -55,43 +102,49 @@ func Load(dir string) (*Manifest, error) {
 		m.Workspaces = []WorkspaceEntry{}
 	}
 
+	if len(m.Include) > 0 {
+		if err := m.resolveIncludes(dir, map[string]bool{}); err != nil {
+			return nil, err
+		}
+	}
+
+	if doc.snapshot, err = marshalFunc(m.fileContent()); err != nil {
+		return nil, err
+	}
+	m.doc = doc
+
 	return &m, nil
 }
 
-// Save writes the manifest to the given directory
+// Save writes the manifest to the given directory.
+// A manifest read by Load is written back into the loaded file: comments,
+// key order and unknown keys are kept, and an unchanged manifest is not rewritten.
+// Included workspaces are only written if they were changed.
 func Save(dir string, m *Manifest) error {
 	path := filepath.Join(dir, FileName)
-	data, err := marshalFunc(m)
+	content := m.fileContent()
+	data, err := marshalFunc(content)
 	if err != nil {
 		return err
 	}
 
-	// Add blank line between workspaces for better readability
-	lines := string(data)
-	// Insert blank line before each "- path:" except the first
-	buf := bytes.NewBuffer(nil)
-	inWorkspaces := false
-	firstEntry := true
-
-	for _, line := range strings.Split(lines, "\n") {
-		// Detect "workspaces:"
-		if strings.HasPrefix(line, "workspaces:") {
-			inWorkspaces = true
-			firstEntry = true
-		}
-
-		if inWorkspaces && strings.HasPrefix(line, "  - path:") {
-			if !firstEntry {
-				buf.WriteString("\n")
-			}
-			firstEntry = false
+	if m.doc != nil {
+		if data, err = m.doc.encode(data); err != nil {
+			return err
 		}
+	}
 
-		buf.WriteString(line)
-		buf.WriteString("\n")
+	if err := WriteFileAtomic(path, data); err != nil {
+		return err
 	}
 
-	return os.WriteFile(path, buf.Bytes(), 0644)
+	// Later saves apply their changes to what was just written
+	if doc, err := parseDocument(data); err == nil {
+		if doc.snapshot, err = marshalFunc(content); err == nil {
+			m.doc = doc
+		}
+	}
+	return nil
 }
 
 // Add adds a new workspace to the manifest
@@ -113,6 +166,18 @@ func (m *Manifest) Remove(path string) bool {
 	return false
 }
 
+// Rename changes the path of a workspace. Its place and comments in the file are kept.
+func (m *Manifest) Rename(oldPath, newPath string) bool {
+	ws := m.Find(oldPath)
+	if ws == nil {
+		return false
+	}
+	ws.Path = newPath
+	if m.doc != nil {
+		m.doc.renameWorkspace(oldPath, newPath)
+	}
+	return true
+}
 
 // Find finds a workspace by path
 func (m *Manifest) Find(path string) *WorkspaceEntry {
@@ -129,6 +194,20 @@ func (m *Manifest) Exists(path string) bool {
 	return m.Find(path) != nil
 }
 
+// GetDiscover returns the discovery settings, the zero value if not configured
+func (m *Manifest) GetDiscover() DiscoverConfig {
+	if m.Discover == nil {
+		return DiscoverConfig{}
+	}
+	return *m.Discover
+}
+
+// AllowsLocalRepos reports whether workspaces without a repo URL are allowed,
+// for repositories registered without an origin remote
+func (m *Manifest) AllowsLocalRepos() bool {
+	return m.GetDiscover().LocalRepos
+}
+
 // GetLanguage returns the configured language, defaults to "en"
 func (m *Manifest) GetLanguage() string {
 	if m.Language == "" {
@@ -152,6 +152,36 @@ func TestFind(t *testing.T) {
 	}
 }
 
+func TestGroups(t *testing.T) {
+	dir := t.TempDir()
+
+	m := &Manifest{
+		Workspaces: []WorkspaceEntry{
+			{Path: "apps/api", Repo: "repo-api", Groups: []string{"backend", "shared"}},
+			{Path: "apps/web", Repo: "repo-web"},
+		},
+	}
+	if err := Save(dir, m); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	api := loaded.Find("apps/api")
+	if !api.InGroup("backend") || !api.InGroup("shared") {
+		t.Errorf("expected apps/api in backend and shared, got %v", api.Groups)
+	}
+	if api.InGroup("frontend") {
+		t.Error("apps/api should not be in frontend")
+	}
+	if loaded.Find("apps/web").InGroup("backend") {
+		t.Error("workspace without groups should not be in any group")
+	}
+}
+
 func TestSaveWriteError(t *testing.T) {
 	dir := t.TempDir()
 	// Create .workspaces as a directory to prevent WriteFile
@@ -0,0 +1,391 @@
+package manifest
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// document keeps the parsed YAML of a loaded manifest, so Save can apply the
+// changed fields to it instead of regenerating the file. Comments, key order,
+// quoting and keys this version doesn't know about survive a load/save cycle.
+type document struct {
+	root     *yaml.Node          // Document node as loaded
+	raw      []byte              // File content as loaded
+	snapshot []byte              // Marshalled manifest as loaded, to detect no-op saves
+	indent   int                 // Indentation used by the file
+	blank    map[*yaml.Node]bool // Nodes preceded by a blank line
+}
+
+// parseDocument parses manifest data and remembers its layout
+func parseDocument(data []byte) (*document, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+
+	doc := &document{
+		root:   &root,
+		raw:    data,
+		indent: detectIndent(data),
+		blank:  map[*yaml.Node]bool{},
+	}
+
+	lines := strings.Split(string(data), "\n")
+	seen := map[int]bool{}
+	walkEntries(&root, func(n *yaml.Node) {
+		// A sequence item and its first key share a line; the item owns it
+		if !seen[n.Line] && precededByBlank(lines, n.Line) {
+			doc.blank[n] = true
+		}
+		seen[n.Line] = true
+	})
+	return doc, nil
+}
+
+// encode renders the manifest into the loaded document. data is the manifest
+// marshalled on its own, which is used as is when nothing changed.
+func (doc *document) encode(data []byte) ([]byte, error) {
+	if bytes.Equal(data, doc.snapshot) {
+		return doc.raw, nil
+	}
+
+	var fresh yaml.Node
+	if err := yaml.Unmarshal(data, &fresh); err != nil {
+		return nil, err
+	}
+	if len(doc.root.Content) == 0 || len(fresh.Content) == 0 {
+		// Empty or comment-only file: nothing to preserve but the comments
+		fresh.HeadComment = doc.root.HeadComment
+		fresh.FootComment = doc.root.FootComment
+		doc.root = &fresh
+	} else {
+		doc.root.Content[0] = doc.merge(doc.root.Content[0], fresh.Content[0], reflect.TypeOf(Manifest{}))
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(doc.indent)
+	if err := enc.Encode(doc.root); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return doc.restoreBlankLines(buf.Bytes())
+}
+
+// merge applies fresh (the newly marshalled value of type t) to old, keeping
+// old's comments and style where the value is unchanged
+func (doc *document) merge(old, fresh *yaml.Node, t reflect.Type) *yaml.Node {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if old.Kind != fresh.Kind {
+		fresh.HeadComment = old.HeadComment
+		fresh.LineComment = old.LineComment
+		fresh.FootComment = old.FootComment
+		return fresh
+	}
+
+	switch old.Kind {
+	case yaml.MappingNode:
+		doc.mergeMapping(old, fresh, t)
+	case yaml.SequenceNode:
+		var elem reflect.Type
+		if t != nil && t.Kind() == reflect.Slice {
+			elem = t.Elem()
+		}
+		doc.mergeSequence(old, fresh, elem)
+	case yaml.ScalarNode:
+		if old.Value != fresh.Value || old.ShortTag() != fresh.ShortTag() {
+			old.Value = fresh.Value
+			old.Tag = fresh.Tag
+			old.Style = fresh.Style
+		}
+	}
+	return old
+}
+
+// mergeMapping updates the keys of old in place. Keys of t missing from fresh
+// were cleared and are removed; keys t doesn't know are left untouched.
+func (doc *document) mergeMapping(old, fresh *yaml.Node, t reflect.Type) {
+	fields := yamlFields(t)
+
+	var content []*yaml.Node
+	header := ""
+	for i := 0; i+1 < len(old.Content); i += 2 {
+		key, value := old.Content[i], old.Content[i+1]
+		freshValue := mappingValue(fresh, key.Value)
+		if _, known := fields[key.Value]; known && freshValue == nil {
+			// Keep the comment above the first key, it usually describes the file
+			if len(content) == 0 {
+				header = joinComments(header, key.HeadComment, false)
+			}
+			continue
+		}
+		if header != "" {
+			key.HeadComment = joinComments(header, key.HeadComment, doc.blank[key])
+			doc.blank[key] = false
+			header = ""
+		}
+		if freshValue != nil {
+			value = doc.merge(value, freshValue, fields[key.Value])
+		}
+		content = append(content, key, value)
+	}
+
+	// New keys go after the key preceding them in the struct
+	for i := 0; i+1 < len(fresh.Content); i += 2 {
+		if mappingValue(old, fresh.Content[i].Value) != nil {
+			continue
+		}
+		at := len(content)
+		for j := i - 2; j >= 0; j -= 2 {
+			if k := keyIndex(content, fresh.Content[j].Value); k >= 0 {
+				at = k + 2
+				break
+			}
+		}
+		content = append(content[:at], append([]*yaml.Node{fresh.Content[i], fresh.Content[i+1]}, content[at:]...)...)
+	}
+
+	old.Content = content
+}
+
+// mergeSequence rebuilds old in the order of fresh, reusing the old item with
+// the same identity (path for workspaces, value for scalars)
+func (doc *document) mergeSequence(old, fresh *yaml.Node, elem reflect.Type) {
+	// New items follow the spacing of the existing ones
+	spaced := false
+	for i, item := range old.Content {
+		if i > 0 && doc.blank[item] {
+			spaced = true
+		}
+	}
+
+	used := map[*yaml.Node]bool{}
+	content := make([]*yaml.Node, 0, len(fresh.Content))
+	for _, item := range fresh.Content {
+		if match := findItem(old, item, used); match != nil {
+			used[match] = true
+			content = append(content, doc.merge(match, item, elem))
+			continue
+		}
+		content = append(content, item)
+	}
+
+	if spaced {
+		for i, item := range content {
+			doc.blank[item] = i > 0
+		}
+	}
+	old.Content = content
+}
+
+// restoreBlankLines re-inserts the blank lines of the loaded file, which the
+// YAML encoder drops, before the entries they preceded
+func (doc *document) restoreBlankLines(data []byte) ([]byte, error) {
+	var out yaml.Node
+	if err := yaml.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(string(data), "\n")
+	insert := map[int]bool{}
+	walkParallel(doc.root, &out, func(n, rendered *yaml.Node) {
+		if !doc.blank[n] {
+			return
+		}
+		// Place the blank line above the entry's comment
+		i := rendered.Line - 1
+		for i > 0 && isComment(lines[i-1]) {
+			i--
+		}
+		if i > 0 && strings.TrimSpace(lines[i-1]) != "" {
+			insert[i] = true
+		}
+	})
+
+	var buf bytes.Buffer
+	for i, line := range lines {
+		if insert[i] {
+			buf.WriteString("\n")
+		}
+		buf.WriteString(line)
+		if i < len(lines)-1 {
+			buf.WriteString("\n")
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// walkEntries calls fn for every mapping key and sequence item below n
+func walkEntries(n *yaml.Node, fn func(*yaml.Node)) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			fn(n.Content[i])
+			walkEntries(n.Content[i+1], fn)
+		}
+	case yaml.SequenceNode:
+		for _, item := range n.Content {
+			fn(item)
+			walkEntries(item, fn)
+		}
+	case yaml.DocumentNode:
+		for _, child := range n.Content {
+			walkEntries(child, fn)
+		}
+	}
+}
+
+// walkParallel calls fn for each pair of nodes of two trees with the same shape
+func walkParallel(a, b *yaml.Node, fn func(a, b *yaml.Node)) {
+	fn(a, b)
+	if a.Kind != b.Kind || len(a.Content) != len(b.Content) {
+		return
+	}
+	for i := range a.Content {
+		walkParallel(a.Content[i], b.Content[i], fn)
+	}
+}
+
+// keyIndex returns the index of key in mapping content, or -1
+func keyIndex(content []*yaml.Node, key string) int {
+	for i := 0; i+1 < len(content); i += 2 {
+		if content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// joinComments joins two comment blocks, optionally separated by a blank line
+func joinComments(a, b string, separate bool) string {
+	if a == "" || b == "" {
+		return a + b
+	}
+	if separate {
+		return a + "\n\n" + b
+	}
+	return a + "\n" + b
+}
+
+// renameWorkspace changes the path of a workspace item in the loaded document,
+// so that Save matches the item to the renamed workspace
+func (doc *document) renameWorkspace(oldPath, newPath string) {
+	if len(doc.root.Content) == 0 {
+		return
+	}
+	seq := mappingValue(doc.root.Content[0], "workspaces")
+	if seq == nil || seq.Kind != yaml.SequenceNode {
+		return
+	}
+	for _, item := range seq.Content {
+		if item.Kind != yaml.MappingNode {
+			continue
+		}
+		if value := mappingValue(item, "path"); value != nil && value.Value == oldPath {
+			value.Value = newPath
+			return
+		}
+	}
+}
+
+// findItem finds an unused item of seq with the same identity as item
+func findItem(seq, item *yaml.Node, used map[*yaml.Node]bool) *yaml.Node {
+	id := itemID(item)
+	if id == "" {
+		return nil
+	}
+	for _, candidate := range seq.Content {
+		if !used[candidate] && candidate.Kind == item.Kind && itemID(candidate) == id {
+			return candidate
+		}
+	}
+	return nil
+}
+
+// itemID identifies a sequence item: the path of a workspace, the prefix
+// replaced by a rewrite rule or a scalar's value
+func itemID(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for _, key := range []string{"path", "instead_of"} {
+			if value := mappingValue(n, key); value != nil {
+				return value.Value
+			}
+		}
+	case yaml.ScalarNode:
+		return n.Value
+	}
+	return ""
+}
+
+// mappingValue returns the value node of key in mapping n
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// yamlFields maps the YAML keys of struct type t to their field types
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return fields
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("yaml"), ",")[0]
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+// detectIndent returns the indentation width used by data (4 if unknown)
+func detectIndent(data []byte) int {
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		width := len(line) - len(trimmed)
+		if width == 0 || trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if width >= 2 && width <= 9 {
+			return width
+		}
+		break
+	}
+	return 4
+}
+
+// precededByBlank checks if the line (1-based) is preceded by a blank line,
+// skipping the comment lines directly above it
+func precededByBlank(lines []string, line int) bool {
+	i := line - 1
+	for i > 0 && i <= len(lines) && isComment(lines[i-1]) {
+		i--
+	}
+	return i > 0 && i <= len(lines) && strings.TrimSpace(lines[i-1]) == ""
+}
+
+func isComment(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "#")
+}
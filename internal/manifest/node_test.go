@@ -0,0 +1,187 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const commentedManifest = `# Workspaces of the platform team
+language: en
+
+# Shared settings
+owner: platform-team # not used by git-multirepo
+workspaces:
+  # The API server
+  - path: apps/api
+    repo: git@github.com:org/api.git # SSH only
+    keep:
+      - config.yml # local database
+    x-notes: deploy from main
+
+  - path: apps/web
+    repo: "git@github.com:org/web.git"
+`
+
+func writeManifest(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func readManifest(t *testing.T, dir string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestSaveUnchangedKeepsFile(t *testing.T) {
+	dir := writeManifest(t, commentedManifest)
+
+	m, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if err := Save(dir, m); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	if got := readManifest(t, dir); got != commentedManifest {
+		t.Errorf("unchanged manifest was rewritten:\n%s", got)
+	}
+}
+
+func TestSavePreservesCommentsAndUnknownKeys(t *testing.T) {
+	dir := writeManifest(t, commentedManifest)
+
+	m, _ := Load(dir)
+	m.Find("apps/api").Keep = append(m.Find("apps/api").Keep, ".env")
+	m.Add("apps/admin", "git@github.com:org/admin.git")
+	if err := Save(dir, m); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	want := `# Workspaces of the platform team
+language: en
+
+# Shared settings
+owner: platform-team # not used by git-multirepo
+workspaces:
+  # The API server
+  - path: apps/api
+    repo: git@github.com:org/api.git # SSH only
+    keep:
+      - config.yml # local database
+      - .env
+    x-notes: deploy from main
+
+  - path: apps/web
+    repo: "git@github.com:org/web.git"
+
+  - path: apps/admin
+    repo: git@github.com:org/admin.git
+`
+	if got := readManifest(t, dir); got != want {
+		t.Errorf("unexpected manifest:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSaveRenamedWorkspace(t *testing.T) {
+	dir := writeManifest(t, commentedManifest)
+
+	m, _ := Load(dir)
+	if !m.Rename("apps/api", "services/api") {
+		t.Fatal("Rename should find apps/api")
+	}
+	if m.Rename("apps/missing", "x") {
+		t.Error("Rename of a missing workspace should fail")
+	}
+	if err := Save(dir, m); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	want := strings.Replace(commentedManifest, "- path: apps/api", "- path: services/api", 1)
+	if got := readManifest(t, dir); got != want {
+		t.Errorf("renamed workspace should keep its place and comments:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSaveRemovesClearedFields(t *testing.T) {
+	dir := writeManifest(t, commentedManifest)
+
+	m, _ := Load(dir)
+	m.Find("apps/api").Keep = nil
+	m.Remove("apps/web")
+	m.Language = ""
+	if err := Save(dir, m); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	got := readManifest(t, dir)
+	for _, gone := range []string{"language:", "keep:", "config.yml", "apps/web"} {
+		if strings.Contains(got, gone) {
+			t.Errorf("%q should be removed:\n%s", gone, got)
+		}
+	}
+	for _, kept := range []string{"# Workspaces of the platform team\n\n# Shared settings\nowner: platform-team", "x-notes: deploy from main", "# The API server"} {
+		if !strings.Contains(got, kept) {
+			t.Errorf("%q should be kept:\n%s", kept, got)
+		}
+	}
+
+	// Saved result loads back with the same content
+	loaded, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(loaded.Workspaces) != 1 || loaded.Workspaces[0].Path != "apps/api" || len(loaded.Workspaces[0].Keep) != 0 {
+		t.Errorf("unexpected workspaces after save: %+v", loaded.Workspaces)
+	}
+}
+
+func TestSaveReordersAndRepeatedSaves(t *testing.T) {
+	dir := writeManifest(t, commentedManifest)
+
+	m, _ := Load(dir)
+	m.Workspaces[0], m.Workspaces[1] = m.Workspaces[1], m.Workspaces[0]
+	Save(dir, m)
+
+	// A second change on the same manifest applies to the saved file
+	m.Find("apps/api").Branch = "develop"
+	Save(dir, m)
+
+	got := readManifest(t, dir)
+	if strings.Index(got, "apps/web") > strings.Index(got, "apps/api") {
+		t.Errorf("workspaces should follow the manifest order:\n%s", got)
+	}
+	if !strings.Contains(got, "workspaces:\n  - path: apps/web\n") || !strings.Contains(got, "\n\n  # The API server\n  - path: apps/api") {
+		t.Errorf("entry comment and spacing should move with the entry:\n%s", got)
+	}
+	if !strings.Contains(got, "repo: git@github.com:org/api.git # SSH only\n    branch: develop\n") {
+		t.Errorf("new key should follow the struct order:\n%s", got)
+	}
+}
+
+func TestSaveEmptyFile(t *testing.T) {
+	dir := writeManifest(t, "")
+
+	m, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	m.Add("apps/api", "repo")
+	if err := Save(dir, m); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	if got := readManifest(t, dir); got != "workspaces:\n    - path: apps/api\n      repo: repo\n" {
+		t.Errorf("unexpected manifest:\n%s", got)
+	}
+}
@@ -0,0 +1,47 @@
+package manifest
+
+import "strings"
+
+// RewriteRule replaces the repository URL prefix InsteadOf with URL, like
+// git's url.<URL>.insteadOf = <InsteadOf>
+type RewriteRule struct {
+	URL       string `yaml:"url"`
+	InsteadOf string `yaml:"instead_of"`
+}
+
+// RewriteURL applies the rule with the longest matching prefix to url.
+// Of rules with the same prefix, the last one wins.
+func RewriteURL(url string, rules []RewriteRule) string {
+	best := -1
+	for i, rule := range rules {
+		if rule.InsteadOf == "" || !strings.HasPrefix(url, rule.InsteadOf) {
+			continue
+		}
+		if best < 0 || len(rule.InsteadOf) >= len(rules[best].InsteadOf) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return url
+	}
+	return rules[best].URL + strings.TrimPrefix(url, rules[best].InsteadOf)
+}
+
+// RemoteURLs returns the URLs to clone a workspace from, in the order to try
+// them: its repo, then its fallbacks. The manifest's rewrite rules are applied,
+// followed by extra rules (e.g. from ~/.git.multirepo), which take precedence
+// over manifest rules for the same prefix.
+func (m *Manifest) RemoteURLs(ws *WorkspaceEntry, extra []RewriteRule) []string {
+	rules := append(append([]RewriteRule(nil), m.Rewrite...), extra...)
+
+	var urls []string
+	seen := make(map[string]bool)
+	for _, url := range append([]string{ws.Repo}, ws.Fallbacks...) {
+		url = RewriteURL(strings.TrimSpace(url), rules)
+		if url != "" && !seen[url] {
+			seen[url] = true
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
@@ -0,0 +1,79 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRewriteURL(t *testing.T) {
+	rules := []RewriteRule{
+		{URL: "https://github.com/", InsteadOf: "git@github.com:"},
+		{URL: "https://proxy.corp/github/", InsteadOf: "https://github.com/"},
+		{URL: "https://proxy.corp/internal/", InsteadOf: "https://github.com/org/"},
+	}
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"git@github.com:user/repo.git", "https://github.com/user/repo.git"}, // Rules are not chained
+		{"https://github.com/user/repo.git", "https://proxy.corp/github/user/repo.git"},
+		{"https://github.com/org/repo.git", "https://proxy.corp/internal/repo.git"}, // Longest prefix wins
+		{"https://gitlab.com/user/repo.git", "https://gitlab.com/user/repo.git"},
+	}
+	for _, tt := range tests {
+		if got := RewriteURL(tt.url, rules); got != tt.want {
+			t.Errorf("RewriteURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestRemoteURLs(t *testing.T) {
+	m := &Manifest{Rewrite: []RewriteRule{{URL: "https://github.com/", InsteadOf: "git@github.com:"}}}
+	ws := &WorkspaceEntry{
+		Path:      "lib",
+		Repo:      "git@github.com:org/lib.git",
+		Fallbacks: []string{"https://mirror.corp/lib.git", "https://github.com/org/lib.git"},
+	}
+
+	got := m.RemoteURLs(ws, nil)
+	want := "https://github.com/org/lib.git,https://mirror.corp/lib.git"
+	if strings.Join(got, ",") != want {
+		t.Errorf("RemoteURLs() = %v, want %s (rewritten, without duplicates)", got, want)
+	}
+
+	// Extra rules override manifest rules for the same prefix
+	got = m.RemoteURLs(ws, []RewriteRule{{URL: "ssh://git@github.com/", InsteadOf: "git@github.com:"}})
+	if got[0] != "ssh://git@github.com/org/lib.git" {
+		t.Errorf("extra rule should take precedence, got %v", got)
+	}
+}
+
+func TestSaveKeepsRewriteComments(t *testing.T) {
+	dir := t.TempDir()
+	content := `rewrite:
+    # SSH for everyone
+    - url: https://github.com/
+      instead_of: "git@github.com:"
+workspaces:
+    - path: lib
+      repo: git@github.com:org/lib.git
+`
+	os.WriteFile(filepath.Join(dir, FileName), []byte(content), 0644)
+
+	m, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Workspaces[0].Fallbacks = []string{"https://mirror.corp/lib.git"}
+	if err := Save(dir, m); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, FileName))
+	if !strings.Contains(string(data), "# SSH for everyone") || !strings.Contains(string(data), "fallbacks:") {
+		t.Errorf("rewrite comment should be kept, got:\n%s", data)
+	}
+}
@@ -0,0 +1,64 @@
+package manifest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// LockPath is the advisory lock taken around manifest read-modify-write,
+// relative to the repository root
+var LockPath = filepath.Join(".multirepos", "lock")
+
+// processMu serializes goroutines of this process; the file lock serializes processes
+var processMu sync.Mutex
+
+// WithLock runs fn while holding the exclusive manifest lock of dir.
+// Other git-multirepo processes (e.g. sync started by post-commit hooks in
+// several workspaces) wait until fn returns. Calls must not be nested.
+func WithLock(dir string, fn func() error) error {
+	processMu.Lock()
+	defer processMu.Unlock()
+
+	path := filepath.Join(dir, LockPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create lock directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open manifest lock: %w", err)
+	}
+	defer f.Close()
+
+	if err := lockFile(f); err != nil {
+		return fmt.Errorf("failed to lock manifest: %w", err)
+	}
+	defer unlockFile(f)
+
+	return fn()
+}
+
+// Update loads the manifest of dir under the manifest lock, applies fn and
+// saves the result. Changes are applied to the current file content, so
+// concurrent updates don't overwrite each other. Returns the saved manifest.
+// Nothing is saved if fn returns an error.
+func Update(dir string, fn func(m *Manifest) error) (*Manifest, error) {
+	var updated *Manifest
+	err := WithLock(dir, func() error {
+		m, err := Load(dir)
+		if err != nil {
+			return fmt.Errorf("failed to load manifest: %w", err)
+		}
+		if err := fn(m); err != nil {
+			return err
+		}
+		if err := Save(dir, m); err != nil {
+			return fmt.Errorf("failed to save manifest: %w", err)
+		}
+		updated = m
+		return nil
+	})
+	return updated, err
+}
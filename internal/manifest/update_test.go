@@ -0,0 +1,86 @@
+package manifest
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestSaveAtomicKeepsMode(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, FileName)
+	os.WriteFile(path, []byte("workspaces: []\n"), 0600)
+
+	m := &Manifest{Workspaces: []WorkspaceEntry{{Path: "apps/api", Repo: "repo"}}}
+	if err := Save(dir, m); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	// No temporary files are left behind
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("expected only the manifest in dir, got %d entries", len(entries))
+	}
+}
+
+func TestUpdateConcurrent(t *testing.T) {
+	dir := t.TempDir()
+
+	const n = 20
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := Update(dir, func(m *Manifest) error {
+				m.Add(fmt.Sprintf("ws%d", i), "repo")
+				return nil
+			})
+			if err != nil {
+				t.Errorf("Update failed: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	m, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(m.Workspaces) != n {
+		t.Errorf("expected %d workspaces, got %d", n, len(m.Workspaces))
+	}
+	if _, err := os.Stat(filepath.Join(dir, LockPath)); err != nil {
+		t.Errorf("lock file should exist: %v", err)
+	}
+}
+
+func TestUpdateErrorDoesNotSave(t *testing.T) {
+	dir := t.TempDir()
+	Save(dir, &Manifest{Workspaces: []WorkspaceEntry{{Path: "apps/api", Repo: "repo"}}})
+
+	wantErr := errors.New("boom")
+	_, err := Update(dir, func(m *Manifest) error {
+		m.Remove("apps/api")
+		return wantErr
+	})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("expected fn error, got %v", err)
+	}
+
+	m, _ := Load(dir)
+	if !m.Exists("apps/api") {
+		t.Error("manifest should not be saved when fn fails")
+	}
+}
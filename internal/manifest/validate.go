@@ -0,0 +1,118 @@
+package manifest
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ValidationError describes one problem found in the manifest
+type ValidationError struct {
+	Path    string // Workspace path the problem belongs to, empty for top-level fields
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// Validate checks the manifest for structural problems that Load accepts:
+// empty, absolute, escaping or duplicate workspace paths, nested workspaces,
+// empty repo (unless local repos are allowed) or fallback URLs, keep files or sparse directories outside their
+// repository, negative clone depths, incomplete rewrite rules and missing includes.
+func (m *Manifest) Validate() []ValidationError {
+	var problems []ValidationError
+	add := func(p, format string, args ...interface{}) {
+		problems = append(problems, ValidationError{Path: p, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, file := range m.Keep {
+		if msg := checkRelativePath(file); msg != "" {
+			add("", "keep file %q %s", file, msg)
+		}
+	}
+
+	for _, rule := range m.Rewrite {
+		if rule.URL == "" || rule.InsteadOf == "" {
+			add("", "rewrite rule needs both url and instead_of (url %q, instead_of %q)", rule.URL, rule.InsteadOf)
+		}
+	}
+
+	for _, include := range m.missing {
+		add("", "include %q not found", include)
+	}
+
+	seen := map[string]bool{}
+	for _, ws := range m.Workspaces {
+		if ws.Path == "" {
+			add("", "workspace with repo %q has no path", ws.Repo)
+			continue
+		}
+		if msg := checkRelativePath(ws.Path); msg != "" {
+			add(ws.Path, "path %s", msg)
+		} else if cleaned := path.Clean(ws.Path); cleaned != ws.Path {
+			add(ws.Path, "path is not clean (use %q)", cleaned)
+		}
+
+		if seen[ws.Path] {
+			add(ws.Path, "duplicate workspace path")
+		}
+		seen[ws.Path] = true
+
+		if strings.TrimSpace(ws.Repo) == "" && !m.AllowsLocalRepos() {
+			add(ws.Path, "repo is empty")
+		}
+		for _, fallback := range ws.Fallbacks {
+			if strings.TrimSpace(fallback) == "" {
+				add(ws.Path, "fallback URL is empty")
+			}
+		}
+
+		for _, file := range ws.Keep {
+			if msg := checkRelativePath(file); msg != "" {
+				add(ws.Path, "keep file %q %s", file, msg)
+			}
+		}
+
+		if ws.Depth < 0 {
+			add(ws.Path, "depth must not be negative")
+		}
+		for _, dir := range ws.Sparse {
+			if msg := checkRelativePath(dir); msg != "" {
+				add(ws.Path, "sparse directory %q %s", dir, msg)
+			}
+		}
+	}
+
+	// Workspaces inside other workspaces are cloned into each other's trees
+	for _, outer := range m.Workspaces {
+		for _, inner := range m.Workspaces {
+			if outer.Path != "" && inner.Path != outer.Path && strings.HasPrefix(inner.Path, outer.Path+"/") {
+				add(inner.Path, "nested inside workspace %s", outer.Path)
+			}
+		}
+	}
+
+	return problems
+}
+
+// checkRelativePath returns why p is not a usable path relative to its repository,
+// or "" if it is
+func checkRelativePath(p string) string {
+	if p == "" {
+		return "is empty"
+	}
+	if filepath.IsAbs(p) || strings.HasPrefix(p, "/") {
+		return "must be relative"
+	}
+	for _, part := range strings.Split(filepath.ToSlash(p), "/") {
+		if part == ".." {
+			return "must not contain .."
+		}
+	}
+	return ""
+}
@@ -0,0 +1,108 @@
+package manifest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest Manifest
+		want     []string
+	}{
+		{
+			name: "valid",
+			manifest: Manifest{
+				Keep: []string{".env"},
+				Workspaces: []WorkspaceEntry{
+					{Path: "apps/api", Repo: "https://github.com/test/api.git", Keep: []string{"config.json"}},
+					{Path: "apps/web", Repo: "https://github.com/test/web.git"},
+				},
+			},
+		},
+		{
+			name: "bad paths",
+			manifest: Manifest{Workspaces: []WorkspaceEntry{
+				{Path: "/abs/api", Repo: "r"},
+				{Path: "../outside", Repo: "r"},
+				{Path: "apps//web", Repo: "r"},
+				{Repo: "r"},
+			}},
+			want: []string{
+				"/abs/api: path must be relative",
+				"../outside: path must not contain ..",
+				`apps//web: path is not clean (use "apps/web")`,
+				`workspace with repo "r" has no path`,
+			},
+		},
+		{
+			name: "duplicate, empty repo and nested",
+			manifest: Manifest{Workspaces: []WorkspaceEntry{
+				{Path: "apps", Repo: "r"},
+				{Path: "apps/api", Repo: " "},
+				{Path: "apps/api", Repo: "r"},
+			}},
+			want: []string{
+				"apps/api: repo is empty",
+				"apps/api: duplicate workspace path",
+				"apps/api: nested inside workspace apps",
+				"apps/api: nested inside workspace apps",
+			},
+		},
+		{
+			name: "empty repo with local repos allowed",
+			manifest: Manifest{
+				Discover:   &DiscoverConfig{LocalRepos: true},
+				Workspaces: []WorkspaceEntry{{Path: "tools/gen"}},
+			},
+		},
+		{
+			name: "keep files",
+			manifest: Manifest{
+				Keep: []string{"../secret"},
+				Workspaces: []WorkspaceEntry{
+					{Path: "apps/api", Repo: "r", Keep: []string{"/etc/passwd", ""}},
+				},
+			},
+			want: []string{
+				`keep file "../secret" must not contain ..`,
+				`apps/api: keep file "/etc/passwd" must be relative`,
+				`apps/api: keep file "" is empty`,
+			},
+		},
+		{
+			name: "clone options",
+			manifest: Manifest{Workspaces: []WorkspaceEntry{
+				{Path: "mono", Repo: "r", Depth: -1, Sparse: []string{"services/api", "../other"}},
+			}},
+			want: []string{
+				"mono: depth must not be negative",
+				`mono: sparse directory "../other" must not contain ..`,
+			},
+		},
+		{
+			name: "remote URLs",
+			manifest: Manifest{
+				Rewrite:    []RewriteRule{{URL: "https://proxy/"}},
+				Workspaces: []WorkspaceEntry{{Path: "lib", Repo: "r", Fallbacks: []string{"m", " "}}},
+			},
+			want: []string{
+				`rewrite rule needs both url and instead_of (url "https://proxy/", instead_of "")`,
+				"lib: fallback URL is empty",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, p := range tt.manifest.Validate() {
+				got = append(got, p.Error())
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(tt.want, "\n"), strings.Join(got, "\n"))
+			}
+		})
+	}
+}
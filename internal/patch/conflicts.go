@@ -0,0 +1,132 @@
+package patch
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConflictsFileName is the state file in .multirepos/ recording unresolved keep file merges
+const ConflictsFileName = "conflicts.yaml"
+
+// Conflict is a keep file left with conflict markers after a pull
+type Conflict struct {
+	Workspace string    `yaml:"workspace"`
+	File      string    `yaml:"file"`
+	Patch     string    `yaml:"patch,omitempty"` // Local changes as a patch, relative to the repository root
+	Time      time.Time `yaml:"time"`
+}
+
+// ConflictState is the content of the conflicts state file
+type ConflictState struct {
+	Conflicts []Conflict `yaml:"conflicts"`
+}
+
+// LoadConflicts reads the conflict state from multireposDir.
+// Returns an empty state if the file does not exist.
+func LoadConflicts(multireposDir string) (*ConflictState, error) {
+	data, err := os.ReadFile(filepath.Join(multireposDir, ConflictsFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &ConflictState{}, nil
+		}
+		return nil, err
+	}
+
+	var s ConflictState
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ConflictsFileName, err)
+	}
+	return &s, nil
+}
+
+// SaveConflicts writes the conflict state to multireposDir, removing the file when empty
+func SaveConflicts(multireposDir string, s *ConflictState) error {
+	path := filepath.Join(multireposDir, ConflictsFileName)
+	if len(s.Conflicts) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(multireposDir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Add records a conflict, replacing an earlier one for the same file
+func (s *ConflictState) Add(c Conflict) {
+	for i := range s.Conflicts {
+		if s.Conflicts[i].Workspace == c.Workspace && s.Conflicts[i].File == c.File {
+			s.Conflicts[i] = c
+			return
+		}
+	}
+	s.Conflicts = append(s.Conflicts, c)
+}
+
+// ForWorkspace returns the conflicts recorded for a workspace
+func (s *ConflictState) ForWorkspace(workspace string) []Conflict {
+	var result []Conflict
+	for _, c := range s.Conflicts {
+		if c.Workspace == workspace {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// RenameWorkspace moves the conflicts of a workspace, and their saved patches
+// under .multirepos/patches/, to the workspace's new path.
+// Returns true if any conflict was moved.
+func (s *ConflictState) RenameWorkspace(oldPath, newPath string) bool {
+	oldPatches := filepath.Join(".multirepos", "patches", oldPath) + string(filepath.Separator)
+	newPatches := filepath.Join(".multirepos", "patches", newPath) + string(filepath.Separator)
+
+	renamed := false
+	for i := range s.Conflicts {
+		c := &s.Conflicts[i]
+		if c.Workspace != oldPath {
+			continue
+		}
+		c.Workspace = newPath
+		if strings.HasPrefix(c.Patch, oldPatches) {
+			c.Patch = newPatches + strings.TrimPrefix(c.Patch, oldPatches)
+		}
+		renamed = true
+	}
+	return renamed
+}
+
+// Prune drops conflicts whose file no longer contains conflict markers.
+// Returns true if any conflict was dropped.
+func (s *ConflictState) Prune(repoRoot string) bool {
+	var remaining []Conflict
+	for _, c := range s.Conflicts {
+		if IsUnresolved(repoRoot, c) {
+			remaining = append(remaining, c)
+		}
+	}
+	pruned := len(remaining) != len(s.Conflicts)
+	s.Conflicts = remaining
+	return pruned
+}
+
+// IsUnresolved checks if the conflicted file still contains conflict markers
+func IsUnresolved(repoRoot string, c Conflict) bool {
+	content, err := os.ReadFile(filepath.Join(repoRoot, c.Workspace, c.File))
+	if err != nil {
+		return false
+	}
+	return HasConflictMarkers(content)
+}
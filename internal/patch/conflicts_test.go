@@ -0,0 +1,90 @@
+package patch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConflicts_SaveAndLoad(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), ".multirepos")
+
+	s, err := LoadConflicts(dir)
+	if err != nil {
+		t.Fatalf("LoadConflicts failed: %v", err)
+	}
+	if len(s.Conflicts) != 0 {
+		t.Fatalf("expected empty state, got %+v", s.Conflicts)
+	}
+
+	s.Add(Conflict{Workspace: "apps/api", File: "config.yml", Time: time.Now()})
+	s.Add(Conflict{Workspace: "apps/api", File: "config.yml", Patch: "p", Time: time.Now()})
+	s.Add(Conflict{Workspace: "apps/web", File: ".env", Time: time.Now()})
+	if err := SaveConflicts(dir, s); err != nil {
+		t.Fatalf("SaveConflicts failed: %v", err)
+	}
+
+	loaded, err := LoadConflicts(dir)
+	if err != nil {
+		t.Fatalf("LoadConflicts failed: %v", err)
+	}
+	if len(loaded.Conflicts) != 2 {
+		t.Fatalf("expected 2 conflicts (Add replaces same file), got %+v", loaded.Conflicts)
+	}
+	api := loaded.ForWorkspace("apps/api")
+	if len(api) != 1 || api[0].Patch != "p" {
+		t.Errorf("expected replaced conflict for apps/api, got %+v", api)
+	}
+
+	// Saving an empty state removes the file
+	if err := SaveConflicts(dir, &ConflictState{}); err != nil {
+		t.Fatalf("SaveConflicts failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ConflictsFileName)); !os.IsNotExist(err) {
+		t.Error("state file should be removed when empty")
+	}
+}
+
+func TestConflicts_Prune(t *testing.T) {
+	repoRoot := t.TempDir()
+	os.MkdirAll(filepath.Join(repoRoot, "apps/api"), 0755)
+	os.WriteFile(filepath.Join(repoRoot, "apps/api/a.yml"), []byte("<<<<<<< local\nx\n=======\ny\n>>>>>>> upstream\n"), 0644)
+	os.WriteFile(filepath.Join(repoRoot, "apps/api/b.yml"), []byte("resolved\n"), 0644)
+
+	s := &ConflictState{Conflicts: []Conflict{
+		{Workspace: "apps/api", File: "a.yml"},
+		{Workspace: "apps/api", File: "b.yml"},
+		{Workspace: "apps/api", File: "deleted.yml"},
+	}}
+
+	if !s.Prune(repoRoot) {
+		t.Error("Prune should report dropped conflicts")
+	}
+	if len(s.Conflicts) != 1 || s.Conflicts[0].File != "a.yml" {
+		t.Errorf("only a.yml should remain, got %+v", s.Conflicts)
+	}
+	if s.Prune(repoRoot) {
+		t.Error("second Prune should drop nothing")
+	}
+}
+
+func TestConflicts_RenameWorkspace(t *testing.T) {
+	s := &ConflictState{}
+	s.Add(Conflict{Workspace: "packages/lib", File: "config.yml", Patch: filepath.Join(".multirepos", "patches", "packages", "lib", "config.yml.patch")})
+	s.Add(Conflict{Workspace: "packages/lib2", File: ".env"})
+
+	if !s.RenameWorkspace("packages/lib", "libs/core") {
+		t.Fatal("RenameWorkspace should report the moved conflict")
+	}
+	moved := s.ForWorkspace("libs/core")
+	if len(moved) != 1 || moved[0].Patch != filepath.Join(".multirepos", "patches", "libs", "core", "config.yml.patch") {
+		t.Errorf("conflict and patch path should move, got %+v", moved)
+	}
+	if len(s.ForWorkspace("packages/lib2")) != 1 {
+		t.Error("other workspaces with the same prefix should not move")
+	}
+	if s.RenameWorkspace("packages/lib", "x") {
+		t.Error("nothing left to move")
+	}
+}
@@ -0,0 +1,59 @@
+package patch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// Conflict marker labels written by Merge
+const (
+	LabelLocal    = "local"
+	LabelBase     = "base"
+	LabelUpstream = "upstream"
+)
+
+// Merge performs a three-way merge of local and upstream changes against base
+// using git merge-file. Conflicting hunks are written with conflict markers.
+// Returns the merged content and the number of conflicts.
+func Merge(base, local, upstream []byte) ([]byte, int, error) {
+	dir, err := os.MkdirTemp("", "multirepo-merge-")
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string][]byte{"local": local, "base": base, "upstream": upstream}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0644); err != nil {
+			return nil, 0, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	cmd := exec.Command("git", "merge-file", "-p",
+		"-L", LabelLocal, "-L", LabelBase, "-L", LabelUpstream,
+		filepath.Join(dir, "local"), filepath.Join(dir, "base"), filepath.Join(dir, "upstream"))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	merged, err := cmd.Output()
+	if err != nil {
+		// Exit code is the number of conflicts (capped at 127); negative (255) on error
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 && exitErr.ExitCode() < 128 {
+			return merged, exitErr.ExitCode(), nil
+		}
+		return nil, 0, fmt.Errorf("git merge-file failed: %w\nstderr: %s", err, stderr.String())
+	}
+
+	return merged, 0, nil
+}
+
+// HasConflictMarkers checks if content still contains conflict markers written by Merge
+func HasConflictMarkers(content []byte) bool {
+	return bytes.Contains(content, []byte("<<<<<<< "+LabelLocal)) &&
+		bytes.Contains(content, []byte(">>>>>>> "+LabelUpstream))
+}
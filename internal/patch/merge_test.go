@@ -0,0 +1,99 @@
+package patch
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMerge_Clean(t *testing.T) {
+	base := []byte("name: app\nhost: localhost\nmode: dev\nlevel: info\nport: 80\n")
+	local := []byte("name: app\nhost: localhost\nmode: dev\nlevel: info\nport: 8080\n")
+	upstream := []byte("name: service\nhost: localhost\nmode: dev\nlevel: info\nport: 80\n")
+
+	merged, conflicts, err := Merge(base, local, upstream)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if conflicts != 0 {
+		t.Errorf("expected no conflicts, got %d", conflicts)
+	}
+
+	want := "name: service\nhost: localhost\nmode: dev\nlevel: info\nport: 8080\n"
+	if string(merged) != want {
+		t.Errorf("expected merged content %q, got %q", want, merged)
+	}
+}
+
+func TestMerge_Conflict(t *testing.T) {
+	base := []byte("port: 80\n")
+	local := []byte("port: 8080\n")
+	upstream := []byte("port: 9090\n")
+
+	merged, conflicts, err := Merge(base, local, upstream)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if conflicts != 1 {
+		t.Errorf("expected 1 conflict, got %d", conflicts)
+	}
+	if !HasConflictMarkers(merged) {
+		t.Errorf("merged content should contain conflict markers, got %q", merged)
+	}
+	if !strings.Contains(string(merged), "port: 8080") || !strings.Contains(string(merged), "port: 9090") {
+		t.Errorf("merged content should contain both sides, got %q", merged)
+	}
+}
+
+func TestMerge_EmptyBase(t *testing.T) {
+	// File added on both sides with the same content
+	merged, conflicts, err := Merge([]byte{}, []byte("same\n"), []byte("same\n"))
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if conflicts != 0 || string(merged) != "same\n" {
+		t.Errorf("expected clean merge, got %d conflicts: %q", conflicts, merged)
+	}
+}
+
+func TestHasConflictMarkers(t *testing.T) {
+	if HasConflictMarkers([]byte("port: 80\n")) {
+		t.Error("plain content should not have markers")
+	}
+	if !HasConflictMarkers([]byte("<<<<<<< local\na\n=======\nb\n>>>>>>> upstream\n")) {
+		t.Error("should detect markers")
+	}
+}
+
+func TestCreateAndApply(t *testing.T) {
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init"},
+		{"config", "user.email", "test@test.com"},
+		{"config", "user.name", "Test"},
+	} {
+		exec.Command("git", append([]string{"-C", dir}, args...)...).Run()
+	}
+	file := filepath.Join(dir, "config.yml")
+	os.WriteFile(file, []byte("port: 80\n"), 0644)
+	exec.Command("git", "-C", dir, "add", ".").Run()
+	exec.Command("git", "-C", dir, "commit", "-m", "init").Run()
+
+	os.WriteFile(file, []byte("port: 8080\n"), 0644)
+	patchPath := filepath.Join(t.TempDir(), "config.yml.patch")
+	if err := Create(dir, "config.yml", patchPath); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	exec.Command("git", "-C", dir, "checkout", "--", "config.yml").Run()
+	if err := Apply(dir, patchPath); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	content, _ := os.ReadFile(file)
+	if string(content) != "port: 8080\n" {
+		t.Errorf("expected patched content, got %q", content)
+	}
+}
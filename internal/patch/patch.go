@@ -49,8 +49,8 @@ func Create(repoPath, file, patchPath string) error {
 	return nil
 }
 
-// Apply applies a patch file to the working tree using the patch command.
-// The patch must be in unified diff format.
+// Apply applies a patch file to the working tree using git apply.
+// The patch must be in unified diff format with a/ and b/ prefixes (as written by Create).
 func Apply(repoPath, patchPath string) error {
 	if repoPath == "" {
 		return fmt.Errorf("repoPath cannot be empty")
@@ -64,17 +64,12 @@ func Apply(repoPath, patchPath string) error {
 		return fmt.Errorf("patch file not found: %w", err)
 	}
 
-	// Open patch file
-	patchFile, err := os.Open(patchPath)
+	absPatch, err := filepath.Abs(patchPath)
 	if err != nil {
-		return fmt.Errorf("failed to open patch file: %w", err)
+		return fmt.Errorf("failed to resolve patch path: %w", err)
 	}
-	defer patchFile.Close()
 
-	// Apply patch with -p1 to strip leading path component
-	cmd := exec.Command("patch", "-p1")
-	cmd.Dir = repoPath
-	cmd.Stdin = patchFile
+	cmd := exec.Command("git", "-C", repoPath, "apply", "--whitespace=nowarn", absPatch)
 
 	// Capture both stdout and stderr
 	output, err := cmd.CombinedOutput()